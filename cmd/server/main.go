@@ -48,6 +48,27 @@ func main() {
 
 	cont := container.New(conf)
 
+	// MQTT
+	if cont.MqttBroker != nil {
+		err = cont.MqttBroker.Start()
+		if err != nil {
+			log.Fatalf("Unable to start MQTT broker: %q\n", err)
+		}
+		defer cont.MqttBroker.Close()
+	}
+	if cont.MqttBridge != nil {
+		cont.MqttBridge.Start(ctx)
+	}
+
+	// Presence
+	go cont.PresenceSweeper.Run(ctx)
+
+	// Sessions
+	go cont.SessionFlusher.Run(ctx)
+
+	// Webhooks
+	cont.WebhookDispatcher.Start(ctx)
+
 	// HTTP Server
 	err = http.Server(
 		ctx,
@@ -2,33 +2,81 @@ package config
 
 import (
 	"log"
+	"net"
 	"os"
+	"strings"
 	"time"
 )
 
 type Configuration struct {
-	DatabaseName        string
-	DatabaseHost        string
-	DatabaseUser        string
-	DatabasePassword    string
-	MigrateToVersion    string
-	MigrationLocation   string
-	FileStorageLocation string
-	JwtSecret           string
-	JwtTTL              time.Duration
+	DatabaseName          string
+	DatabaseHost          string
+	DatabaseUser          string
+	DatabasePassword      string
+	MigrateToVersion      string
+	MigrationLocation     string
+	FileStorageLocation   string
+	JwtSecret             string
+	JwtTTL                time.Duration
+	RefreshTokenTTL       time.Duration
+	SessionFlushInterval  time.Duration
+	MqttBrokerUrl         string
+	MqttClientId          string
+	MqttUsername          string
+	MqttPassword          string
+	MqttEmbeddedAddr      string
+	DeviceStaleAfter      time.Duration
+	DeviceOfflineAfter    time.Duration
+	PresenceSweepInterval time.Duration
+	DeepLinkBase          string
+	AppUrl                string
+	InvitationTTL         time.Duration
+	PasswordResetTTL      time.Duration
+	EmailVerificationTTL  time.Duration
+	MailFrom              string
+	MailDir               string
+	SmtpAddr              string
+	SmtpUsername          string
+	SmtpPassword          string
+	DeviceDeletion        string
+	TrashRetention        time.Duration
+	TrustedProxies        []*net.IPNet
 }
 
 func GetConfiguration() Configuration {
 	return Configuration{
-		DatabaseName:        getOrDefault("DB_NAME", "project-ar-db"),
-		DatabaseHost:        getOrDefault("DB_HOST", "127.0.0.1:5432"),
-		DatabaseUser:        getOrDefault("DB_USER", "postgres"),
-		DatabasePassword:    getOrDefault("DB_PASSWORD", "postgres"),
-		MigrateToVersion:    getOrDefault("MIGRATE", "latest"),
-		MigrationLocation:   getOrDefault("MIGRATION_LOCATION", "internal/infra/database/migrations"),
-		FileStorageLocation: getOrDefault("FILES_LOCATION", "file_storage"),
-		JwtSecret:           getOrDefault("JWT_SECRET", "1234567890"),
-		JwtTTL:              72 * time.Hour,
+		DatabaseName:          getOrDefault("DB_NAME", "project-ar-db"),
+		DatabaseHost:          getOrDefault("DB_HOST", "127.0.0.1:5432"),
+		DatabaseUser:          getOrDefault("DB_USER", "postgres"),
+		DatabasePassword:      getOrDefault("DB_PASSWORD", "postgres"),
+		MigrateToVersion:      getOrDefault("MIGRATE", "latest"),
+		MigrationLocation:     getOrDefault("MIGRATION_LOCATION", "internal/infra/database/migrations"),
+		FileStorageLocation:   getOrDefault("FILES_LOCATION", "file_storage"),
+		JwtSecret:             getOrDefault("JWT_SECRET", "1234567890"),
+		JwtTTL:                getDurationOrDefault("JWT_TTL", 15*time.Minute),
+		RefreshTokenTTL:       getDurationOrDefault("REFRESH_TOKEN_TTL", 30*24*time.Hour),
+		SessionFlushInterval:  getDurationOrDefault("SESSION_FLUSH_INTERVAL", time.Minute),
+		MqttBrokerUrl:         getOrDefault("MQTT_BROKER", ""),
+		MqttClientId:          getOrDefault("MQTT_CLIENT_ID", "project-ar-server"),
+		MqttUsername:          getOrDefault("MQTT_USERNAME", ""),
+		MqttPassword:          getOrDefault("MQTT_PASSWORD", ""),
+		MqttEmbeddedAddr:      getOrDefault("MQTT_EMBEDDED_ADDR", ""),
+		DeviceStaleAfter:      getDurationOrDefault("DEVICE_STALE_AFTER", 2*time.Minute),
+		DeviceOfflineAfter:    getDurationOrDefault("DEVICE_OFFLINE_AFTER", 10*time.Minute),
+		PresenceSweepInterval: getDurationOrDefault("PRESENCE_SWEEP_INTERVAL", 30*time.Second),
+		DeepLinkBase:          getOrDefault("DEEP_LINK_BASE", ""),
+		AppUrl:                getOrDefault("APP_URL", "http://localhost:3000"),
+		InvitationTTL:         getDurationOrDefault("INVITATION_TTL", 7*24*time.Hour),
+		PasswordResetTTL:      getDurationOrDefault("PASSWORD_RESET_TTL", time.Hour),
+		EmailVerificationTTL:  getDurationOrDefault("EMAIL_VERIFICATION_TTL", 48*time.Hour),
+		MailFrom:              getOrDefault("MAIL_FROM", "no-reply@localhost"),
+		MailDir:               getOrDefault("MAIL_DIR", "mail_outbox"),
+		SmtpAddr:              getOrDefault("SMTP_ADDR", ""),
+		SmtpUsername:          getOrDefault("SMTP_USERNAME", ""),
+		SmtpPassword:          getOrDefault("SMTP_PASSWORD", ""),
+		DeviceDeletion:        getOneOfOrDefault("DEVICE_DELETION", "CASCADE", "CASCADE", "UNASSIGN"),
+		TrashRetention:        getDurationOrDefault("TRASH_RETENTION", 30*24*time.Hour),
+		TrustedProxies:        getNetworks("TRUSTED_PROXIES"),
 	}
 }
 
@@ -48,3 +96,53 @@ func getOrDefault(key, defaultVal string) string {
 	}
 	return env
 }
+
+func getDurationOrDefault(key string, defaultVal time.Duration) time.Duration {
+	env, set := os.LookupEnv(key)
+	if !set {
+		return defaultVal
+	}
+	d, err := time.ParseDuration(env)
+	if err != nil || d <= 0 {
+		log.Fatalf("%s env var is not a valid duration: %q", key, env)
+	}
+	return d
+}
+
+// getNetworks reads a comma separated list of CIDRs or single addresses.
+func getNetworks(key string) []*net.IPNet {
+	var nets []*net.IPNet
+	for _, s := range strings.Split(os.Getenv(key), ",") {
+		s = strings.TrimSpace(s)
+		if s == "" {
+			continue
+		}
+		if !strings.Contains(s, "/") {
+			if ip := net.ParseIP(s); ip != nil && ip.To4() != nil {
+				s += "/32"
+			} else {
+				s += "/128"
+			}
+		}
+		_, n, err := net.ParseCIDR(s)
+		if err != nil {
+			log.Fatalf("%s env var has an invalid address: %q", key, s)
+		}
+		nets = append(nets, n)
+	}
+	return nets
+}
+
+func getOneOfOrDefault(key, defaultVal string, allowed ...string) string {
+	env, set := os.LookupEnv(key)
+	if !set {
+		return defaultVal
+	}
+	for _, a := range allowed {
+		if env == a {
+			return env
+		}
+	}
+	log.Fatalf("%s env var has to be one of %q, got %q", key, allowed, env)
+	return ""
+}
@@ -3,13 +3,23 @@ package container
 import (
 	"log"
 	"net/http"
+	"strings"
 
 	"github.com/BohdanBoriak/boilerplate-go-back/config"
 	"github.com/BohdanBoriak/boilerplate-go-back/internal/app"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
 	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/database"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/events"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/filesystem"
 	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/http/controllers"
 	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/http/middlewares"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/labels"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/mail"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/mqtt"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/webhooks"
+	paho "github.com/eclipse/paho.mqtt.golang"
 	"github.com/go-chi/jwtauth/v5"
+	"github.com/google/uuid"
 	"github.com/upper/db/v4"
 	"github.com/upper/db/v4/adapter/postgresql"
 )
@@ -18,70 +28,193 @@ type Container struct {
 	Middlewares
 	Services
 	Controllers
+	Mqtt
+	PresenceSweeper   app.PresenceSweeper
+	SessionFlusher    app.SessionFlusher
+	WebhookDispatcher webhooks.Dispatcher
+}
+
+// Mqtt holds the optional MQTT components, both are nil when disabled.
+type Mqtt struct {
+	MqttBroker *mqtt.Broker
+	MqttBridge *mqtt.Bridge
 }
 
 type Middlewares struct {
-	AuthMw func(http.Handler) http.Handler
+	AuthMw       func(http.Handler) http.Handler
+	DeviceAuthMw func(http.Handler) http.Handler
+	RealIPMw     func(http.Handler) http.Handler
 }
 
 type Services struct {
 	app.AuthService
+	app.SessionService
+	app.PasswordResetService
+	app.EmailVerificationService
 	app.UserService
 	app.OrganizationService
+	app.OrganizationMemberService
+	app.InvitationService
 	app.RoomService
 	app.DeviceService
+	app.MeasurementService
+	app.PowerReportService
+	app.CommandService
+	app.DeviceAuthService
+	app.AlertRuleService
+	app.AlertService
+	app.WebhookService
+	app.AdminService
+	app.TrashService
 }
 
 type Controllers struct {
-	AuthController         controllers.AuthController
-	UserController         controllers.UserController
-	OrganizationController controllers.OrganizationController
-	RoomController         controllers.RoomController
-	DeviceController       controllers.DeviceController
+	AuthController               controllers.AuthController
+	UserController               controllers.UserController
+	SessionController            controllers.SessionController
+	OrganizationController       controllers.OrganizationController
+	OrganizationMemberController controllers.OrganizationMemberController
+	InvitationController         controllers.InvitationController
+	RoomController               controllers.RoomController
+	DeviceController             controllers.DeviceController
+	MeasurementController        controllers.MeasurementController
+	PowerReportController        controllers.PowerReportController
+	CommandController            controllers.CommandController
+	AlertRuleController          controllers.AlertRuleController
+	AlertController              controllers.AlertController
+	WebhookController            controllers.WebhookController
+	StreamController             controllers.StreamController
+	LabelController              controllers.LabelController
+	AdminController              controllers.AdminController
+	TrashController              controllers.TrashController
 }
 
 func New(conf config.Configuration) Container {
 	tknAuth := jwtauth.New("HS256", []byte(conf.JwtSecret), nil)
 	sess := getDbSess(conf)
+	eventBus := events.NewBus()
 
 	sessionRepository := database.NewSessRepository(sess)
 	userRepository := database.NewUserRepository(sess)
 	organizationRepository := database.NewOrganizationRepository(sess)
+	organizationMemberRepository := database.NewOrganizationMemberRepository(sess)
+	invitationRepository := database.NewInvitationRepository(sess)
+	passwordResetRepository := database.NewPasswordResetRepository(sess)
 	roomRepository := database.NewRoomRepository(sess)
 	deviceRepository := database.NewDeviceRepository(sess)
+	measurementRepository := database.NewMeasurementRepository(sess)
+	commandRepository := database.NewCommandRepository(sess)
+	deviceTokenRepository := database.NewDeviceTokenRepository(sess)
+	alertRuleRepository := database.NewAlertRuleRepository(sess)
+	alertRepository := database.NewAlertRepository(sess)
+	webhookRepository := database.NewWebhookRepository(sess)
+	webhookDeliveryRepository := database.NewWebhookDeliveryRepository(sess)
+	unitOfWork := database.NewUnitOfWork(sess)
+	webhookOutbox := webhooks.NewOutbox(webhookRepository, webhookDeliveryRepository)
+
+	fileStorageService := filesystem.NewFileStorageService(conf.FileStorageLocation)
+	mailSender := getMailSender(conf)
 
 	userService := app.NewUserService(userRepository)
-	authService := app.NewAuthService(sessionRepository, userRepository, tknAuth, conf.JwtTTL)
-	organizationService := app.NewOrganizationService(organizationRepository, roomRepository)
-	roomServise := app.NewRoomService(roomRepository, organizationRepository)
-	deviceSevise := app.NewDeviceService(deviceRepository, roomRepository, organizationRepository)
-
-	authController := controllers.NewAuthController(authService, userService)
-	userController := controllers.NewUserController(userService, authService)
-	organizationController := controllers.NewOrganizationController(organizationService)
+	sessionService := app.NewSessionService(sessionRepository)
+	organizationMemberService := app.NewOrganizationMemberService(organizationMemberRepository, userRepository, unitOfWork)
+	invitationService := app.NewInvitationService(invitationRepository, organizationRepository, organizationMemberService, unitOfWork, mailSender, conf.JwtSecret, conf.InvitationTTL, conf.AppUrl)
+	authService := app.NewAuthService(sessionRepository, userRepository, invitationService, unitOfWork, tknAuth, conf.JwtTTL, conf.RefreshTokenTTL, conf.JwtSecret)
+	emailVerificationService := app.NewEmailVerificationService(userRepository, mailSender, conf.JwtSecret, conf.EmailVerificationTTL, conf.AppUrl)
+	passwordResetService := app.NewPasswordResetService(passwordResetRepository, userRepository, unitOfWork, mailSender, conf.PasswordResetTTL, conf.AppUrl)
+	deviceDeletion := domain.DeviceDeletion(conf.DeviceDeletion)
+	organizationService := app.NewOrganizationService(organizationRepository, roomRepository, unitOfWork, eventBus, webhookOutbox)
+	roomServise := app.NewRoomService(roomRepository, organizationMemberService, unitOfWork, eventBus, webhookOutbox, fileStorageService, deviceDeletion)
+	deviceSevise := app.NewDeviceService(deviceRepository, roomRepository, organizationRepository, organizationMemberService, unitOfWork, eventBus, webhookOutbox)
+	alertRuleService := app.NewAlertRuleService(alertRuleRepository, alertRepository, deviceRepository, roomRepository, organizationMemberService)
+	alertService := app.NewAlertService(alertRuleRepository, alertRepository, organizationMemberService, eventBus, webhookOutbox)
+	webhookService := app.NewWebhookService(webhookRepository, webhookDeliveryRepository, organizationMemberService, webhooks.NewSender())
+	measurementService := app.NewMeasurementService(measurementRepository, organizationMemberService, alertService)
+	powerReportService := app.NewPowerReportService(deviceRepository, roomRepository, organizationMemberService)
+	commandService := app.NewCommandService(commandRepository, organizationMemberService, eventBus, webhookOutbox)
+	deviceAuthService := app.NewDeviceAuthService(deviceTokenRepository, deviceRepository, unitOfWork, eventBus)
+	adminService := app.NewAdminService(userRepository, sessionRepository, organizationRepository, organizationMemberRepository, roomRepository, deviceRepository, unitOfWork, eventBus, webhookOutbox)
+	trashService := app.NewTrashService(organizationRepository, roomRepository, deviceRepository, organizationMemberService, unitOfWork, eventBus, webhookOutbox, conf.TrashRetention)
+
+	authController := controllers.NewAuthController(authService, userService, invitationService, passwordResetService, emailVerificationService)
+	userController := controllers.NewUserController(userService, authService, emailVerificationService)
+	sessionController := controllers.NewSessionController(sessionService)
+	organizationController := controllers.NewOrganizationController(organizationService, organizationMemberService)
+	organizationMemberController := controllers.NewOrganizationMemberController(organizationMemberService)
+	invitationController := controllers.NewInvitationController(invitationService)
 	roomController := controllers.NewRoomController(roomServise)
-	deviceController := controllers.NewDeviceController(deviceSevise)
+	deviceController := controllers.NewDeviceController(deviceSevise, deviceAuthService)
+	measurementController := controllers.NewMeasurementController(measurementService)
+	powerReportController := controllers.NewPowerReportController(powerReportService)
+	commandController := controllers.NewCommandController(commandService)
+	alertRuleController := controllers.NewAlertRuleController(alertRuleService)
+	alertController := controllers.NewAlertController(alertService)
+	webhookController := controllers.NewWebhookController(webhookService)
+	streamController := controllers.NewStreamController(eventBus, authService, userService, organizationMemberService)
+	labelController := controllers.NewLabelController(deviceSevise, labels.NewGenerator(conf.DeepLinkBase))
+	adminController := controllers.NewAdminController(adminService)
+	trashController := controllers.NewTrashController(trashService)
 
-	authMiddleware := middlewares.AuthMiddleware(tknAuth, authService, userService)
+	authMiddleware := middlewares.AuthMiddleware(tknAuth, authService, userService, sessionService)
+	deviceAuthMiddleware := middlewares.DeviceAuthMiddleware(deviceAuthService, deviceSevise)
+	realIPMiddleware := middlewares.RealIPMiddleware(conf.TrustedProxies)
+
+	presenceSweeper := app.NewPresenceSweeper(deviceSevise, conf.PresenceSweepInterval, conf.DeviceStaleAfter, conf.DeviceOfflineAfter)
+	sessionFlusher := app.NewSessionFlusher(sessionService, conf.SessionFlushInterval)
+
+	mqttComponents := getMqtt(conf, deviceSevise, measurementService, commandService, deviceAuthService, eventBus)
 
 	return Container{
 		Middlewares: Middlewares{
-			AuthMw: authMiddleware,
+			AuthMw:       authMiddleware,
+			DeviceAuthMw: deviceAuthMiddleware,
+			RealIPMw:     realIPMiddleware,
 		},
 		Services: Services{
 			authService,
+			sessionService,
+			passwordResetService,
+			emailVerificationService,
 			userService,
 			organizationService,
+			organizationMemberService,
+			invitationService,
 			roomServise,
 			deviceSevise,
+			measurementService,
+			powerReportService,
+			commandService,
+			deviceAuthService,
+			alertRuleService,
+			alertService,
+			webhookService,
+			adminService,
+			trashService,
 		},
 		Controllers: Controllers{
 			authController,
 			userController,
+			sessionController,
 			organizationController,
+			organizationMemberController,
+			invitationController,
 			roomController,
 			deviceController,
+			measurementController,
+			powerReportController,
+			commandController,
+			alertRuleController,
+			alertController,
+			webhookController,
+			streamController,
+			labelController,
+			adminController,
+			trashController,
 		},
+		Mqtt:              mqttComponents,
+		PresenceSweeper:   presenceSweeper,
+		SessionFlusher:    sessionFlusher,
+		WebhookDispatcher: webhooks.NewDispatcher(webhookService),
 	}
 }
 
@@ -98,3 +231,52 @@ func getDbSess(conf config.Configuration) db.Session {
 	}
 	return sess
 }
+
+// getMailSender sends through SMTP when a server is configured,
+// otherwise messages are only written to files for local use.
+func getMailSender(conf config.Configuration) mail.Sender {
+	if conf.SmtpAddr == "" {
+		return mail.NewFileSender(conf.MailDir, conf.MailFrom)
+	}
+	return mail.NewSMTPSender(conf.SmtpAddr, conf.SmtpUsername, conf.SmtpPassword, conf.MailFrom)
+}
+
+func getMqtt(
+	conf config.Configuration,
+	ds app.DeviceService,
+	ms app.MeasurementService,
+	cs app.CommandService,
+	das app.DeviceAuthService,
+	eb events.Bus) Mqtt {
+	var res Mqtt
+	brokerUrl, username, password := conf.MqttBrokerUrl, conf.MqttUsername, conf.MqttPassword
+
+	if conf.MqttEmbeddedAddr != "" {
+		// the bridge gets one-off credentials for the embedded broker
+		username, password = conf.MqttClientId, uuid.New().String()
+		broker, err := mqtt.NewBroker(conf.MqttEmbeddedAddr, username, password, das, eb)
+		if err != nil {
+			log.Fatalf("Unable to create MQTT broker: %q\n", err)
+		}
+		res.MqttBroker = broker
+		if brokerUrl == "" {
+			host := conf.MqttEmbeddedAddr
+			if strings.HasPrefix(host, ":") {
+				host = "127.0.0.1" + host
+			}
+			brokerUrl = "tcp://" + host
+		}
+	}
+
+	if brokerUrl == "" {
+		return res
+	}
+
+	opts := paho.NewClientOptions().
+		AddBroker(brokerUrl).
+		SetClientID(conf.MqttClientId).
+		SetUsername(username).
+		SetPassword(password)
+	res.MqttBridge = mqtt.NewBridge(opts, ds, ms, cs, eb)
+	return res
+}
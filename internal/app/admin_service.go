@@ -0,0 +1,293 @@
+package app
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/database"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/events"
+)
+
+var (
+	ErrUserBlocked        = domain.NewError(domain.ForbiddenError, "user_blocked", "user account is blocked")
+	ErrSelfAdministration = domain.NewError(domain.ForbiddenError, "self_administration", "administrators can not block or demote themselves")
+	ErrParentDeleted      = domain.NewError(domain.ConflictError, "parent_deleted", "restore the record it belongs to first")
+)
+
+// AdminService is meant for support staff only, it does not check
+// organization membership, the admin routes are gated by role instead.
+type AdminService interface {
+	FindUsers(f domain.UserFilters, p domain.Pagination) (domain.Page[domain.User], error)
+	BlockUser(u domain.User, adminId uint64) (domain.User, error)
+	UnblockUser(u domain.User) (domain.User, error)
+	ChangeRole(u domain.User, role domain.Role, adminId uint64) (domain.User, error)
+	FindDeletedUser(id uint64) (interface{}, error)
+	RestoreUser(u domain.User) (domain.User, error)
+	FindMembers(f domain.MemberFilters, p domain.Pagination) (domain.Page[domain.OrganizationMember], error)
+	FindDeletedOrganization(id uint64) (interface{}, error)
+	RestoreOrganization(o domain.Organization) (domain.Organization, error)
+	FindDeletedRoom(id uint64) (interface{}, error)
+	RestoreRoom(r domain.Room) (domain.Room, error)
+	FindDeletedDevice(id uint64) (interface{}, error)
+	RestoreDevice(d domain.Device) (domain.Device, error)
+}
+
+type adminService struct {
+	userRepo    database.UserRepository
+	sessionRepo database.SessionRepository
+	orgRepo     database.OrganizationRepository
+	memberRepo  database.OrganizationMemberRepository
+	roomRepo    database.RoomRepository
+	deviceRepo  database.DeviceRepository
+	uow         database.UnitOfWork
+	eventBus    events.Bus
+	outbox      WebhookOutbox
+}
+
+func NewAdminService(
+	ur database.UserRepository,
+	sr database.SessionRepository,
+	or database.OrganizationRepository,
+	mr database.OrganizationMemberRepository,
+	rr database.RoomRepository,
+	dr database.DeviceRepository,
+	uow database.UnitOfWork,
+	eb events.Bus,
+	wo WebhookOutbox) AdminService {
+	return adminService{
+		userRepo:    ur,
+		sessionRepo: sr,
+		orgRepo:     or,
+		memberRepo:  mr,
+		roomRepo:    rr,
+		deviceRepo:  dr,
+		uow:         uow,
+		eventBus:    eb,
+		outbox:      wo,
+	}
+}
+
+func (s adminService) FindUsers(f domain.UserFilters, p domain.Pagination) (domain.Page[domain.User], error) {
+	users, err := s.userRepo.FindAll(f, p)
+	if err != nil {
+		log.Printf("AdminService: %s", err)
+		return domain.Page[domain.User]{}, err
+	}
+
+	return users, nil
+}
+
+// BlockUser also signs the user out of every session.
+func (s adminService) BlockUser(u domain.User, adminId uint64) (domain.User, error) {
+	if u.Id == adminId {
+		log.Printf("AdminService: %s", ErrSelfAdministration)
+		return domain.User{}, ErrSelfAdministration
+	}
+	if u.Blocked() {
+		return u, nil
+	}
+
+	now := time.Now()
+	u, err := s.userRepo.SetBlocked(u.Id, &now)
+	if err != nil {
+		log.Printf("AdminService: %s", err)
+		return domain.User{}, err
+	}
+
+	err = s.sessionRepo.DeleteForUser(u.Id)
+	if err != nil {
+		log.Printf("AdminService: %s", err)
+		return domain.User{}, err
+	}
+
+	return u, nil
+}
+
+func (s adminService) UnblockUser(u domain.User) (domain.User, error) {
+	if !u.Blocked() {
+		return u, nil
+	}
+
+	u, err := s.userRepo.SetBlocked(u.Id, nil)
+	if err != nil {
+		log.Printf("AdminService: %s", err)
+		return domain.User{}, err
+	}
+
+	return u, nil
+}
+
+func (s adminService) ChangeRole(u domain.User, role domain.Role, adminId uint64) (domain.User, error) {
+	if u.Id == adminId && role != domain.AdminRole {
+		log.Printf("AdminService: %s", ErrSelfAdministration)
+		return domain.User{}, ErrSelfAdministration
+	}
+
+	u, err := s.userRepo.SetRole(u.Id, role)
+	if err != nil {
+		log.Printf("AdminService: %s", err)
+		return domain.User{}, err
+	}
+
+	return u, nil
+}
+
+func (s adminService) FindDeletedUser(id uint64) (interface{}, error) {
+	u, err := s.userRepo.FindById(id)
+	if err == nil && u.DeletedDate == nil {
+		err = domain.ErrNotFound
+	}
+	if err != nil {
+		log.Printf("AdminService: %s", err)
+		return nil, err
+	}
+
+	return u, nil
+}
+
+// RestoreUser fails when somebody has registered with the same email
+// after the account was deleted.
+func (s adminService) RestoreUser(u domain.User) (domain.User, error) {
+	_, err := s.userRepo.FindByEmail(u.Email)
+	if err == nil {
+		log.Printf("AdminService: %s", domain.ErrEmailTaken)
+		return domain.User{}, domain.ErrEmailTaken
+	} else if !errors.Is(err, domain.ErrNotFound) {
+		log.Printf("AdminService: %s", err)
+		return domain.User{}, err
+	}
+
+	err = s.userRepo.Restore(u.Id)
+	if err != nil {
+		log.Printf("AdminService: %s", err)
+		return domain.User{}, err
+	}
+
+	u.DeletedDate = nil
+	return u, nil
+}
+
+func (s adminService) FindMembers(f domain.MemberFilters, p domain.Pagination) (domain.Page[domain.OrganizationMember], error) {
+	mems, err := s.memberRepo.FindAll(f, p)
+	if err != nil {
+		log.Printf("AdminService: %s", err)
+		return domain.Page[domain.OrganizationMember]{}, err
+	}
+
+	return mems, nil
+}
+
+func (s adminService) FindDeletedOrganization(id uint64) (interface{}, error) {
+	o, err := s.orgRepo.FindDeletedById(id)
+	if err != nil {
+		log.Printf("AdminService: %s", err)
+		return nil, err
+	}
+
+	return o, nil
+}
+
+// RestoreOrganization, RestoreRoom and RestoreDevice restore the same way
+// the trash does, only the retention does not apply to them.
+func (s adminService) RestoreOrganization(o domain.Organization) (domain.Organization, error) {
+	var evts []domain.Event
+	err := s.uow.Do(func(tx database.Tx) error {
+		var err error
+		evts, err = restoreOrganization(tx, o)
+		if err != nil {
+			return err
+		}
+		return addAllWithin(tx, s.outbox, evts)
+	})
+	if err != nil {
+		log.Printf("AdminService: %s", err)
+		return domain.Organization{}, err
+	}
+	for _, e := range evts {
+		s.eventBus.Publish(e)
+	}
+
+	o, err = s.orgRepo.FindById(o.Id)
+	if err != nil {
+		log.Printf("AdminService: %s", err)
+		return domain.Organization{}, err
+	}
+
+	return o, nil
+}
+
+func (s adminService) FindDeletedRoom(id uint64) (interface{}, error) {
+	r, err := s.roomRepo.FindDeletedById(id)
+	if err != nil {
+		log.Printf("AdminService: %s", err)
+		return nil, err
+	}
+
+	return r, nil
+}
+
+func (s adminService) RestoreRoom(r domain.Room) (domain.Room, error) {
+	var evts []domain.Event
+	err := s.uow.Do(func(tx database.Tx) error {
+		var err error
+		evts, err = restoreRoom(tx, r)
+		if err != nil {
+			return err
+		}
+		return addAllWithin(tx, s.outbox, evts)
+	})
+	if err != nil {
+		log.Printf("AdminService: %s", err)
+		return domain.Room{}, err
+	}
+	for _, e := range evts {
+		s.eventBus.Publish(e)
+	}
+
+	r, err = s.roomRepo.FindById(r.Id)
+	if err != nil {
+		log.Printf("AdminService: %s", err)
+		return domain.Room{}, err
+	}
+
+	return r, nil
+}
+
+func (s adminService) FindDeletedDevice(id uint64) (interface{}, error) {
+	d, err := s.deviceRepo.FindDeletedById(id)
+	if err != nil {
+		log.Printf("AdminService: %s", err)
+		return nil, err
+	}
+
+	return d, nil
+}
+
+func (s adminService) RestoreDevice(d domain.Device) (domain.Device, error) {
+	var evts []domain.Event
+	err := s.uow.Do(func(tx database.Tx) error {
+		var err error
+		evts, err = restoreDevice(tx, d)
+		if err != nil {
+			return err
+		}
+		return addAllWithin(tx, s.outbox, evts)
+	})
+	if err != nil {
+		log.Printf("AdminService: %s", err)
+		return domain.Device{}, err
+	}
+	for _, e := range evts {
+		s.eventBus.Publish(e)
+	}
+
+	d, err = s.deviceRepo.FindById(d.Id)
+	if err != nil {
+		log.Printf("AdminService: %s", err)
+		return domain.Device{}, err
+	}
+
+	return d, nil
+}
@@ -0,0 +1,167 @@
+package app
+
+import (
+	"log"
+
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/database"
+)
+
+var (
+	ErrRuleTarget = domain.NewError(domain.ValidationError, "invalid_rule_target", "alert rule must target exactly one device or room")
+	ErrNotSensor  = domain.NewError(domain.ValidationError, "not_sensor", "alert rules can be set only on SENSOR devices")
+)
+
+type AlertRuleService interface {
+	Save(ar domain.AlertRule, uId uint64) (domain.AlertRule, error)
+	Update(ar domain.AlertRule, uId uint64) (domain.AlertRule, error)
+	Delete(ar domain.AlertRule, uId uint64) error
+	Find(id uint64) (interface{}, error)
+	FindForOrganization(oId uint64, p domain.Pagination, uId uint64) (domain.Page[domain.AlertRule], error)
+	CheckAccess(oId, uId uint64, p domain.Permission) error
+}
+
+type alertRuleService struct {
+	alertRuleRepo database.AlertRuleRepository
+	alertRepo     database.AlertRepository
+	deviceRepo    database.DeviceRepository
+	roomRepo      database.RoomRepository
+	memberService OrganizationMemberService
+}
+
+func NewAlertRuleService(
+	arr database.AlertRuleRepository,
+	ar database.AlertRepository,
+	dr database.DeviceRepository,
+	rr database.RoomRepository,
+	ms OrganizationMemberService) AlertRuleService {
+	return alertRuleService{
+		alertRuleRepo: arr,
+		alertRepo:     ar,
+		deviceRepo:    dr,
+		roomRepo:      rr,
+		memberService: ms,
+	}
+}
+
+func (s alertRuleService) Save(ar domain.AlertRule, uId uint64) (domain.AlertRule, error) {
+	var err error
+	switch {
+	case ar.DeviceId != nil && ar.RoomId == nil:
+		var dv domain.Device
+		dv, err = s.deviceRepo.FindById(*ar.DeviceId)
+		if err != nil {
+			log.Printf("AlertRuleService: %s", err)
+			return domain.AlertRule{}, err
+		}
+		if dv.Category != string(database.SENSOR) {
+			err = ErrNotSensor
+			log.Printf("AlertRuleService: %s", err)
+			return domain.AlertRule{}, err
+		}
+		ar.OrganizationId = dv.OrganizationId
+	case ar.RoomId != nil && ar.DeviceId == nil:
+		var rom domain.Room
+		rom, err = s.roomRepo.FindById(*ar.RoomId)
+		if err != nil {
+			log.Printf("AlertRuleService: %s", err)
+			return domain.AlertRule{}, err
+		}
+		ar.OrganizationId = rom.OrganizationId
+	default:
+		err = ErrRuleTarget
+		log.Printf("AlertRuleService: %s", err)
+		return domain.AlertRule{}, err
+	}
+
+	err = s.CheckAccess(ar.OrganizationId, uId, domain.ManagePermission)
+	if err != nil {
+		log.Printf("AlertRuleService: %s", err)
+		return domain.AlertRule{}, err
+	}
+
+	ar.UserId = uId
+	ar, err = s.alertRuleRepo.Save(ar)
+	if err != nil {
+		log.Printf("AlertRuleService: %s", err)
+		return domain.AlertRule{}, err
+	}
+
+	return ar, nil
+}
+
+func (s alertRuleService) Update(ar domain.AlertRule, uId uint64) (domain.AlertRule, error) {
+	err := s.CheckAccess(ar.OrganizationId, uId, domain.ManagePermission)
+	if err != nil {
+		log.Printf("AlertRuleService: %s", err)
+		return domain.AlertRule{}, err
+	}
+
+	ar, err = s.alertRuleRepo.Update(ar)
+	if err != nil {
+		log.Printf("AlertRuleService: %s", err)
+		return domain.AlertRule{}, err
+	}
+
+	// the condition may have changed, so whatever was raised under
+	// the old one is not relevant anymore
+	err = s.alertRepo.ResolveForRule(ar.Id)
+	if err != nil {
+		log.Printf("AlertRuleService: %s", err)
+		return domain.AlertRule{}, err
+	}
+
+	return ar, nil
+}
+
+func (s alertRuleService) Delete(ar domain.AlertRule, uId uint64) error {
+	err := s.CheckAccess(ar.OrganizationId, uId, domain.ManagePermission)
+	if err != nil {
+		log.Printf("AlertRuleService: %s", err)
+		return err
+	}
+
+	err = s.alertRuleRepo.Delete(ar.Id)
+	if err != nil {
+		log.Printf("AlertRuleService: %s", err)
+		return err
+	}
+
+	err = s.alertRepo.ResolveForRule(ar.Id)
+	if err != nil {
+		log.Printf("AlertRuleService: %s", err)
+		return err
+	}
+
+	return nil
+}
+
+func (s alertRuleService) Find(id uint64) (interface{}, error) {
+	ar, err := s.alertRuleRepo.FindById(id)
+	if err != nil {
+		log.Printf("AlertRuleService: %s", err)
+		return nil, err
+	}
+
+	return ar, nil
+}
+
+func (s alertRuleService) FindForOrganization(oId uint64, p domain.Pagination, uId uint64) (domain.Page[domain.AlertRule], error) {
+	err := s.CheckAccess(oId, uId, domain.ViewPermission)
+	if err != nil {
+		log.Printf("AlertRuleService: %s", err)
+		return domain.Page[domain.AlertRule]{}, err
+	}
+
+	rules, err := s.alertRuleRepo.FindForOrganization(oId, p)
+	if err != nil {
+		log.Printf("AlertRuleService: %s", err)
+		return domain.Page[domain.AlertRule]{}, err
+	}
+
+	return rules, nil
+}
+
+func (s alertRuleService) CheckAccess(oId, uId uint64, p domain.Permission) error {
+	return s.memberService.Authorize(oId, uId, p)
+}
@@ -0,0 +1,256 @@
+package app
+
+import (
+	"errors"
+	"log"
+	"sort"
+
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/database"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/events"
+)
+
+var ErrAlertPending = domain.NewError(domain.ValidationError, "alert_pending", "alert condition has not lasted long enough to be acknowledged")
+
+type AlertService interface {
+	Find(id uint64) (interface{}, error)
+	FindForOrganization(oId uint64, status *domain.AlertStatus, p domain.Pagination, uId uint64) (domain.Page[domain.Alert], error)
+	Acknowledge(a domain.Alert, uId uint64) (domain.Alert, error)
+	Evaluate(dv domain.Device, ms []domain.Measurement) error
+	CheckAccess(oId, uId uint64, p domain.Permission) error
+}
+
+type alertService struct {
+	alertRuleRepo database.AlertRuleRepository
+	alertRepo     database.AlertRepository
+	memberService OrganizationMemberService
+	eventBus      events.Bus
+	outbox        WebhookOutbox
+}
+
+func NewAlertService(
+	arr database.AlertRuleRepository,
+	ar database.AlertRepository,
+	ms OrganizationMemberService,
+	eb events.Bus,
+	wo WebhookOutbox) AlertService {
+	return alertService{
+		alertRuleRepo: arr,
+		alertRepo:     ar,
+		memberService: ms,
+		eventBus:      eb,
+		outbox:        wo,
+	}
+}
+
+func (s alertService) Find(id uint64) (interface{}, error) {
+	a, err := s.alertRepo.FindById(id)
+	if err != nil {
+		log.Printf("AlertService: %s", err)
+		return nil, err
+	}
+
+	return a, nil
+}
+
+// FindForOrganization lists open and resolved alerts, pending ones are
+// returned only when asked for explicitly.
+func (s alertService) FindForOrganization(oId uint64, status *domain.AlertStatus, p domain.Pagination, uId uint64) (domain.Page[domain.Alert], error) {
+	err := s.CheckAccess(oId, uId, domain.ViewPermission)
+	if err != nil {
+		log.Printf("AlertService: %s", err)
+		return domain.Page[domain.Alert]{}, err
+	}
+
+	statuses := []domain.AlertStatus{domain.AlertOpen, domain.AlertResolved}
+	if status != nil {
+		statuses = []domain.AlertStatus{*status}
+	}
+	alerts, err := s.alertRepo.FindForOrganization(oId, p, statuses...)
+	if err != nil {
+		log.Printf("AlertService: %s", err)
+		return domain.Page[domain.Alert]{}, err
+	}
+
+	return alerts, nil
+}
+
+func (s alertService) Acknowledge(a domain.Alert, uId uint64) (domain.Alert, error) {
+	err := s.CheckAccess(a.OrganizationId, uId, domain.OperatePermission)
+	if err != nil {
+		log.Printf("AlertService: %s", err)
+		return domain.Alert{}, err
+	}
+
+	if a.Status == domain.AlertPending {
+		err = ErrAlertPending
+		log.Printf("AlertService: %s", err)
+		return domain.Alert{}, err
+	}
+	if a.AcknowledgedDate != nil {
+		return a, nil
+	}
+
+	acked, err := s.alertRepo.Acknowledge(a.Id, uId)
+	if errors.Is(err, domain.ErrNotFound) {
+		// acknowledged by someone else in the meantime
+		acked, err = s.alertRepo.FindById(a.Id)
+	}
+	if err != nil {
+		log.Printf("AlertService: %s", err)
+		return domain.Alert{}, err
+	}
+
+	return acked, nil
+}
+
+// Evaluate runs fresh readings of the device through every rule that covers it.
+// A breach starts a pending alert, which opens once the condition has held
+// for the rule duration; a reading back within the limit resolves it.
+func (s alertService) Evaluate(dv domain.Device, ms []domain.Measurement) error {
+	rules, err := s.alertRuleRepo.FindForDevice(dv)
+	if err != nil {
+		log.Printf("AlertService: %s", err)
+		return err
+	}
+	if len(rules) == 0 {
+		return nil
+	}
+
+	ms = append([]domain.Measurement(nil), ms...)
+	sort.Slice(ms, func(i, j int) bool { return ms[i].MeasuredAt.Before(ms[j].MeasuredAt) })
+
+	for _, rule := range rules {
+		if rule.Units != nil && (dv.Units == nil || *dv.Units != *rule.Units) {
+			continue
+		}
+
+		err = s.evaluateRule(dv, rule, ms)
+		if err != nil {
+			log.Printf("AlertService: %s", err)
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s alertService) evaluateRule(dv domain.Device, rule domain.AlertRule, ms []domain.Measurement) error {
+	active, err := s.alertRepo.FindActive(rule.Id, dv.Id)
+	if err != nil {
+		return err
+	}
+
+	for _, m := range ms {
+		if !rule.Breached(m.Value) {
+			if active == nil {
+				continue
+			}
+			if active.Status == domain.AlertPending {
+				err = s.alertRepo.DeletePending(active.Id)
+				if err != nil {
+					return err
+				}
+				active = nil
+				continue
+			}
+
+			next := *active
+			resolved := m.MeasuredAt
+			next.Status = domain.AlertResolved
+			next.ResolvedDate = &resolved
+			a, err := s.alertRepo.Advance(next, active.Status)
+			if errors.Is(err, domain.ErrNotFound) {
+				active, err = s.alertRepo.FindActive(rule.Id, dv.Id)
+				if err != nil {
+					return err
+				}
+				continue
+			} else if err != nil {
+				return err
+			}
+			s.publish(domain.AlertClosed, dv, a)
+			active = nil
+			continue
+		}
+
+		if active == nil {
+			active, err = s.start(dv, rule, m)
+			if err != nil {
+				return err
+			}
+			if active == nil {
+				continue
+			}
+		}
+
+		next := *active
+		next.Value = m.Value
+		opened := false
+		if next.Status == domain.AlertPending && m.MeasuredAt.Sub(next.StartedDate) >= rule.Duration {
+			openedAt := m.MeasuredAt
+			next.Status = domain.AlertOpen
+			next.OpenedDate = &openedAt
+			opened = true
+		}
+
+		a, err := s.alertRepo.Advance(next, active.Status)
+		if errors.Is(err, domain.ErrNotFound) {
+			// a concurrent evaluation changed the alert first, carry on from its state
+			active, err = s.alertRepo.FindActive(rule.Id, dv.Id)
+			if err != nil {
+				return err
+			}
+			continue
+		} else if err != nil {
+			return err
+		}
+		active = &a
+
+		if opened {
+			s.publish(domain.AlertOpened, dv, a)
+		}
+	}
+
+	return nil
+}
+
+// start saves a new pending alert for the breach, or returns the one that
+// a concurrent evaluation has started for the rule and device first.
+func (s alertService) start(dv domain.Device, rule domain.AlertRule, m domain.Measurement) (*domain.Alert, error) {
+	a, err := s.alertRepo.Save(domain.Alert{
+		RuleId:         rule.Id,
+		OrganizationId: dv.OrganizationId,
+		DeviceId:       dv.Id,
+		RoomId:         dv.RoomId,
+		Status:         domain.AlertPending,
+		Value:          m.Value,
+		StartedDate:    m.MeasuredAt,
+	})
+	if errors.Is(err, database.ErrAlertActive) {
+		return s.alertRepo.FindActive(rule.Id, dv.Id)
+	} else if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+func (s alertService) publish(t domain.EventType, dv domain.Device, a domain.Alert) {
+	e := domain.Event{
+		Type:           t,
+		OrganizationId: a.OrganizationId,
+		RoomId:         a.RoomId,
+		DeviceGUID:     &dv.GUID,
+		Data:           a,
+	}
+	err := s.outbox.Add(e)
+	if err != nil {
+		// the alert is stored already, the evaluation carries on
+		log.Printf("AlertService: %s", err)
+	}
+	s.eventBus.Publish(e)
+}
+
+func (s alertService) CheckAccess(oId, uId uint64, p domain.Permission) error {
+	return s.memberService.Authorize(oId, uId, p)
+}
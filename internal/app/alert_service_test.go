@@ -0,0 +1,245 @@
+package app
+
+import (
+	"testing"
+	"time"
+
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/database"
+)
+
+// fakeAlerts keeps alerts in memory the way the alert repository does.
+// When raced is set, the first FindActive returns seen instead, the way it
+// looks to an evaluation that another one overtakes.
+type fakeAlerts struct {
+	database.AlertRepository
+	alerts map[uint64]domain.Alert
+	nextId uint64
+	raced  bool
+	seen   *domain.Alert
+}
+
+func (f *fakeAlerts) Save(a domain.Alert) (domain.Alert, error) {
+	if a.Status != domain.AlertResolved {
+		if active, _ := f.find(a.RuleId, a.DeviceId); active != nil {
+			return domain.Alert{}, database.ErrAlertActive
+		}
+	}
+	f.nextId++
+	a.Id = f.nextId
+	f.alerts[a.Id] = a
+	return a, nil
+}
+
+func (f *fakeAlerts) Advance(a domain.Alert, from domain.AlertStatus) (domain.Alert, error) {
+	stored, ok := f.alerts[a.Id]
+	if !ok || stored.Status != from {
+		return domain.Alert{}, domain.ErrNotFound
+	}
+	stored.Status, stored.Value = a.Status, a.Value
+	stored.OpenedDate, stored.ResolvedDate = a.OpenedDate, a.ResolvedDate
+	f.alerts[a.Id] = stored
+	return stored, nil
+}
+
+func (f *fakeAlerts) FindActive(ruleId, deviceId uint64) (*domain.Alert, error) {
+	if f.raced {
+		f.raced = false
+		return f.seen, nil
+	}
+	return f.find(ruleId, deviceId)
+}
+
+func (f *fakeAlerts) find(ruleId, deviceId uint64) (*domain.Alert, error) {
+	for _, a := range f.alerts {
+		if a.RuleId == ruleId && a.DeviceId == deviceId && a.Status != domain.AlertResolved {
+			return &a, nil
+		}
+	}
+	return nil, nil
+}
+
+func (f *fakeAlerts) DeletePending(id uint64) error {
+	if f.alerts[id].Status == domain.AlertPending {
+		delete(f.alerts, id)
+	}
+	return nil
+}
+
+type fakeBus struct {
+	published []domain.Event
+}
+
+func (b *fakeBus) Publish(e domain.Event) {
+	b.published = append(b.published, e)
+}
+
+func (b *fakeBus) Subscribe() (<-chan domain.Event, func()) {
+	return nil, func() {}
+}
+
+type fakeOutbox struct {
+	added []domain.Event
+}
+
+func (o *fakeOutbox) Add(e domain.Event) error {
+	o.added = append(o.added, e)
+	return nil
+}
+
+func (o *fakeOutbox) AddWithin(_ database.Tx, e domain.Event) error {
+	return o.Add(e)
+}
+
+func TestAlertServiceEvaluateRule(t *testing.T) {
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	at := func(d time.Duration) time.Time { return start.Add(d) }
+	readings := func(values ...float64) []domain.Measurement {
+		ms := make([]domain.Measurement, 0, len(values))
+		for i, v := range values {
+			ms = append(ms, domain.Measurement{Value: v, MeasuredAt: at(time.Duration(i) * time.Minute)})
+		}
+		return ms
+	}
+
+	tests := []struct {
+		name     string
+		operator domain.AlertOperator
+		duration time.Duration
+		active   *domain.Alert
+		raced    bool          // active was stored by a concurrent evaluation
+		seen     *domain.Alert // what this evaluation read before that
+		ms       []domain.Measurement
+		status   domain.AlertStatus // of the last alert, empty when none is left
+		events   []domain.EventType
+	}{
+		{
+			name:     "within the limit",
+			operator: domain.AlertGreater,
+			ms:       readings(29, 30),
+		},
+		{
+			name:     "breach opens at once without a duration",
+			operator: domain.AlertGreater,
+			ms:       readings(31),
+			status:   domain.AlertOpen,
+			events:   []domain.EventType{domain.AlertOpened},
+		},
+		{
+			name:     "equal value breaches an inclusive rule",
+			operator: domain.AlertGreaterOrEqual,
+			ms:       readings(30),
+			status:   domain.AlertOpen,
+			events:   []domain.EventType{domain.AlertOpened},
+		},
+		{
+			name:     "breach shorter than the duration stays pending",
+			operator: domain.AlertGreater,
+			duration: 5 * time.Minute,
+			ms:       readings(31, 32, 33),
+			status:   domain.AlertPending,
+		},
+		{
+			name:     "breach lasting the duration opens once",
+			operator: domain.AlertLess,
+			duration: 2 * time.Minute,
+			ms:       readings(10, 11, 12, 13),
+			status:   domain.AlertOpen,
+			events:   []domain.EventType{domain.AlertOpened},
+		},
+		{
+			name:     "pending alert is dropped when the value recovers",
+			operator: domain.AlertGreater,
+			duration: 5 * time.Minute,
+			ms:       readings(31, 29),
+		},
+		{
+			name:     "open alert is resolved when the value recovers",
+			operator: domain.AlertGreater,
+			active:   &domain.Alert{Status: domain.AlertOpen, StartedDate: at(-time.Hour)},
+			ms:       readings(29),
+			status:   domain.AlertResolved,
+			events:   []domain.EventType{domain.AlertClosed},
+		},
+		{
+			name:     "open alert stays open while breached",
+			operator: domain.AlertGreater,
+			active:   &domain.Alert{Status: domain.AlertOpen, StartedDate: at(-time.Hour)},
+			ms:       readings(35, 36),
+			status:   domain.AlertOpen,
+		},
+		{
+			name:     "pending alert keeps its start across evaluations",
+			operator: domain.AlertGreater,
+			duration: 30 * time.Minute,
+			active:   &domain.Alert{Status: domain.AlertPending, StartedDate: at(-30 * time.Minute)},
+			ms:       readings(31),
+			status:   domain.AlertOpen,
+			events:   []domain.EventType{domain.AlertOpened},
+		},
+		{
+			name:     "alert started concurrently is taken over",
+			operator: domain.AlertGreater,
+			duration: 30 * time.Minute,
+			active:   &domain.Alert{Status: domain.AlertPending, StartedDate: at(-30 * time.Minute)},
+			raced:    true,
+			ms:       readings(31),
+			status:   domain.AlertOpen,
+			events:   []domain.EventType{domain.AlertOpened},
+		},
+		{
+			name:     "alert opened concurrently is not opened again",
+			operator: domain.AlertGreater,
+			duration: 30 * time.Minute,
+			active:   &domain.Alert{Status: domain.AlertOpen, StartedDate: at(-30 * time.Minute)},
+			raced:    true,
+			seen:     &domain.Alert{Id: 1, Status: domain.AlertPending, StartedDate: at(-30 * time.Minute)},
+			ms:       readings(31),
+			status:   domain.AlertOpen,
+		},
+		{
+			name:     "alert resolved concurrently is not resolved again",
+			operator: domain.AlertGreater,
+			active:   &domain.Alert{Status: domain.AlertResolved, StartedDate: at(-time.Hour)},
+			raced:    true,
+			seen:     &domain.Alert{Id: 1, Status: domain.AlertOpen, StartedDate: at(-time.Hour)},
+			ms:       readings(29),
+			status:   domain.AlertResolved,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dv := domain.Device{Id: 5, OrganizationId: 2}
+			rule := domain.AlertRule{Id: 9, OrganizationId: 2, Operator: tt.operator, Threshold: 30, Duration: tt.duration}
+			repo := &fakeAlerts{alerts: map[uint64]domain.Alert{}, raced: tt.raced, seen: tt.seen}
+			if tt.active != nil {
+				a := *tt.active
+				a.RuleId, a.DeviceId, a.OrganizationId = rule.Id, dv.Id, dv.OrganizationId
+				_, _ = repo.Save(a)
+			}
+			bus, outbox := &fakeBus{}, &fakeOutbox{}
+			s := alertService{alertRepo: repo, eventBus: bus, outbox: outbox}
+
+			err := s.evaluateRule(dv, rule, tt.ms)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var status domain.AlertStatus
+			if a, ok := repo.alerts[repo.nextId]; ok {
+				status = a.Status
+			}
+			if status != tt.status {
+				t.Errorf("alert status %q, want %q", status, tt.status)
+			}
+			if len(bus.published) != len(tt.events) || len(outbox.added) != len(tt.events) {
+				t.Fatalf("published %d and queued %d events, want %v", len(bus.published), len(outbox.added), tt.events)
+			}
+			for i, e := range bus.published {
+				if e.Type != tt.events[i] {
+					t.Errorf("event %d is %s, want %s", i, e.Type, tt.events[i])
+				}
+			}
+		})
+	}
+}
@@ -1,118 +1,363 @@
 package app
 
 import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
 	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
 	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/database"
 	"github.com/go-chi/jwtauth/v5"
 	"github.com/google/uuid"
-	"github.com/upper/db/v4"
 	"golang.org/x/crypto/bcrypt"
 	"log"
+	"strings"
 	"time"
 )
 
+const refreshNonceLength = 32
+
+var (
+	ErrInvalidCredentials  = domain.NewError(domain.UnauthorizedError, "invalid_credentials", "invalid credentials")
+	ErrWrongPassword       = domain.NewError(domain.ValidationError, "wrong_password", "old password is incorrect")
+	ErrInvalidRefreshToken = domain.NewError(domain.UnauthorizedError, "invalid_refresh_token", "refresh token is invalid or has expired")
+	ErrRefreshTokenReused  = domain.NewError(domain.UnauthorizedError, "refresh_token_reused", "refresh token has already been used, the session is closed")
+)
+
 type AuthService interface {
-	Register(user domain.User) (domain.User, string, error)
-	Login(user domain.User) (domain.User, string, error)
+	Register(reg domain.Registration, c domain.Client) (domain.User, domain.AuthTokens, error)
+	Login(user domain.User, c domain.Client) (domain.User, domain.AuthTokens, error)
+	Refresh(token string, c domain.Client) (domain.User, domain.AuthTokens, error)
 	Logout(sess domain.Session) error
 	Check(sess domain.Session) error
-	GenerateJwt(user domain.User) (string, error)
+	ChangePassword(user domain.User, sess domain.Session, cp domain.ChangePassword) error
+	GenerateTokens(user domain.User, c domain.Client) (domain.AuthTokens, error)
 }
 
 type authService struct {
-	authRepo  database.SessionRepository
-	userRepo  database.UserRepository
-	tokenAuth *jwtauth.JWTAuth
-	jwtTTL    time.Duration
+	authRepo          database.SessionRepository
+	userRepo          database.UserRepository
+	invitationService InvitationService
+	uow               database.UnitOfWork
+	tokenAuth         *jwtauth.JWTAuth
+	jwtTTL            time.Duration
+	refreshTTL        time.Duration
+	secret            []byte
 }
 
-func NewAuthService(ar database.SessionRepository, ur database.UserRepository, ta *jwtauth.JWTAuth, jwtTtl time.Duration) AuthService {
+func NewAuthService(
+	ar database.SessionRepository,
+	ur database.UserRepository,
+	is InvitationService,
+	uow database.UnitOfWork,
+	ta *jwtauth.JWTAuth,
+	jwtTtl time.Duration,
+	refreshTtl time.Duration,
+	secret string) AuthService {
 	return authService{
-		authRepo:  ar,
-		userRepo:  ur,
-		tokenAuth: ta,
-		jwtTTL:    jwtTtl,
+		authRepo:          ar,
+		userRepo:          ur,
+		invitationService: is,
+		uow:               uow,
+		tokenAuth:         ta,
+		jwtTTL:            jwtTtl,
+		refreshTTL:        refreshTtl,
+		secret:            []byte(secret),
 	}
 }
 
-func (s authService) Register(user domain.User) (domain.User, string, error) {
-	_, err := s.userRepo.FindByEmail(user.Email)
-	if err == nil {
-		log.Printf("invalid credentials")
-		return domain.User{}, "", errors.New("invalid credentials")
-	} else if !errors.Is(err, db.ErrNoMoreRows) {
-		log.Print(err)
-		return domain.User{}, "", err
-	}
-
-	user.Password, err = s.generatePasswordHash(user.Password)
+// Register creates the account and accepts the invitation, if there is
+// one, together: a failed invitation leaves no account behind. The
+// invitation was mailed to the address, so it is verified right away.
+func (s authService) Register(reg domain.Registration, c domain.Client) (domain.User, domain.AuthTokens, error) {
+	user := reg.User
+	hash, err := s.generatePasswordHash(user.Password)
 	if err != nil {
-		log.Printf("UserService: %s", err)
-		return domain.User{}, "", err
+		log.Printf("AuthService: %s", err)
+		return domain.User{}, domain.AuthTokens{}, err
 	}
+	user.Password = hash
 
-	user, err = s.userRepo.Save(user)
+	err = s.uow.Do(func(tx database.Tx) error {
+		// the unique email index settles concurrent registrations,
+		// this check only gives the usual answer sooner
+		_, err := tx.Users().FindByEmail(user.Email)
+		if err == nil {
+			return domain.ErrEmailTaken
+		} else if !errors.Is(err, domain.ErrNotFound) {
+			return err
+		}
+
+		if reg.InvitationToken != "" {
+			now := time.Now()
+			user.VerifiedDate = &now
+		}
+		user, err = tx.Users().Save(user)
+		if err != nil {
+			return err
+		}
+
+		if reg.InvitationToken != "" {
+			_, err = s.invitationService.AcceptWithin(tx, reg.InvitationToken, user)
+		}
+		return err
+	})
 	if err != nil {
-		log.Print(err)
-		return domain.User{}, "", err
+		log.Printf("AuthService: %s", err)
+		return domain.User{}, domain.AuthTokens{}, err
 	}
 
-	token, err := s.GenerateJwt(user)
-	return user, token, err
+	tokens, err := s.GenerateTokens(user, c)
+	return user, tokens, err
 }
 
-func (s authService) Login(user domain.User) (domain.User, string, error) {
+func (s authService) Login(user domain.User, c domain.Client) (domain.User, domain.AuthTokens, error) {
 	u, err := s.userRepo.FindByEmail(user.Email)
 	if err != nil {
-		if errors.Is(err, db.ErrNoMoreRows) {
+		if errors.Is(err, domain.ErrNotFound) {
 			log.Printf("AuthService: failed to find user %s", err)
+			err = ErrInvalidCredentials
 		}
 		log.Printf("AuthService: login error %s", err)
-		return domain.User{}, "", err
+		return domain.User{}, domain.AuthTokens{}, err
 	}
 
 	valid := s.checkPasswordHash(user.Password, u.Password)
 	if !valid {
-		return domain.User{}, "", errors.New("invalid credentials")
+		return domain.User{}, domain.AuthTokens{}, ErrInvalidCredentials
+	}
+	if u.Blocked() {
+		log.Printf("AuthService: %s", ErrUserBlocked)
+		return domain.User{}, domain.AuthTokens{}, ErrUserBlocked
 	}
 
-	token, err := s.GenerateJwt(u)
+	tokens, err := s.GenerateTokens(u, c)
 	if err != nil {
-		log.Printf("AuthService->s.GenerateJwt %s", err)
-		return domain.User{}, "", err
+		log.Printf("AuthService->s.GenerateTokens %s", err)
+		return domain.User{}, domain.AuthTokens{}, err
 	}
 
-	return u, token, err
+	return u, tokens, err
+}
+
+// Refresh exchanges a refresh token for a new pair within the same session.
+// A token that was already exchanged means it has leaked, so the whole
+// session is closed for the legitimate client and the thief alike.
+func (s authService) Refresh(token string, c domain.Client) (domain.User, domain.AuthTokens, error) {
+	sId, ok := s.verifyRefreshToken(token)
+	if !ok {
+		log.Printf("AuthService: %s", ErrInvalidRefreshToken)
+		return domain.User{}, domain.AuthTokens{}, ErrInvalidRefreshToken
+	}
+
+	sess, err := s.authRepo.FindByUUID(sId)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			err = ErrInvalidRefreshToken
+		}
+		log.Printf("AuthService: %s", err)
+		return domain.User{}, domain.AuthTokens{}, err
+	}
+
+	oldHash := s.hashToken(token)
+	if sess.RefreshHash != oldHash {
+		return domain.User{}, domain.AuthTokens{}, s.closeReused(sess)
+	}
+	if sess.RefreshExpiresDate == nil || time.Now().After(*sess.RefreshExpiresDate) {
+		log.Printf("AuthService: %s", ErrInvalidRefreshToken)
+		return domain.User{}, domain.AuthTokens{}, ErrInvalidRefreshToken
+	}
+
+	u, err := s.userRepo.FindById(sess.UserId)
+	if err == nil && (u.DeletedDate != nil || u.Blocked()) {
+		err = domain.ErrNotFound
+	}
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			err = ErrInvalidRefreshToken
+		}
+		log.Printf("AuthService: %s", err)
+		return domain.User{}, domain.AuthTokens{}, err
+	}
+
+	tokens, err := s.issueRefreshToken(&sess)
+	if err != nil {
+		log.Printf("AuthService: %s", err)
+		return domain.User{}, domain.AuthTokens{}, err
+	}
+	sess.UserAgent, sess.Ip = clientUserAgent(c), c.Ip
+
+	err = s.authRepo.Rotate(sess, oldHash)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			// another request got to exchange the very same token first
+			return domain.User{}, domain.AuthTokens{}, s.closeReused(sess)
+		}
+		log.Printf("AuthService: %s", err)
+		return domain.User{}, domain.AuthTokens{}, err
+	}
+
+	err = s.signAccessToken(sess, &tokens)
+	if err != nil {
+		log.Printf("AuthService: %s", err)
+		return domain.User{}, domain.AuthTokens{}, err
+	}
+
+	return u, tokens, nil
 }
 
 func (s authService) Logout(sess domain.Session) error {
 	return s.authRepo.Delete(sess)
 }
 
-func (s authService) GenerateJwt(user domain.User) (string, error) {
-	sess := domain.Session{UserId: user.Id, UUID: uuid.New()}
-	err := s.authRepo.Save(sess)
+// GenerateTokens starts a new session and issues its first token pair.
+func (s authService) GenerateTokens(user domain.User, c domain.Client) (domain.AuthTokens, error) {
+	sess := domain.Session{
+		UserId:    user.Id,
+		UUID:      uuid.New(),
+		UserAgent: clientUserAgent(c),
+		Ip:        c.Ip,
+	}
+	tokens, err := s.issueRefreshToken(&sess)
+	if err != nil {
+		return domain.AuthTokens{}, err
+	}
+
+	err = s.authRepo.Save(sess)
 	if err != nil {
 		log.Printf("AuthService: failed to save session %s", err)
-		return "", err
+		return domain.AuthTokens{}, err
+	}
+
+	err = s.signAccessToken(sess, &tokens)
+	if err != nil {
+		return domain.AuthTokens{}, err
+	}
+
+	return tokens, nil
+}
+
+func (s authService) Check(sess domain.Session) error {
+	return s.authRepo.Exists(sess)
+}
+
+// ChangePassword stores the new password and signs the user out
+// everywhere except the session the change was made from, both or
+// neither.
+func (s authService) ChangePassword(user domain.User, sess domain.Session, cp domain.ChangePassword) error {
+	if !s.checkPasswordHash(cp.OldPassword, user.Password) {
+		log.Printf("AuthService: %s", ErrWrongPassword)
+		return ErrWrongPassword
+	}
+
+	hash, err := s.generatePasswordHash(cp.NewPassword)
+	if err != nil {
+		log.Printf("AuthService: %s", err)
+		return err
+	}
+
+	err = s.uow.Do(func(tx database.Tx) error {
+		_, err := tx.Users().SetPassword(user.Id, hash)
+		if err != nil {
+			return err
+		}
+		return tx.Sessions().DeleteOthers(sess)
+	})
+	if err != nil {
+		log.Printf("AuthService: %s", err)
+		return err
 	}
 
+	return nil
+}
+
+func (s authService) closeReused(sess domain.Session) error {
+	log.Printf("AuthService: refresh token reuse detected for user %d, closing session %s", sess.UserId, sess.UUID)
+	err := s.authRepo.Delete(sess)
+	if err != nil {
+		log.Printf("AuthService: %s", err)
+		return err
+	}
+	return ErrRefreshTokenReused
+}
+
+func (s authService) signAccessToken(sess domain.Session, tokens *domain.AuthTokens) error {
 	claims := map[string]interface{}{
 		"user_id": sess.UserId,
 		"uuid":    sess.UUID,
 	}
-	jwtauth.SetExpiryIn(claims, s.jwtTTL)
+	tokens.AccessExpiresDate = time.Now().Add(s.jwtTTL)
+	jwtauth.SetExpiry(claims, tokens.AccessExpiresDate)
 	_, tokenString, err := s.tokenAuth.Encode(claims)
 	if err != nil {
-		return "", err
+		return err
 	}
 
-	return tokenString, nil
+	tokens.AccessToken = tokenString
+	return nil
 }
 
-func (s authService) Check(sess domain.Session) error {
-	return s.authRepo.Exists(sess)
+// issueRefreshToken puts a fresh refresh token into tokens and its hash
+// into sess, it is up to the caller to store the session.
+func (s authService) issueRefreshToken(sess *domain.Session) (domain.AuthTokens, error) {
+	buf := make([]byte, refreshNonceLength)
+	_, err := rand.Read(buf)
+	if err != nil {
+		return domain.AuthTokens{}, err
+	}
+
+	// "{session uuid}.{nonce}.{signature}", the session is found by the
+	// uuid and forged tokens are turned down before it is looked up
+	payload := sess.UUID.String() + "." + base64.RawURLEncoding.EncodeToString(buf)
+	token := payload + "." + s.sign(payload)
+	expires := time.Now().Add(s.refreshTTL)
+
+	sess.RefreshHash = s.hashToken(token)
+	sess.RefreshExpiresDate = &expires
+	return domain.AuthTokens{
+		RefreshToken:       token,
+		RefreshExpiresDate: expires,
+	}, nil
+}
+
+func (s authService) verifyRefreshToken(token string) (uuid.UUID, bool) {
+	i := strings.LastIndex(token, ".")
+	if i < 0 {
+		return uuid.UUID{}, false
+	}
+	payload, sig := token[:i], token[i+1:]
+	if !hmac.Equal([]byte(sig), []byte(s.sign(payload))) {
+		return uuid.UUID{}, false
+	}
+
+	sId, _, _ := strings.Cut(payload, ".")
+	id, err := uuid.Parse(sId)
+	if err != nil {
+		return uuid.UUID{}, false
+	}
+	return id, true
+}
+
+func (s authService) sign(payload string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte("refresh." + payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func (s authService) hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// clientUserAgent cuts the user agent down to what the sessions table holds.
+func clientUserAgent(c domain.Client) string {
+	ua := []rune(c.UserAgent)
+	if len(ua) > 255 {
+		ua = ua[:255]
+	}
+	return string(ua)
 }
 
 func (s authService) generatePasswordHash(password string) (string, error) {
@@ -0,0 +1,176 @@
+package app
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/database"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/events"
+)
+
+const defaultCommandTTL = 5 * time.Minute
+
+var (
+	ErrNotActuator   = domain.NewError(domain.ValidationError, "not_actuator", "commands are accepted only by ACTUATOR devices")
+	ErrCommandClosed = domain.NewError(domain.ValidationError, "command_closed", "command is no longer awaiting acknowledgement")
+)
+
+type CommandService interface {
+	Save(dv domain.Device, c domain.Command, uId uint64) (domain.Command, error)
+	FindForDevice(dv domain.Device, status *domain.CommandStatus, p domain.Pagination, uId uint64) (domain.Page[domain.Command], error)
+	Find(id uint64) (interface{}, error)
+	Poll(dv domain.Device) ([]domain.Command, error)
+	Ack(dv domain.Device, c domain.Command, ack domain.Command) (domain.Command, error)
+}
+
+type commandService struct {
+	commandRepo   database.CommandRepository
+	memberService OrganizationMemberService
+	eventBus      events.Bus
+	outbox        WebhookOutbox
+}
+
+func NewCommandService(cr database.CommandRepository, ms OrganizationMemberService, eb events.Bus, wo WebhookOutbox) CommandService {
+	return commandService{
+		commandRepo:   cr,
+		memberService: ms,
+		eventBus:      eb,
+		outbox:        wo,
+	}
+}
+
+func (s commandService) Save(dv domain.Device, c domain.Command, uId uint64) (domain.Command, error) {
+	err := s.memberService.Authorize(dv.OrganizationId, uId, domain.OperatePermission)
+	if err != nil {
+		log.Printf("CommandService: %s", err)
+		return domain.Command{}, err
+	}
+
+	if dv.Category != string(database.ACTUATOR) {
+		err = ErrNotActuator
+		log.Printf("CommandService: %s", err)
+		return domain.Command{}, err
+	}
+
+	c.DeviceId = dv.Id
+	c.UserId = uId
+	c.Status = domain.CommandPending
+	if c.ExpiresAt.IsZero() {
+		c.ExpiresAt = time.Now().Add(defaultCommandTTL)
+	}
+	c, err = s.commandRepo.Save(c)
+	if err != nil {
+		log.Printf("CommandService: %s", err)
+		return domain.Command{}, err
+	}
+
+	e := domain.Event{
+		Type:           domain.CommandCreated,
+		OrganizationId: dv.OrganizationId,
+		RoomId:         dv.RoomId,
+		DeviceGUID:     &dv.GUID,
+		Data:           c,
+	}
+	err = s.outbox.Add(e)
+	if err != nil {
+		// the command is stored already, failing the request would only get it sent twice
+		log.Printf("CommandService: %s", err)
+	}
+	s.eventBus.Publish(e)
+	return c, nil
+}
+
+func (s commandService) FindForDevice(dv domain.Device, status *domain.CommandStatus, p domain.Pagination, uId uint64) (domain.Page[domain.Command], error) {
+	err := s.memberService.Authorize(dv.OrganizationId, uId, domain.ViewPermission)
+	if err != nil {
+		log.Printf("CommandService: %s", err)
+		return domain.Page[domain.Command]{}, err
+	}
+
+	err = s.commandRepo.ExpireOverdue(dv.Id)
+	if err != nil {
+		log.Printf("CommandService: %s", err)
+		return domain.Page[domain.Command]{}, err
+	}
+
+	var statuses []domain.CommandStatus
+	if status != nil {
+		statuses = append(statuses, *status)
+	}
+	cmds, err := s.commandRepo.FindAll(dv.Id, p, statuses...)
+	if err != nil {
+		log.Printf("CommandService: %s", err)
+		return domain.Page[domain.Command]{}, err
+	}
+
+	return cmds, nil
+}
+
+func (s commandService) Find(id uint64) (interface{}, error) {
+	cmd, err := s.commandRepo.FindById(id)
+	if err != nil {
+		log.Printf("CommandService: %s", err)
+		return nil, err
+	}
+
+	return cmd, nil
+}
+
+// Poll hands pending commands over to the device and marks them as delivered.
+func (s commandService) Poll(dv domain.Device) ([]domain.Command, error) {
+	err := s.commandRepo.ExpireOverdue(dv.Id)
+	if err != nil {
+		log.Printf("CommandService: %s", err)
+		return nil, err
+	}
+
+	cmds, err := s.commandRepo.FindForDevice(dv.Id, domain.CommandPending)
+	if err != nil {
+		log.Printf("CommandService: %s", err)
+		return nil, err
+	}
+
+	delivered := make([]domain.Command, 0, len(cmds))
+	for _, c := range cmds {
+		c, err = s.commandRepo.Deliver(c.Id)
+		if errors.Is(err, domain.ErrNotFound) {
+			// a concurrent poll, ack or expiry got to it first
+			continue
+		} else if err != nil {
+			log.Printf("CommandService: %s", err)
+			return nil, err
+		}
+		delivered = append(delivered, c)
+	}
+
+	return delivered, nil
+}
+
+func (s commandService) Ack(dv domain.Device, c domain.Command, ack domain.Command) (domain.Command, error) {
+	var err error
+	if c.DeviceId != dv.Id {
+		err = domain.ErrAccessDenied
+		log.Printf("CommandService: %s", err)
+		return domain.Command{}, err
+	}
+
+	c, err = s.commandRepo.Ack(c.Id, ack.Status, ack.Result)
+	if errors.Is(err, domain.ErrNotFound) {
+		// acknowledged already or overdue, mark it as expired in the latter case
+		err = s.commandRepo.ExpireOverdue(dv.Id)
+		if err != nil {
+			log.Printf("CommandService: %s", err)
+			return domain.Command{}, err
+		}
+		err = ErrCommandClosed
+		log.Printf("CommandService: %s", err)
+		return domain.Command{}, err
+	} else if err != nil {
+		log.Printf("CommandService: %s", err)
+		return domain.Command{}, err
+	}
+
+	return c, nil
+}
@@ -0,0 +1,102 @@
+package app
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
+
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/database"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/events"
+)
+
+const deviceTokenLength = 32
+
+type DeviceAuthService interface {
+	Issue(dv domain.Device, uId uint64) (string, error)
+	Revoke(dv domain.Device) error
+	Check(token string) (domain.Device, error)
+}
+
+type deviceAuthService struct {
+	tokenRepo  database.DeviceTokenRepository
+	deviceRepo database.DeviceRepository
+	uow        database.UnitOfWork
+	eventBus   events.Bus
+}
+
+func NewDeviceAuthService(tr database.DeviceTokenRepository, dr database.DeviceRepository, uow database.UnitOfWork, eb events.Bus) DeviceAuthService {
+	return deviceAuthService{
+		tokenRepo:  tr,
+		deviceRepo: dr,
+		uow:        uow,
+		eventBus:   eb,
+	}
+}
+
+// Issue revokes any token the device already has and generates a new one,
+// both at once, so the device is never left with two tokens or none.
+// The plain token is returned only here, the database keeps its hash.
+func (s deviceAuthService) Issue(dv domain.Device, uId uint64) (string, error) {
+	buf := make([]byte, deviceTokenLength)
+	_, err := rand.Read(buf)
+	if err != nil {
+		log.Printf("DeviceAuthService: %s", err)
+		return "", err
+	}
+	token := hex.EncodeToString(buf)
+
+	err = s.uow.Do(func(tx database.Tx) error {
+		err := tx.DeviceTokens().RevokeForDevice(dv.Id)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.DeviceTokens().Save(domain.DeviceToken{
+			DeviceId:  dv.Id,
+			UserId:    uId,
+			TokenHash: s.hashToken(token),
+		})
+		return err
+	})
+	if err != nil {
+		log.Printf("DeviceAuthService: %s", err)
+		return "", err
+	}
+
+	s.eventBus.Publish(deviceEvent(domain.DeviceTokenRevoked, dv))
+	return token, nil
+}
+
+func (s deviceAuthService) Revoke(dv domain.Device) error {
+	err := s.tokenRepo.RevokeForDevice(dv.Id)
+	if err != nil {
+		log.Printf("DeviceAuthService: %s", err)
+		return err
+	}
+
+	s.eventBus.Publish(deviceEvent(domain.DeviceTokenRevoked, dv))
+	return nil
+}
+
+func (s deviceAuthService) Check(token string) (domain.Device, error) {
+	tkn, err := s.tokenRepo.FindByHash(s.hashToken(token))
+	if err != nil {
+		log.Printf("DeviceAuthService: %s", err)
+		return domain.Device{}, err
+	}
+
+	dv, err := s.deviceRepo.FindById(tkn.DeviceId)
+	if err != nil {
+		log.Printf("DeviceAuthService: %s", err)
+		return domain.Device{}, err
+	}
+
+	return dv, nil
+}
+
+func (s deviceAuthService) hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -1,73 +1,110 @@
 package app
 
 import (
-	"errors"
 	"log"
+	"slices"
+	"time"
 
 	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
 	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/database"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/events"
+	"github.com/google/uuid"
 )
 
+// presenceBatchSize bounds the devices a sweep loads and reports at once
+const presenceBatchSize = 500
+
+var ErrNotInRoom = domain.NewError(domain.ValidationError, "not_in_room", "device has to be set to a room before it can be placed")
+
 type DeviceService interface {
 	Save(dv domain.Device, uId uint64) (domain.Device, error)
-	FindForRoom(mId uint64) ([]domain.Device, error)
+	FindForRoom(mId uint64, uId uint64) ([]domain.Device, error)
+	FindAll(f domain.DeviceFilters, p domain.Pagination, uId uint64) (domain.Page[domain.Device], error)
 	Find(id uint64) (interface{}, error)
+	FindByGUID(guid uuid.UUID) (interface{}, error)
+	InventoryNumberAvailable(number string) (bool, error)
+	CheckAccess(dv domain.Device, uId uint64, p domain.Permission) error
+	Locate(dv domain.Device, uId uint64) (domain.DeviceLocation, error)
 	Update(dv domain.Device) (domain.Device, error)
-	SetDeviceToRoom(deviceId, roomId uint64) error
-	RemoveDeviceFromRoom(deviceId uint64) error
-	Delete(id uint64) error
+	SetDeviceToRoom(dv domain.Device, roomId uint64) error
+	RemoveDeviceFromRoom(dv domain.Device) error
+	SetPlacement(dv domain.Device, p *domain.Placement) (domain.Device, error)
+	FindPlacedForRoom(mId uint64, uId uint64) ([]domain.Device, error)
+	Touch(dv domain.Device) error
+	SweepPresence(staleAfter, offlineAfter time.Duration) error
+	Delete(dv domain.Device) error
 }
 
 type deviceService struct {
-	deviceRepo database.DeviceRepository
-	roomRepo   database.RoomRepository
-	orgRepo    database.OrganizationRepository
+	deviceRepo    database.DeviceRepository
+	roomRepo      database.RoomRepository
+	orgRepo       database.OrganizationRepository
+	memberService OrganizationMemberService
+	uow           database.UnitOfWork
+	eventBus      events.Bus
+	outbox        WebhookOutbox
 }
 
-func NewDeviceService(de database.DeviceRepository, ro database.RoomRepository, or database.OrganizationRepository) DeviceService {
+func NewDeviceService(de database.DeviceRepository, ro database.RoomRepository, or database.OrganizationRepository, ms OrganizationMemberService, uow database.UnitOfWork, eb events.Bus, wo WebhookOutbox) DeviceService {
 	return &deviceService{
-		deviceRepo: de,
-		roomRepo:   ro,
-		orgRepo:    or,
+		deviceRepo:    de,
+		roomRepo:      ro,
+		orgRepo:       or,
+		memberService: ms,
+		uow:           uow,
+		eventBus:      eb,
+		outbox:        wo,
 	}
 }
 
 func (s deviceService) Save(dv domain.Device, uId uint64) (domain.Device, error) {
-	var (
-		rom domain.Room
-		org domain.Organization
-		err error
-	)
-	if dv.RoomId != nil {
-		rom, err = s.roomRepo.FindById(*dv.RoomId)
-		if err != nil {
-			log.Printf("DeviceService: %s", err)
-			return domain.Device{}, err
-		}
-	}
-
-	org, err = s.orgRepo.FindById(rom.OrganizationId)
+	err := s.CheckAccess(dv, uId, domain.ManagePermission)
 	if err != nil {
 		log.Printf("DeviceService: %s", err)
 		return domain.Device{}, err
 	}
 
-	if org.UserId != uId {
-		err = errors.New("access denied")
-		log.Panicf("DeviceService: %s", err)
-		return domain.Device{}, err
-	}
+	dv.Status = domain.DeviceOffline
+	err = s.uow.Do(func(tx database.Tx) error {
+		if dv.RoomId != nil {
+			rom, err := tx.Rooms().FindById(*dv.RoomId)
+			if err != nil {
+				return err
+			}
+			if rom.OrganizationId != dv.OrganizationId {
+				return domain.ErrAccessDenied
+			}
+		}
 
-	dv, err = s.deviceRepo.Save(dv)
+		var err error
+		dv, err = tx.Devices().Save(dv)
+		if err != nil {
+			return err
+		}
+		return s.outbox.AddWithin(tx, deviceEvent(domain.DeviceCreated, dv))
+	})
 	if err != nil {
 		log.Printf("DeviceService: %s", err)
 		return domain.Device{}, err
 	}
 
+	s.eventBus.Publish(deviceEvent(domain.DeviceCreated, dv))
 	return dv, nil
 }
 
-func (s deviceService) FindForRoom(mId uint64) ([]domain.Device, error) {
+func (s deviceService) FindForRoom(mId uint64, uId uint64) ([]domain.Device, error) {
+	rom, err := s.roomRepo.FindById(mId)
+	if err != nil {
+		log.Printf("DeviceService: %s", err)
+		return nil, err
+	}
+
+	err = s.CheckAccess(domain.Device{OrganizationId: rom.OrganizationId}, uId, domain.ViewPermission)
+	if err != nil {
+		log.Printf("DeviceService: %s", err)
+		return nil, err
+	}
+
 	devices, err := s.deviceRepo.FindForRoom(mId)
 	if err != nil {
 		log.Printf("DeviceService: %s", err)
@@ -77,6 +114,32 @@ func (s deviceService) FindForRoom(mId uint64) ([]domain.Device, error) {
 	return devices, nil
 }
 
+// FindAll takes the organization from the room when only the room is given.
+func (s deviceService) FindAll(f domain.DeviceFilters, p domain.Pagination, uId uint64) (domain.Page[domain.Device], error) {
+	if f.RoomId != nil && f.OrganizationId == 0 {
+		rom, err := s.roomRepo.FindById(*f.RoomId)
+		if err != nil {
+			log.Printf("DeviceService: %s", err)
+			return domain.Page[domain.Device]{}, err
+		}
+		f.OrganizationId = rom.OrganizationId
+	}
+
+	err := s.CheckAccess(domain.Device{OrganizationId: f.OrganizationId}, uId, domain.ViewPermission)
+	if err != nil {
+		log.Printf("DeviceService: %s", err)
+		return domain.Page[domain.Device]{}, err
+	}
+
+	devices, err := s.deviceRepo.FindAll(f, p)
+	if err != nil {
+		log.Printf("DeviceService: %s", err)
+		return domain.Page[domain.Device]{}, err
+	}
+
+	return devices, nil
+}
+
 func (s deviceService) Find(id uint64) (interface{}, error) {
 	device, err := s.deviceRepo.FindById(id)
 	if err != nil {
@@ -87,28 +150,182 @@ func (s deviceService) Find(id uint64) (interface{}, error) {
 	return device, nil
 }
 
+func (s deviceService) InventoryNumberAvailable(number string) (bool, error) {
+	taken, err := s.deviceRepo.InventoryNumberTaken(number)
+	if err != nil {
+		log.Printf("DeviceService: %s", err)
+		return false, err
+	}
+
+	return !taken, nil
+}
+
+func (s deviceService) FindByGUID(guid uuid.UUID) (interface{}, error) {
+	device, err := s.deviceRepo.FindByGUID(guid)
+	if err != nil {
+		log.Printf("DeviceService: %s", err)
+		return nil, err
+	}
+
+	return device, nil
+}
+
+func (s deviceService) CheckAccess(dv domain.Device, uId uint64, p domain.Permission) error {
+	return s.memberService.Authorize(dv.OrganizationId, uId, p)
+}
+
 func (s deviceService) Update(dv domain.Device) (domain.Device, error) {
-	device, err := s.deviceRepo.Update(dv)
+	var device domain.Device
+	err := s.uow.Do(func(tx database.Tx) error {
+		var err error
+		device, err = tx.Devices().Update(dv)
+		if err != nil {
+			return err
+		}
+		return s.outbox.AddWithin(tx, deviceEvent(domain.DeviceUpdated, device))
+	})
 	if err != nil {
 		log.Printf("DeviceService: %s", err)
 		return domain.Device{}, err
 	}
 
+	s.eventBus.Publish(deviceEvent(domain.DeviceUpdated, device))
 	return device, nil
 }
 
-func (s deviceService) SetDeviceToRoom(deviceId, roomId uint64) error {
-	err := s.deviceRepo.SetDeviceToRoom(deviceId, roomId)
+func (s deviceService) SetDeviceToRoom(dv domain.Device, roomId uint64) error {
+	e := moveEvent(dv, &roomId)
+	err := s.uow.Do(func(tx database.Tx) error {
+		rom, err := tx.Rooms().FindById(roomId)
+		if err != nil {
+			return err
+		}
+		if rom.OrganizationId != dv.OrganizationId {
+			return domain.ErrAccessDenied
+		}
+
+		err = tx.Devices().SetDeviceToRoom(dv.Id, roomId)
+		if err != nil {
+			return err
+		}
+		return s.outbox.AddWithin(tx, e)
+	})
+	if err != nil {
+		log.Printf("DeviceService: %s", err)
+		return err
+	}
+
+	s.eventBus.Publish(e)
+	return nil
+}
+
+func (s deviceService) RemoveDeviceFromRoom(dv domain.Device) error {
+	e := moveEvent(dv, nil)
+	err := s.uow.Do(func(tx database.Tx) error {
+		err := tx.Devices().RemoveDeviceFromRoom(dv.Id)
+		if err != nil {
+			return err
+		}
+		return s.outbox.AddWithin(tx, e)
+	})
+	if err != nil {
+		log.Printf("DeviceService: %s", err)
+		return err
+	}
+
+	s.eventBus.Publish(e)
+	return nil
+}
+
+func (s deviceService) Locate(dv domain.Device, uId uint64) (domain.DeviceLocation, error) {
+	err := s.CheckAccess(dv, uId, domain.ViewPermission)
+	if err != nil {
+		log.Printf("DeviceService: %s", err)
+		return domain.DeviceLocation{}, err
+	}
+
+	org, err := s.orgRepo.FindById(dv.OrganizationId)
+	if err != nil {
+		log.Printf("DeviceService: %s", err)
+		return domain.DeviceLocation{}, err
+	}
+
+	loc := domain.DeviceLocation{Device: dv, Organization: org}
+	if dv.RoomId != nil {
+		rom, err := s.roomRepo.FindById(*dv.RoomId)
+		if err != nil {
+			log.Printf("DeviceService: %s", err)
+			return domain.DeviceLocation{}, err
+		}
+		loc.Room = &rom
+	}
+
+	return loc, nil
+}
+
+// SetPlacement positions the device in its room, nil clears the placement.
+func (s deviceService) SetPlacement(dv domain.Device, p *domain.Placement) (domain.Device, error) {
+	if p != nil && dv.RoomId == nil {
+		err := ErrNotInRoom
+		log.Printf("DeviceService: %s", err)
+		return domain.Device{}, err
+	}
+
+	dv.Placement = p
+	return s.Update(dv)
+}
+
+func (s deviceService) FindPlacedForRoom(mId uint64, uId uint64) ([]domain.Device, error) {
+	rom, err := s.roomRepo.FindById(mId)
+	if err != nil {
+		log.Printf("DeviceService: %s", err)
+		return nil, err
+	}
+
+	err = s.CheckAccess(domain.Device{OrganizationId: rom.OrganizationId}, uId, domain.ViewPermission)
+	if err != nil {
+		log.Printf("DeviceService: %s", err)
+		return nil, err
+	}
+
+	devices, err := s.deviceRepo.FindPlacedForRoom(mId)
+	if err != nil {
+		log.Printf("DeviceService: %s", err)
+		return nil, err
+	}
+
+	return devices, nil
+}
+
+// Touch records that the device has just been heard from.
+func (s deviceService) Touch(dv domain.Device) error {
+	now := time.Now()
+	err := s.deviceRepo.Touch(dv.Id, now)
 	if err != nil {
 		log.Printf("DeviceService: %s", err)
 		return err
 	}
 
+	if dv.Status != domain.DeviceOnline {
+		dv.Status = domain.DeviceOnline
+		dv.LastSeenDate = &now
+		s.eventBus.Publish(deviceEvent(domain.DevicePresence, dv))
+	}
 	return nil
 }
 
-func (s deviceService) RemoveDeviceFromRoom(deviceId uint64) error {
-	err := s.deviceRepo.RemoveDeviceFromRoom(deviceId)
+// SweepPresence downgrades devices that went silent: online ones become
+// stale after staleAfter, and any of them offline after offlineAfter.
+func (s deviceService) SweepPresence(staleAfter, offlineAfter time.Duration) error {
+	now := time.Now()
+
+	err := s.markSilent(now.Add(-offlineAfter), domain.DeviceOffline, domain.DeviceOnline, domain.DeviceStale)
+	if err != nil {
+		log.Printf("DeviceService: %s", err)
+		return err
+	}
+
+	err = s.markSilent(now.Add(-staleAfter), domain.DeviceStale, domain.DeviceOnline)
 	if err != nil {
 		log.Printf("DeviceService: %s", err)
 		return err
@@ -117,12 +334,77 @@ func (s deviceService) RemoveDeviceFromRoom(deviceId uint64) error {
 	return nil
 }
 
-func (s deviceService) Delete(id uint64) error {
-	err := s.deviceRepo.Delete(id)
+// markSilent works in batches of presenceBatchSize, the devices of a batch
+// leave the from statuses, so the next lookup returns the following ones.
+func (s deviceService) markSilent(before time.Time, to domain.DeviceStatus, from ...domain.DeviceStatus) error {
+	for {
+		devs, err := s.deviceRepo.FindSilent(before, presenceBatchSize, from...)
+		if err != nil {
+			return err
+		}
+
+		ids := make([]uint64, 0, len(devs))
+		for _, dv := range devs {
+			ids = append(ids, dv.Id)
+		}
+		changed, err := s.deviceRepo.SetStatus(ids, before, to, from...)
+		if err != nil {
+			return err
+		}
+
+		// devices heard from in the meantime are left out, they are not silent
+		for _, dv := range devs {
+			if !slices.Contains(changed, dv.Id) {
+				continue
+			}
+			dv.Status = to
+			s.eventBus.Publish(deviceEvent(domain.DevicePresence, dv))
+		}
+
+		if len(devs) < presenceBatchSize {
+			return nil
+		}
+	}
+}
+
+func (s deviceService) Delete(dv domain.Device) error {
+	err := s.uow.Do(func(tx database.Tx) error {
+		err := tx.Devices().Delete(dv.Id)
+		if err != nil {
+			return err
+		}
+		return s.outbox.AddWithin(tx, deviceEvent(domain.DeviceDeleted, dv))
+	})
 	if err != nil {
 		log.Printf("DeviceService: %s", err)
 		return err
 	}
 
+	s.eventBus.Publish(deviceEvent(domain.DeviceDeleted, dv))
 	return nil
 }
+
+func deviceEvent(t domain.EventType, dv domain.Device) domain.Event {
+	return domain.Event{
+		Type:           t,
+		OrganizationId: dv.OrganizationId,
+		RoomId:         dv.RoomId,
+		DeviceGUID:     &dv.GUID,
+		Data:           dv,
+	}
+}
+
+// moveEvent also names the room the device has left, so the
+// subscribers of that room learn that it is gone.
+func moveEvent(dv domain.Device, roomId *uint64) domain.Event {
+	prevRoomId := dv.RoomId
+	if prevRoomId != nil && roomId != nil && *prevRoomId == *roomId {
+		prevRoomId = nil
+	}
+	dv.RoomId = roomId
+	dv.Placement = nil
+
+	e := deviceEvent(domain.DeviceUpdated, dv)
+	e.PrevRoomId = prevRoomId
+	return e
+}
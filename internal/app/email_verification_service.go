@@ -0,0 +1,175 @@
+package app
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/database"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/mail"
+)
+
+const (
+	// resendLimit verification mails can be asked for per user within resendWindow
+	resendLimit  = 3
+	resendWindow = time.Hour
+)
+
+var (
+	ErrEmailNotVerified        = domain.NewError(domain.ForbiddenError, "email_not_verified", "email address has to be verified first")
+	ErrAlreadyVerified         = domain.NewError(domain.ConflictError, "already_verified", "email address is already verified")
+	ErrInvalidVerificationLink = domain.NewError(domain.ValidationError, "invalid_verification_link", "verification link is invalid or has expired")
+	ErrTooManyVerifications    = domain.NewError(domain.TooManyRequestsError, "too_many_verifications", "too many verification emails requested, try again later")
+)
+
+type EmailVerificationService interface {
+	Send(u domain.User) error
+	Resend(u domain.User) error
+	Verify(token string) (domain.User, error)
+	MarkVerified(u domain.User) (domain.User, error)
+}
+
+type emailVerificationService struct {
+	userRepo database.UserRepository
+	mailer   mail.Sender
+	limiter  *rateLimiter
+	secret   []byte
+	ttl      time.Duration
+	appUrl   string
+}
+
+func NewEmailVerificationService(
+	ur database.UserRepository,
+	mailer mail.Sender,
+	secret string,
+	ttl time.Duration,
+	appUrl string) EmailVerificationService {
+	return emailVerificationService{
+		userRepo: ur,
+		mailer:   mailer,
+		limiter:  newRateLimiter(resendLimit, resendWindow),
+		secret:   []byte(secret),
+		ttl:      ttl,
+		appUrl:   strings.TrimRight(appUrl, "/"),
+	}
+}
+
+// Send mails a verification link for the current email of the user. The
+// link is signed rather than stored, it stops working once the email changes.
+func (s emailVerificationService) Send(u domain.User) error {
+	expires := time.Now().Add(s.ttl)
+	token := s.generateToken(u, expires)
+
+	link := fmt.Sprintf("%s/verify-email?token=%s", s.appUrl, url.QueryEscape(token))
+	err := s.mailer.Send(mail.Message{
+		To:      u.Email,
+		Subject: "Confirm your email address",
+		Body: fmt.Sprintf(
+			"Please confirm that this email address belongs to you.\r\n\r\n"+
+				"Confirm: %s\r\n\r\n"+
+				"The link expires on %s. "+
+				"If you did not create an account, just ignore this message.\r\n",
+			link, expires.UTC().Format("2006-01-02 15:04 MST")),
+	})
+	if err != nil {
+		log.Printf("EmailVerificationService: %s", err)
+		return err
+	}
+
+	return nil
+}
+
+func (s emailVerificationService) Resend(u domain.User) error {
+	if u.Verified() {
+		log.Printf("EmailVerificationService: %s", ErrAlreadyVerified)
+		return ErrAlreadyVerified
+	}
+
+	if !s.limiter.Allow(strconv.FormatUint(u.Id, 10)) {
+		log.Printf("EmailVerificationService: %s", ErrTooManyVerifications)
+		return ErrTooManyVerifications
+	}
+
+	return s.Send(u)
+}
+
+// Verify confirms the email the token was issued for, following the
+// same link again is not an error.
+func (s emailVerificationService) Verify(token string) (domain.User, error) {
+	uId, expires, ok := s.parseToken(token)
+	if !ok || time.Now().After(expires) {
+		log.Printf("EmailVerificationService: %s", ErrInvalidVerificationLink)
+		return domain.User{}, ErrInvalidVerificationLink
+	}
+
+	u, err := s.userRepo.FindById(uId)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			err = ErrInvalidVerificationLink
+		}
+		log.Printf("EmailVerificationService: %s", err)
+		return domain.User{}, err
+	}
+
+	if u.DeletedDate != nil || !hmac.Equal([]byte(token), []byte(s.generateToken(u, expires))) {
+		log.Printf("EmailVerificationService: %s", ErrInvalidVerificationLink)
+		return domain.User{}, ErrInvalidVerificationLink
+	}
+
+	return s.MarkVerified(u)
+}
+
+// MarkVerified is meant for cases where the address was proven some other
+// way, e.g. by registering with an invitation mailed to it.
+func (s emailVerificationService) MarkVerified(u domain.User) (domain.User, error) {
+	if u.Verified() {
+		return u, nil
+	}
+
+	u, err := s.userRepo.SetVerified(u.Id, u.Email)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			// the email has been changed since
+			err = ErrInvalidVerificationLink
+		}
+		log.Printf("EmailVerificationService: %s", err)
+		return domain.User{}, err
+	}
+
+	return u, nil
+}
+
+// generateToken returns "{userId}.{expires}.{signature}", the email is
+// signed along, but is not a part of the token itself.
+func (s emailVerificationService) generateToken(u domain.User, expires time.Time) string {
+	payload := fmt.Sprintf("%d.%d", u.Id, expires.Unix())
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte("email-verification." + payload + "." + strings.ToLower(u.Email)))
+	return payload + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func (s emailVerificationService) parseToken(token string) (uint64, time.Time, bool) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return 0, time.Time{}, false
+	}
+
+	uId, err := strconv.ParseUint(parts[0], 10, 64)
+	if err != nil {
+		return 0, time.Time{}, false
+	}
+	exp, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return 0, time.Time{}, false
+	}
+
+	return uId, time.Unix(exp, 0), true
+}
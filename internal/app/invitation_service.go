@@ -0,0 +1,299 @@
+package app
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/database"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/mail"
+)
+
+const invitationNonceLength = 24
+
+var (
+	ErrInvalidInvitation = domain.NewError(domain.ValidationError, "invalid_invitation", "invitation is invalid or has expired")
+	ErrInvitationEmail   = domain.NewError(domain.ForbiddenError, "invitation_email_mismatch", "invitation was sent to a different email")
+)
+
+type InvitationService interface {
+	Save(i domain.Invitation, uId uint64) (domain.Invitation, error)
+	FindPendingForOrganization(oId uint64, p domain.Pagination, uId uint64) (domain.Page[domain.Invitation], error)
+	Find(id uint64) (interface{}, error)
+	Revoke(i domain.Invitation, uId uint64) error
+	Check(token, email string) (domain.Invitation, error)
+	Accept(token string, u domain.User) (domain.OrganizationMember, error)
+	AcceptWithin(tx database.Tx, token string, u domain.User) (domain.OrganizationMember, error)
+}
+
+type invitationService struct {
+	invitationRepo database.InvitationRepository
+	orgRepo        database.OrganizationRepository
+	memberService  OrganizationMemberService
+	uow            database.UnitOfWork
+	mailer         mail.Sender
+	secret         []byte
+	ttl            time.Duration
+	appUrl         string
+}
+
+func NewInvitationService(
+	ir database.InvitationRepository,
+	or database.OrganizationRepository,
+	ms OrganizationMemberService,
+	uow database.UnitOfWork,
+	mailer mail.Sender,
+	secret string,
+	ttl time.Duration,
+	appUrl string) InvitationService {
+	return invitationService{
+		invitationRepo: ir,
+		orgRepo:        or,
+		memberService:  ms,
+		uow:            uow,
+		mailer:         mailer,
+		secret:         []byte(secret),
+		ttl:            ttl,
+		appUrl:         strings.TrimRight(appUrl, "/"),
+	}
+}
+
+// Save stores a new invitation in place of any pending one for the same
+// email and mails the token, which is not kept anywhere but in the message.
+func (s invitationService) Save(i domain.Invitation, uId uint64) (domain.Invitation, error) {
+	err := s.memberService.AuthorizeRole(i.OrganizationId, uId, i.Role)
+	if err != nil {
+		log.Printf("InvitationService: %s", err)
+		return domain.Invitation{}, err
+	}
+
+	org, err := s.orgRepo.FindById(i.OrganizationId)
+	if err != nil {
+		log.Printf("InvitationService: %s", err)
+		return domain.Invitation{}, err
+	}
+
+	i.Email = strings.ToLower(strings.TrimSpace(i.Email))
+	token, err := s.generateToken()
+	if err != nil {
+		log.Printf("InvitationService: %s", err)
+		return domain.Invitation{}, err
+	}
+
+	err = s.invitationRepo.RevokePending(i.OrganizationId, i.Email)
+	if err != nil {
+		log.Printf("InvitationService: %s", err)
+		return domain.Invitation{}, err
+	}
+
+	i.InvitedBy = uId
+	i.TokenHash = s.hashToken(token)
+	i.ExpiresDate = time.Now().Add(s.ttl)
+	i, err = s.invitationRepo.Save(i)
+	if err != nil {
+		log.Printf("InvitationService: %s", err)
+		return domain.Invitation{}, err
+	}
+
+	err = s.mailer.Send(s.message(i, org, token))
+	if err != nil {
+		log.Printf("InvitationService: %s", err)
+		// nobody can accept an invitation that never arrived
+		_ = s.Revoke(i, uId)
+		return domain.Invitation{}, err
+	}
+
+	return i, nil
+}
+
+func (s invitationService) FindPendingForOrganization(oId uint64, p domain.Pagination, uId uint64) (domain.Page[domain.Invitation], error) {
+	err := s.memberService.Authorize(oId, uId, domain.ManagePermission)
+	if err != nil {
+		log.Printf("InvitationService: %s", err)
+		return domain.Page[domain.Invitation]{}, err
+	}
+
+	invs, err := s.invitationRepo.FindPendingForOrganization(oId, p)
+	if err != nil {
+		log.Printf("InvitationService: %s", err)
+		return domain.Page[domain.Invitation]{}, err
+	}
+
+	return invs, nil
+}
+
+func (s invitationService) Find(id uint64) (interface{}, error) {
+	i, err := s.invitationRepo.FindById(id)
+	if err != nil {
+		log.Printf("InvitationService: %s", err)
+		return nil, err
+	}
+
+	return i, nil
+}
+
+func (s invitationService) Revoke(i domain.Invitation, uId uint64) error {
+	err := s.memberService.AuthorizeRole(i.OrganizationId, uId, i.Role)
+	if err != nil {
+		log.Printf("InvitationService: %s", err)
+		return err
+	}
+
+	if i.RevokedDate != nil {
+		return nil
+	}
+
+	now := time.Now()
+	i.RevokedDate = &now
+	_, err = s.invitationRepo.Update(i)
+	if err != nil {
+		log.Printf("InvitationService: %s", err)
+		return err
+	}
+
+	return nil
+}
+
+// Check makes sure the token is genuine, still pending and addressed to
+// email, so it can be verified before a new account is created for it.
+func (s invitationService) Check(token, email string) (domain.Invitation, error) {
+	return s.check(s.invitationRepo, token, email)
+}
+
+func (s invitationService) check(ir database.InvitationRepository, token, email string) (domain.Invitation, error) {
+	if !s.verifyToken(token) {
+		log.Printf("InvitationService: %s", ErrInvalidInvitation)
+		return domain.Invitation{}, ErrInvalidInvitation
+	}
+
+	i, err := ir.FindByHash(s.hashToken(token))
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			err = ErrInvalidInvitation
+		}
+		log.Printf("InvitationService: %s", err)
+		return domain.Invitation{}, err
+	}
+
+	if !i.Pending(time.Now()) {
+		log.Printf("InvitationService: %s", ErrInvalidInvitation)
+		return domain.Invitation{}, ErrInvalidInvitation
+	}
+
+	if !strings.EqualFold(i.Email, strings.TrimSpace(email)) {
+		log.Printf("InvitationService: %s", ErrInvitationEmail)
+		return domain.Invitation{}, ErrInvitationEmail
+	}
+
+	return i, nil
+}
+
+func (s invitationService) Accept(token string, u domain.User) (domain.OrganizationMember, error) {
+	var m domain.OrganizationMember
+	err := s.uow.Do(func(tx database.Tx) error {
+		var err error
+		m, err = s.AcceptWithin(tx, token, u)
+		return err
+	})
+	if err != nil {
+		return domain.OrganizationMember{}, err
+	}
+
+	return m, nil
+}
+
+// AcceptWithin lets the invitation be accepted as a part of a bigger change,
+// such as the registration of the invited user.
+func (s invitationService) AcceptWithin(tx database.Tx, token string, u domain.User) (domain.OrganizationMember, error) {
+	i, err := s.check(tx.Invitations(), token, u.Email)
+	if err != nil {
+		return domain.OrganizationMember{}, err
+	}
+
+	_, err = tx.OrganizationMembers().FindMember(i.OrganizationId, u.Id)
+	if err == nil {
+		err = ErrAlreadyMember
+		log.Printf("InvitationService: %s", err)
+		return domain.OrganizationMember{}, err
+	} else if !errors.Is(err, domain.ErrNotFound) {
+		log.Printf("InvitationService: %s", err)
+		return domain.OrganizationMember{}, err
+	}
+
+	now := time.Now()
+	i.AcceptedDate, i.AcceptedBy = &now, &u.Id
+	_, err = tx.Invitations().Update(i)
+	if err != nil {
+		log.Printf("InvitationService: %s", err)
+		return domain.OrganizationMember{}, err
+	}
+
+	m, err := tx.OrganizationMembers().Save(domain.OrganizationMember{
+		OrganizationId: i.OrganizationId,
+		UserId:         u.Id,
+		Role:           i.Role,
+	})
+	if err != nil {
+		log.Printf("InvitationService: %s", err)
+		return domain.OrganizationMember{}, err
+	}
+
+	m.User = &u
+	return m, nil
+}
+
+func (s invitationService) message(i domain.Invitation, org domain.Organization, token string) mail.Message {
+	link := fmt.Sprintf("%s/invitations/accept?token=%s", s.appUrl, url.QueryEscape(token))
+	body := fmt.Sprintf(
+		"You have been invited to join %s as %s.\r\n\r\n"+
+			"Accept the invitation: %s\r\n\r\n"+
+			"If you do not have an account yet, register with this email address "+
+			"and the invitation will be accepted along with it.\r\n"+
+			"The invitation expires on %s.\r\n",
+		org.Name, i.Role, link, i.ExpiresDate.UTC().Format("2006-01-02 15:04 MST"))
+	return mail.Message{
+		To:      i.Email,
+		Subject: fmt.Sprintf("Invitation to %s", org.Name),
+		Body:    body,
+	}
+}
+
+// generateToken returns "{nonce}.{signature}", the signature lets forged
+// tokens be turned down before the database is asked about them.
+func (s invitationService) generateToken() (string, error) {
+	buf := make([]byte, invitationNonceLength)
+	_, err := rand.Read(buf)
+	if err != nil {
+		return "", err
+	}
+	nonce := base64.RawURLEncoding.EncodeToString(buf)
+	return nonce + "." + s.sign(nonce), nil
+}
+
+func (s invitationService) verifyToken(token string) bool {
+	nonce, sig, ok := strings.Cut(token, ".")
+	if !ok {
+		return false
+	}
+	return hmac.Equal([]byte(sig), []byte(s.sign(nonce)))
+}
+
+func (s invitationService) sign(nonce string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(nonce))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func (s invitationService) hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,87 @@
+package app
+
+import (
+	"log"
+	"time"
+
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/database"
+)
+
+var ErrSensorOnly = domain.NewError(domain.ValidationError, "sensor_only", "measurements are accepted only from SENSOR devices")
+
+type MeasurementService interface {
+	Save(dv domain.Device, ms []domain.Measurement, uId uint64) ([]domain.Measurement, error)
+	Record(dv domain.Device, ms []domain.Measurement) ([]domain.Measurement, error)
+	FindForDevice(dv domain.Device, from, to time.Time, p domain.Pagination, uId uint64) (domain.Page[domain.Measurement], error)
+}
+
+type measurementService struct {
+	measurementRepo database.MeasurementRepository
+	memberService   OrganizationMemberService
+	alertService    AlertService
+}
+
+func NewMeasurementService(mr database.MeasurementRepository, mbs OrganizationMemberService, as AlertService) MeasurementService {
+	return measurementService{
+		measurementRepo: mr,
+		memberService:   mbs,
+		alertService:    as,
+	}
+}
+
+func (s measurementService) Save(dv domain.Device, ms []domain.Measurement, uId uint64) ([]domain.Measurement, error) {
+	err := s.memberService.Authorize(dv.OrganizationId, uId, domain.OperatePermission)
+	if err != nil {
+		log.Printf("MeasurementService: %s", err)
+		return nil, err
+	}
+
+	return s.Record(dv, ms)
+}
+
+// Record stores readings submitted by the device itself, no user is involved.
+func (s measurementService) Record(dv domain.Device, ms []domain.Measurement) ([]domain.Measurement, error) {
+	var err error
+	if dv.Category != string(database.SENSOR) {
+		err = ErrSensorOnly
+		log.Printf("MeasurementService: %s", err)
+		return nil, err
+	}
+
+	saved := make([]domain.Measurement, 0, len(ms))
+	for _, m := range ms {
+		m.DeviceId = dv.Id
+		if m.MeasuredAt.IsZero() {
+			m.MeasuredAt = time.Now()
+		}
+		m, err = s.measurementRepo.Save(m)
+		if err != nil {
+			log.Printf("MeasurementService: %s", err)
+			return nil, err
+		}
+		saved = append(saved, m)
+	}
+
+	// the readings are stored already, a failed evaluation
+	// is logged by the alert service and must not reject them
+	_ = s.alertService.Evaluate(dv, saved)
+
+	return saved, nil
+}
+
+func (s measurementService) FindForDevice(dv domain.Device, from, to time.Time, p domain.Pagination, uId uint64) (domain.Page[domain.Measurement], error) {
+	err := s.memberService.Authorize(dv.OrganizationId, uId, domain.ViewPermission)
+	if err != nil {
+		log.Printf("MeasurementService: %s", err)
+		return domain.Page[domain.Measurement]{}, err
+	}
+
+	ms, err := s.measurementRepo.FindForDevice(dv.Id, from, to, p)
+	if err != nil {
+		log.Printf("MeasurementService: %s", err)
+		return domain.Page[domain.Measurement]{}, err
+	}
+
+	return ms, nil
+}
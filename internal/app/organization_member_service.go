@@ -0,0 +1,220 @@
+package app
+
+import (
+	"errors"
+	"log"
+
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/database"
+)
+
+var (
+	ErrUnknownMember = domain.NewError(domain.NotFoundError, "unknown_member", "no user is registered with this email")
+	ErrAlreadyMember = domain.NewError(domain.ConflictError, "already_member", "user is already a member of the organization")
+	ErrLastOwner     = domain.NewError(domain.ValidationError, "last_owner", "organization has to keep at least one owner")
+)
+
+// OrganizationMemberService manages who belongs to an organization and
+// is the single place that decides what a user may do within one.
+type OrganizationMemberService interface {
+	Save(m domain.OrganizationMember, uId uint64) (domain.OrganizationMember, error)
+	FindForOrganization(f domain.MemberFilters, p domain.Pagination, uId uint64) (domain.Page[domain.OrganizationMember], error)
+	Find(id uint64) (interface{}, error)
+	ChangeRole(m domain.OrganizationMember, role domain.OrganizationRole, uId uint64) (domain.OrganizationMember, error)
+	Delete(m domain.OrganizationMember, uId uint64) error
+	Authorize(oId, uId uint64, p domain.Permission) error
+	AuthorizeRole(oId, uId uint64, role domain.OrganizationRole) error
+}
+
+type organizationMemberService struct {
+	memberRepo database.OrganizationMemberRepository
+	userRepo   database.UserRepository
+	uow        database.UnitOfWork
+}
+
+func NewOrganizationMemberService(mr database.OrganizationMemberRepository, ur database.UserRepository, uow database.UnitOfWork) OrganizationMemberService {
+	return organizationMemberService{
+		memberRepo: mr,
+		userRepo:   ur,
+		uow:        uow,
+	}
+}
+
+// Save adds an already registered user, found by m.User.Email, to the organization.
+func (s organizationMemberService) Save(m domain.OrganizationMember, uId uint64) (domain.OrganizationMember, error) {
+	err := s.AuthorizeRole(m.OrganizationId, uId, m.Role)
+	if err != nil {
+		log.Printf("OrganizationMemberService: %s", err)
+		return domain.OrganizationMember{}, err
+	}
+
+	u, err := s.userRepo.FindByEmail(m.User.Email)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			err = ErrUnknownMember
+		}
+		log.Printf("OrganizationMemberService: %s", err)
+		return domain.OrganizationMember{}, err
+	}
+
+	_, err = s.memberRepo.FindMember(m.OrganizationId, u.Id)
+	if err == nil {
+		err = ErrAlreadyMember
+		log.Printf("OrganizationMemberService: %s", err)
+		return domain.OrganizationMember{}, err
+	} else if !errors.Is(err, domain.ErrNotFound) {
+		log.Printf("OrganizationMemberService: %s", err)
+		return domain.OrganizationMember{}, err
+	}
+
+	m.UserId = u.Id
+	m, err = s.memberRepo.Save(m)
+	if err != nil {
+		log.Printf("OrganizationMemberService: %s", err)
+		return domain.OrganizationMember{}, err
+	}
+
+	m.User = &u
+	return m, nil
+}
+
+func (s organizationMemberService) FindForOrganization(f domain.MemberFilters, p domain.Pagination, uId uint64) (domain.Page[domain.OrganizationMember], error) {
+	err := s.Authorize(f.OrganizationId, uId, domain.ViewPermission)
+	if err != nil {
+		log.Printf("OrganizationMemberService: %s", err)
+		return domain.Page[domain.OrganizationMember]{}, err
+	}
+
+	mems, err := s.memberRepo.FindAll(f, p)
+	if err != nil {
+		log.Printf("OrganizationMemberService: %s", err)
+		return domain.Page[domain.OrganizationMember]{}, err
+	}
+
+	return mems, nil
+}
+
+func (s organizationMemberService) Find(id uint64) (interface{}, error) {
+	m, err := s.memberRepo.FindById(id)
+	if err != nil {
+		log.Printf("OrganizationMemberService: %s", err)
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// ChangeRole needs the right to manage both the current and the new role.
+func (s organizationMemberService) ChangeRole(m domain.OrganizationMember, role domain.OrganizationRole, uId uint64) (domain.OrganizationMember, error) {
+	err := s.AuthorizeRole(m.OrganizationId, uId, m.Role)
+	if err == nil {
+		err = s.AuthorizeRole(m.OrganizationId, uId, role)
+	}
+	if err != nil {
+		log.Printf("OrganizationMemberService: %s", err)
+		return domain.OrganizationMember{}, err
+	}
+
+	err = s.uow.Do(func(tx database.Tx) error {
+		err := checkLastOwner(tx.OrganizationMembers(), m, role)
+		if err != nil {
+			return err
+		}
+
+		m.Role = role
+		m, err = tx.OrganizationMembers().Update(m)
+		return err
+	})
+	if err != nil {
+		log.Printf("OrganizationMemberService: %s", err)
+		return domain.OrganizationMember{}, err
+	}
+
+	return m, nil
+}
+
+// Delete removes a member, members may also leave on their own.
+func (s organizationMemberService) Delete(m domain.OrganizationMember, uId uint64) error {
+	var err error
+	if m.UserId != uId {
+		err = s.AuthorizeRole(m.OrganizationId, uId, m.Role)
+	}
+	if err != nil {
+		log.Printf("OrganizationMemberService: %s", err)
+		return err
+	}
+
+	err = s.uow.Do(func(tx database.Tx) error {
+		err := checkLastOwner(tx.OrganizationMembers(), m, "")
+		if err != nil {
+			return err
+		}
+		return tx.OrganizationMembers().Delete(m.Id)
+	})
+	if err != nil {
+		log.Printf("OrganizationMemberService: %s", err)
+		return err
+	}
+
+	return nil
+}
+
+// Authorize fails with domain.ErrAccessDenied unless the user is a member
+// of the organization with a role that grants p.
+func (s organizationMemberService) Authorize(oId, uId uint64, p domain.Permission) error {
+	m, err := s.memberRepo.FindMember(oId, uId)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return domain.ErrAccessDenied
+		}
+		return err
+	}
+
+	if !m.Role.Can(p) {
+		return domain.ErrAccessDenied
+	}
+
+	return nil
+}
+
+// AuthorizeRole tells whether the user may grant, change or take away
+// the role: owners manage every member, managers only those ranked below them.
+func (s organizationMemberService) AuthorizeRole(oId, uId uint64, role domain.OrganizationRole) error {
+	actor, err := s.memberRepo.FindMember(oId, uId)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return domain.ErrAccessDenied
+		}
+		return err
+	}
+
+	if !actor.Role.Can(domain.ManagePermission) {
+		return domain.ErrAccessDenied
+	}
+	if !actor.Role.Can(domain.OwnPermission) && !actor.Role.Outranks(role) {
+		return domain.ErrAccessDenied
+	}
+
+	return nil
+}
+
+// checkLastOwner runs within the transaction that demotes or removes m.
+// The owners stay locked until it ends, so two of them can not take each
+// other away at the same time, and the role of m is taken as it is now.
+func checkLastOwner(mr database.OrganizationMemberRepository, m domain.OrganizationMember, role domain.OrganizationRole) error {
+	if role == domain.OrganizationOwner {
+		return nil
+	}
+
+	owners, err := mr.LockWithRole(m.OrganizationId, domain.OrganizationOwner)
+	if err != nil {
+		return err
+	}
+	for _, id := range owners {
+		if id == m.Id && len(owners) <= 1 {
+			return ErrLastOwner
+		}
+	}
+
+	return nil
+}
@@ -5,11 +5,12 @@ import (
 
 	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
 	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/database"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/events"
 )
 
 type OrganizationService interface {
-	Save(o domain.Organization) (domain.Organization, error)
-	FindForUser(uId uint64) ([]domain.Organization, error)
+	Save(o domain.Organization, u domain.User) (domain.Organization, error)
+	FindForUser(uId uint64, p domain.Pagination) (domain.Page[domain.Organization], error)
 	Find(id uint64) (interface{}, error)
 	Update(o domain.Organization) (domain.Organization, error)
 	Delete(id uint64) error
@@ -18,32 +19,66 @@ type OrganizationService interface {
 type organizationService struct {
 	organizationRepo database.OrganizationRepository
 	roomRepo         database.RoomRepository
+	uow              database.UnitOfWork
+	eventBus         events.Bus
+	outbox           WebhookOutbox
 }
 
 func NewOrganizationService(
 	or database.OrganizationRepository,
-	rr database.RoomRepository) OrganizationService {
+	rr database.RoomRepository,
+	uow database.UnitOfWork,
+	eb events.Bus,
+	wo WebhookOutbox) OrganizationService {
 	return organizationService{
 		organizationRepo: or,
 		roomRepo:         rr,
+		uow:              uow,
+		eventBus:         eb,
+		outbox:           wo,
 	}
 }
 
-func (s organizationService) Save(o domain.Organization) (domain.Organization, error) {
-	o, err := s.organizationRepo.Save(o)
+// Save makes the creator the owner, an organization is never left without one.
+// Only users with a verified email address may create organizations.
+func (s organizationService) Save(o domain.Organization, u domain.User) (domain.Organization, error) {
+	if !u.Verified() {
+		log.Printf("OrganizationService: %s", ErrEmailNotVerified)
+		return domain.Organization{}, ErrEmailNotVerified
+	}
+
+	o.UserId = u.Id
+	err := s.uow.Do(func(tx database.Tx) error {
+		var err error
+		o, err = tx.Organizations().Save(o)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.OrganizationMembers().Save(domain.OrganizationMember{
+			OrganizationId: o.Id,
+			UserId:         o.UserId,
+			Role:           domain.OrganizationOwner,
+		})
+		if err != nil {
+			return err
+		}
+		return s.outbox.AddWithin(tx, organizationEvent(domain.OrganizationCreated, o))
+	})
 	if err != nil {
 		log.Printf("OrganizationService: %s", err)
 		return domain.Organization{}, err
 	}
 
+	s.eventBus.Publish(organizationEvent(domain.OrganizationCreated, o))
 	return o, nil
 }
 
-func (s organizationService) FindForUser(uId uint64) ([]domain.Organization, error) {
-	orgs, err := s.organizationRepo.FindForUser(uId)
+func (s organizationService) FindForUser(uId uint64, p domain.Pagination) (domain.Page[domain.Organization], error) {
+	orgs, err := s.organizationRepo.FindForUser(uId, p)
 	if err != nil {
 		log.Printf("OrganizationService: %s", err)
-		return nil, err
+		return domain.Page[domain.Organization]{}, err
 	}
 
 	return orgs, nil
@@ -64,21 +99,89 @@ func (s organizationService) Find(id uint64) (interface{}, error) {
 }
 
 func (s organizationService) Update(o domain.Organization) (domain.Organization, error) {
-	org, err := s.organizationRepo.Update(o)
+	var org domain.Organization
+	err := s.uow.Do(func(tx database.Tx) error {
+		var err error
+		org, err = tx.Organizations().Update(o)
+		if err != nil {
+			return err
+		}
+		return s.outbox.AddWithin(tx, organizationEvent(domain.OrganizationUpdated, org))
+	})
 	if err != nil {
 		log.Printf("OrganizationService: %s", err)
 		return domain.Organization{}, err
 	}
 
+	s.eventBus.Publish(organizationEvent(domain.OrganizationUpdated, org))
 	return org, nil
 }
 
+// Delete takes the rooms and the devices along, whatever the device deletion
+// setting, which only applies to rooms: a device left without its organization
+// could still authenticate. Everything deleted gets the deletion time of the
+// organization, so it can be restored together, and an event of its own.
 func (s organizationService) Delete(id uint64) error {
-	err := s.organizationRepo.Delete(id)
+	var evts []domain.Event
+	err := s.uow.Do(func(tx database.Tx) error {
+		_, err := tx.Organizations().FindById(id)
+		if err != nil {
+			return err
+		}
+		rooms, err := tx.Rooms().FindForOrganization(id)
+		if err != nil {
+			return err
+		}
+		devs, err := tx.Devices().FindForOrganization(id)
+		if err != nil {
+			return err
+		}
+
+		err = tx.Organizations().Delete(id)
+		if err != nil {
+			return err
+		}
+		org, err := tx.Organizations().FindDeletedById(id)
+		if err != nil {
+			return err
+		}
+
+		err = tx.Rooms().DeleteForOrganization(id, *org.DeletedDate)
+		if err != nil {
+			return err
+		}
+		err = tx.Devices().DeleteForOrganization(id, *org.DeletedDate)
+		if err != nil {
+			return err
+		}
+
+		evts = make([]domain.Event, 0, len(devs)+len(rooms)+1)
+		for _, d := range devs {
+			d.DeletedDate = org.DeletedDate
+			evts = append(evts, deviceEvent(domain.DeviceDeleted, d))
+		}
+		for _, m := range rooms {
+			m.DeletedDate = org.DeletedDate
+			evts = append(evts, roomEvent(domain.RoomDeleted, m))
+		}
+		evts = append(evts, organizationEvent(domain.OrganizationDeleted, org))
+		return addAllWithin(tx, s.outbox, evts)
+	})
 	if err != nil {
 		log.Printf("OrganizationService: %s", err)
 		return err
 	}
 
+	for _, e := range evts {
+		s.eventBus.Publish(e)
+	}
 	return nil
 }
+
+func organizationEvent(t domain.EventType, o domain.Organization) domain.Event {
+	return domain.Event{
+		Type:           t,
+		OrganizationId: o.Id,
+		Data:           o,
+	}
+}
@@ -0,0 +1,231 @@
+package app
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/database"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/mail"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	resetTokenLength = 32
+	// resetLimit requests are accepted per email within resetWindow
+	resetLimit  = 3
+	resetWindow = time.Hour
+	// resetMailers bounds the reset mails being prepared at once
+	resetMailers = 8
+)
+
+var (
+	ErrInvalidResetToken = domain.NewError(domain.ValidationError, "invalid_reset_token", "reset token is invalid or has expired")
+	ErrTooManyResets     = domain.NewError(domain.TooManyRequestsError, "too_many_resets", "too many password reset requests, try again later")
+)
+
+type PasswordResetService interface {
+	Request(email string) error
+	Reset(pc domain.PasswordChange) error
+}
+
+type passwordResetService struct {
+	resetRepo database.PasswordResetRepository
+	userRepo  database.UserRepository
+	uow       database.UnitOfWork
+	mailer    mail.Sender
+	limiter   *rateLimiter
+	mailers   chan struct{}
+	ttl       time.Duration
+	appUrl    string
+}
+
+func NewPasswordResetService(
+	pr database.PasswordResetRepository,
+	ur database.UserRepository,
+	uow database.UnitOfWork,
+	mailer mail.Sender,
+	ttl time.Duration,
+	appUrl string) PasswordResetService {
+	return passwordResetService{
+		resetRepo: pr,
+		userRepo:  ur,
+		uow:       uow,
+		mailer:    mailer,
+		limiter:   newRateLimiter(resetLimit, resetWindow),
+		mailers:   make(chan struct{}, resetMailers),
+		ttl:       ttl,
+		appUrl:    strings.TrimRight(appUrl, "/"),
+	}
+}
+
+// Request answers the same whether or not the email belongs to a user:
+// the lookup and the mail happen in the background, so neither the result
+// nor the time it takes can be used to probe accounts.
+func (s passwordResetService) Request(email string) error {
+	email = strings.ToLower(strings.TrimSpace(email))
+	if !s.limiter.Allow(email) {
+		log.Printf("PasswordResetService: %s", ErrTooManyResets)
+		return ErrTooManyResets
+	}
+
+	select {
+	case s.mailers <- struct{}{}:
+		go func() {
+			defer func() { <-s.mailers }()
+			err := s.mailReset(email)
+			if err != nil {
+				log.Printf("PasswordResetService: %s", err)
+			}
+		}()
+	default:
+		log.Printf("PasswordResetService: too many resets in progress, request dropped")
+	}
+
+	return nil
+}
+
+func (s passwordResetService) mailReset(email string) error {
+	u, err := s.userRepo.FindByEmail(email)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	buf := make([]byte, resetTokenLength)
+	_, err = rand.Read(buf)
+	if err != nil {
+		return err
+	}
+	token := hex.EncodeToString(buf)
+
+	pr, err := s.resetRepo.Save(domain.PasswordReset{
+		UserId:      u.Id,
+		TokenHash:   s.hashToken(token),
+		ExpiresDate: time.Now().Add(s.ttl),
+	})
+	if err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%s/reset-password?token=%s", s.appUrl, url.QueryEscape(token))
+	return s.mailer.Send(mail.Message{
+		To:      u.Email,
+		Subject: "Password reset",
+		Body: fmt.Sprintf(
+			"A password reset was requested for your account.\r\n\r\n"+
+				"Set a new password: %s\r\n\r\n"+
+				"The link can be used once and expires on %s. "+
+				"If you did not ask for it, just ignore this message.\r\n",
+			link, pr.ExpiresDate.UTC().Format("2006-01-02 15:04 MST")),
+	})
+}
+
+// Reset sets the new password, burns every outstanding token of the user
+// and signs the user out of all sessions. The token is used up by the
+// same transaction that changes the password, so it is redeemed once at most.
+func (s passwordResetService) Reset(pc domain.PasswordChange) error {
+	hash, err := bcrypt.GenerateFromPassword([]byte(pc.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		log.Printf("PasswordResetService: %s", err)
+		return err
+	}
+
+	err = s.uow.Do(func(tx database.Tx) error {
+		pr, err := tx.PasswordResets().Use(s.hashToken(pc.Token))
+		if err != nil {
+			return err
+		}
+
+		u, err := tx.Users().FindById(pr.UserId)
+		if err != nil {
+			return err
+		}
+
+		err = tx.PasswordResets().UseAllForUser(u.Id)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Users().SetPassword(u.Id, string(hash))
+		if err != nil {
+			return err
+		}
+
+		return tx.Sessions().DeleteForUser(u.Id)
+	})
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			err = ErrInvalidResetToken
+		}
+		log.Printf("PasswordResetService: %s", err)
+		return err
+	}
+
+	return nil
+}
+
+func (s passwordResetService) hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// rateLimiter allows up to limit events per key within a sliding window,
+// it lives in memory, so every server instance counts on its own.
+type rateLimiter struct {
+	mu     sync.Mutex
+	limit  int
+	window time.Duration
+	events map[string][]time.Time
+}
+
+func newRateLimiter(limit int, window time.Duration) *rateLimiter {
+	return &rateLimiter{
+		limit:  limit,
+		window: window,
+		events: make(map[string][]time.Time),
+	}
+}
+
+func (l *rateLimiter) Allow(key string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	since := now.Add(-l.window)
+	recent := l.recent(key, since)
+	if len(recent) >= l.limit {
+		l.events[key] = recent
+		return false
+	}
+	l.events[key] = append(recent, now)
+
+	// forget keys nobody has used for a whole window
+	if len(l.events) > 1000 {
+		for k := range l.events {
+			if len(l.recent(k, since)) == 0 {
+				delete(l.events, k)
+			}
+		}
+	}
+	return true
+}
+
+func (l *rateLimiter) recent(key string, since time.Time) []time.Time {
+	evs := l.events[key]
+	i := 0
+	for i < len(evs) && !evs[i].After(since) {
+		i++
+	}
+	return evs[i:]
+}
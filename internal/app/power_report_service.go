@@ -0,0 +1,110 @@
+package app
+
+import (
+	"log"
+
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/database"
+)
+
+type PowerReportService interface {
+	RoomReport(rom domain.Room, uId uint64) (domain.RoomPowerReport, error)
+	OrganizationReport(org domain.Organization, uId uint64) (domain.OrganizationPowerReport, error)
+}
+
+type powerReportService struct {
+	deviceRepo    database.DeviceRepository
+	roomRepo      database.RoomRepository
+	memberService OrganizationMemberService
+}
+
+func NewPowerReportService(dr database.DeviceRepository, rr database.RoomRepository, ms OrganizationMemberService) PowerReportService {
+	return powerReportService{
+		deviceRepo:    dr,
+		roomRepo:      rr,
+		memberService: ms,
+	}
+}
+
+func (s powerReportService) RoomReport(rom domain.Room, uId uint64) (domain.RoomPowerReport, error) {
+	err := s.memberService.Authorize(rom.OrganizationId, uId, domain.ViewPermission)
+	if err != nil {
+		log.Printf("PowerReportService: %s", err)
+		return domain.RoomPowerReport{}, err
+	}
+
+	devs, err := s.deviceRepo.FindForRoom(rom.Id)
+	if err != nil {
+		log.Printf("PowerReportService: %s", err)
+		return domain.RoomPowerReport{}, err
+	}
+
+	return s.roomReport(rom, devs), nil
+}
+
+func (s powerReportService) OrganizationReport(org domain.Organization, uId uint64) (domain.OrganizationPowerReport, error) {
+	err := s.memberService.Authorize(org.Id, uId, domain.ViewPermission)
+	if err != nil {
+		log.Printf("PowerReportService: %s", err)
+		return domain.OrganizationPowerReport{}, err
+	}
+
+	rooms, err := s.roomRepo.FindForOrganization(org.Id)
+	if err != nil {
+		log.Printf("PowerReportService: %s", err)
+		return domain.OrganizationPowerReport{}, err
+	}
+
+	devs, err := s.deviceRepo.FindForOrganization(org.Id)
+	if err != nil {
+		log.Printf("PowerReportService: %s", err)
+		return domain.OrganizationPowerReport{}, err
+	}
+
+	devsByRoom := make(map[uint64][]domain.Device)
+	report := domain.OrganizationPowerReport{
+		Organization: org,
+		ByCategory:   make(map[string]float64),
+	}
+	for _, dv := range devs {
+		if dv.RoomId != nil {
+			devsByRoom[*dv.RoomId] = append(devsByRoom[*dv.RoomId], dv)
+		}
+		if dv.PowerConsumption == nil {
+			continue
+		}
+		report.TotalConsumption += *dv.PowerConsumption
+		report.ByCategory[dv.Category] += *dv.PowerConsumption
+		if dv.RoomId == nil {
+			report.Unassigned += *dv.PowerConsumption
+		}
+	}
+
+	for _, rom := range rooms {
+		rr := s.roomReport(rom, devsByRoom[rom.Id])
+		if rr.Overloaded {
+			report.OverloadedRooms = append(report.OverloadedRooms, rom.Id)
+		}
+		report.Rooms = append(report.Rooms, rr)
+	}
+
+	return report, nil
+}
+
+func (s powerReportService) roomReport(rom domain.Room, devs []domain.Device) domain.RoomPowerReport {
+	report := domain.RoomPowerReport{
+		Room:        rom,
+		ByCategory:  make(map[string]float64),
+		DeviceCount: uint64(len(devs)),
+	}
+	for _, dv := range devs {
+		if dv.PowerConsumption == nil {
+			continue
+		}
+		report.TotalConsumption += *dv.PowerConsumption
+		report.ByCategory[dv.Category] += *dv.PowerConsumption
+	}
+	report.Overloaded = rom.PowerCapacity != nil && report.TotalConsumption > *rom.PowerCapacity
+
+	return report
+}
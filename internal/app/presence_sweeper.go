@@ -0,0 +1,38 @@
+package app
+
+import (
+	"context"
+	"time"
+)
+
+type PresenceSweeper struct {
+	deviceService DeviceService
+	interval      time.Duration
+	staleAfter    time.Duration
+	offlineAfter  time.Duration
+}
+
+func NewPresenceSweeper(ds DeviceService, interval, staleAfter, offlineAfter time.Duration) PresenceSweeper {
+	return PresenceSweeper{
+		deviceService: ds,
+		interval:      interval,
+		staleAfter:    staleAfter,
+		offlineAfter:  offlineAfter,
+	}
+}
+
+// Run sweeps device presence every interval until ctx is done.
+func (s PresenceSweeper) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			// errors are already logged by the service, the next tick retries
+			_ = s.deviceService.SweepPresence(s.staleAfter, s.offlineAfter)
+		}
+	}
+}
@@ -1,60 +1,78 @@
 package app
 
 import (
-	"errors"
+	"fmt"
 	"log"
 
 	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
 	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/database"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/events"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/filesystem"
+	"github.com/google/uuid"
 )
 
 type RoomService interface {
 	Save(m domain.Room, uId uint64) (domain.Room, error)
-	FindForOrganization(oId uint64) ([]domain.Room, error)
+	FindAll(f domain.RoomFilters, p domain.Pagination) (domain.Page[domain.Room], error)
 	Find(id uint64) (interface{}, error)
 	Update(m domain.Room) (domain.Room, error)
 	Delete(id uint64) error
+	CheckAccess(m domain.Room, uId uint64, p domain.Permission) error
+	SaveAsset(m domain.Room, asset domain.RoomAsset, ext string, content []byte, uId uint64) (domain.Room, error)
+	RemoveAsset(m domain.Room, asset domain.RoomAsset, uId uint64) (domain.Room, error)
 }
 
 type roomService struct {
-	roomRepo               database.RoomRepository
-	organizationRepository database.OrganizationRepository
+	roomRepo       database.RoomRepository
+	memberService  OrganizationMemberService
+	uow            database.UnitOfWork
+	eventBus       events.Bus
+	outbox         WebhookOutbox
+	fileStorage    filesystem.FileStorageService
+	deviceDeletion domain.DeviceDeletion
 }
 
-func NewRoomService(ro database.RoomRepository, or database.OrganizationRepository) RoomService {
+func NewRoomService(ro database.RoomRepository, ms OrganizationMemberService, uow database.UnitOfWork, eb events.Bus, wo WebhookOutbox, fs filesystem.FileStorageService, dd domain.DeviceDeletion) RoomService {
 	return &roomService{
-		roomRepo:               ro,
-		organizationRepository: or,
+		roomRepo:       ro,
+		memberService:  ms,
+		uow:            uow,
+		eventBus:       eb,
+		outbox:         wo,
+		fileStorage:    fs,
+		deviceDeletion: dd,
 	}
 }
 
 func (s roomService) Save(m domain.Room, uId uint64) (domain.Room, error) {
-	org, err := s.organizationRepository.FindById(m.OrganizationId)
+	err := s.CheckAccess(m, uId, domain.ManagePermission)
 	if err != nil {
 		log.Printf("RoomService: %s", err)
 		return domain.Room{}, err
 	}
 
-	if org.UserId != uId {
-		err = errors.New("access denied")
-		log.Panicf("RoomService: %s", err)
-		return domain.Room{}, err
-	}
-
-	m, err = s.roomRepo.Save(m)
+	err = s.uow.Do(func(tx database.Tx) error {
+		var err error
+		m, err = tx.Rooms().Save(m)
+		if err != nil {
+			return err
+		}
+		return s.outbox.AddWithin(tx, roomEvent(domain.RoomCreated, m))
+	})
 	if err != nil {
 		log.Printf("RoomService: %s", err)
 		return domain.Room{}, err
 	}
 
+	s.eventBus.Publish(roomEvent(domain.RoomCreated, m))
 	return m, nil
 }
 
-func (s roomService) FindForOrganization(oId uint64) ([]domain.Room, error) {
-	rooms, err := s.roomRepo.FindForOrganization(oId)
+func (s roomService) FindAll(f domain.RoomFilters, p domain.Pagination) (domain.Page[domain.Room], error) {
+	rooms, err := s.roomRepo.FindAll(f, p)
 	if err != nil {
 		log.Printf("RoomService: %s", err)
-		return nil, err
+		return domain.Page[domain.Room]{}, err
 	}
 
 	return rooms, nil
@@ -71,21 +89,146 @@ func (s roomService) Find(id uint64) (interface{}, error) {
 }
 
 func (s roomService) Update(m domain.Room) (domain.Room, error) {
-	room, err := s.roomRepo.Update(m)
+	var room domain.Room
+	err := s.uow.Do(func(tx database.Tx) error {
+		var err error
+		room, err = tx.Rooms().Update(m)
+		if err != nil {
+			return err
+		}
+		return s.outbox.AddWithin(tx, roomEvent(domain.RoomUpdated, room))
+	})
 	if err != nil {
 		log.Printf("RoomService: %s", err)
 		return domain.Room{}, err
 	}
 
+	s.eventBus.Publish(roomEvent(domain.RoomUpdated, room))
 	return room, nil
 }
 
+// Delete either deletes the devices of the room along with it, sharing
+// its deletion time, or takes them out of the room, so none of them is
+// left in a room that does not exist anymore.
 func (s roomService) Delete(id uint64) error {
-	err := s.roomRepo.Delete(id)
+	var evts []domain.Event
+	err := s.uow.Do(func(tx database.Tx) error {
+		_, err := tx.Rooms().FindById(id)
+		if err != nil {
+			return err
+		}
+		devs, err := tx.Devices().FindForRoom(id)
+		if err != nil {
+			return err
+		}
+
+		err = tx.Rooms().Delete(id)
+		if err != nil {
+			return err
+		}
+		room, err := tx.Rooms().FindDeletedById(id)
+		if err != nil {
+			return err
+		}
+
+		if s.deviceDeletion == domain.CascadeDevices {
+			err = tx.Devices().DeleteForRoom(id, *room.DeletedDate)
+			if err != nil {
+				return err
+			}
+		}
+		evts = make([]domain.Event, 0, len(devs)+1)
+		for _, d := range devs {
+			if s.deviceDeletion == domain.CascadeDevices {
+				d.DeletedDate = room.DeletedDate
+				evts = append(evts, deviceEvent(domain.DeviceDeleted, d))
+				continue
+			}
+			err = tx.Devices().RemoveDeviceFromRoom(d.Id)
+			if err != nil {
+				return err
+			}
+			d.RoomId, d.Placement = nil, nil
+			e := deviceEvent(domain.DeviceUpdated, d)
+			e.PrevRoomId = &room.Id
+			evts = append(evts, e)
+		}
+		evts = append(evts, roomEvent(domain.RoomDeleted, room))
+		return addAllWithin(tx, s.outbox, evts)
+	})
 	if err != nil {
 		log.Printf("RoomService: %s", err)
 		return err
 	}
 
+	for _, e := range evts {
+		s.eventBus.Publish(e)
+	}
 	return nil
 }
+
+// SaveAsset stores the file under a fresh name, so clients never get
+// a stale cached copy, and removes the one it replaces.
+func (s roomService) SaveAsset(m domain.Room, asset domain.RoomAsset, ext string, content []byte, uId uint64) (domain.Room, error) {
+	err := s.CheckAccess(m, uId, domain.ManagePermission)
+	if err != nil {
+		log.Printf("RoomService: %s", err)
+		return domain.Room{}, err
+	}
+
+	filename := fmt.Sprintf("rooms/%d/%s-%s%s", m.Id, asset, uuid.New(), ext)
+	err = s.fileStorage.SaveFile(filename, content)
+	if err != nil {
+		log.Printf("RoomService: %s", err)
+		return domain.Room{}, err
+	}
+
+	return s.replaceAsset(m, asset, &filename)
+}
+
+func (s roomService) RemoveAsset(m domain.Room, asset domain.RoomAsset, uId uint64) (domain.Room, error) {
+	err := s.CheckAccess(m, uId, domain.ManagePermission)
+	if err != nil {
+		log.Printf("RoomService: %s", err)
+		return domain.Room{}, err
+	}
+
+	return s.replaceAsset(m, asset, nil)
+}
+
+func (s roomService) replaceAsset(m domain.Room, asset domain.RoomAsset, filename *string) (domain.Room, error) {
+	var old *string
+	switch asset {
+	case domain.RoomFloorPlan:
+		old, m.FloorPlan = m.FloorPlan, filename
+	case domain.RoomModel:
+		old, m.Model = m.Model, filename
+	}
+
+	room, err := s.Update(m)
+	if err != nil {
+		return domain.Room{}, err
+	}
+
+	if old != nil {
+		err = s.fileStorage.RemoveFile(*old)
+		if err != nil {
+			// the room does not point to it anymore, an orphan file is harmless
+			log.Printf("RoomService: %s", err)
+		}
+	}
+	return room, nil
+}
+
+func (s roomService) CheckAccess(m domain.Room, uId uint64, p domain.Permission) error {
+	return s.memberService.Authorize(m.OrganizationId, uId, p)
+}
+
+func roomEvent(t domain.EventType, m domain.Room) domain.Event {
+	return domain.Event{
+		Type:           t,
+		OrganizationId: m.OrganizationId,
+		RoomId:         &m.Id,
+		Data:           m,
+	}
+}
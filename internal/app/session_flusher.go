@@ -0,0 +1,37 @@
+package app
+
+import (
+	"context"
+	"time"
+)
+
+// SessionFlusher writes the last use of sessions every interval,
+// so that authenticated requests do not each cost a database write.
+type SessionFlusher struct {
+	sessionService SessionService
+	interval       time.Duration
+}
+
+func NewSessionFlusher(ss SessionService, interval time.Duration) SessionFlusher {
+	return SessionFlusher{
+		sessionService: ss,
+		interval:       interval,
+	}
+}
+
+// Run flushes every interval until ctx is done and one last time after.
+func (f SessionFlusher) Run(ctx context.Context) {
+	ticker := time.NewTicker(f.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			_ = f.sessionService.Flush()
+			return
+		case <-ticker.C:
+			// errors are already logged by the service, those times are lost
+			_ = f.sessionService.Flush()
+		}
+	}
+}
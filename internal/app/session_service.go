@@ -0,0 +1,132 @@
+package app
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/database"
+	"github.com/google/uuid"
+)
+
+type SessionService interface {
+	FindForUser(uId uint64, p domain.Pagination) (domain.Page[domain.Session], error)
+	FindByGUID(id uuid.UUID) (interface{}, error)
+	Revoke(other, sess domain.Session) error
+	RevokeOthers(sess domain.Session) error
+	Touch(sess domain.Session)
+	Flush() error
+}
+
+type sessionService struct {
+	sessionRepo database.SessionRepository
+	lastUsed    *lastUsedBuffer
+}
+
+func NewSessionService(sr database.SessionRepository) SessionService {
+	return sessionService{
+		sessionRepo: sr,
+		lastUsed:    &lastUsedBuffer{times: make(map[uuid.UUID]time.Time)},
+	}
+}
+
+func (s sessionService) FindForUser(uId uint64, p domain.Pagination) (domain.Page[domain.Session], error) {
+	ss, err := s.sessionRepo.FindForUser(uId, p)
+	if err != nil {
+		log.Printf("SessionService: %s", err)
+		return domain.Page[domain.Session]{}, err
+	}
+
+	// show what has not been flushed yet as well
+	for i := range ss.Items {
+		if t, ok := s.lastUsed.get(ss.Items[i].UUID); ok && t.After(ss.Items[i].LastUsedDate) {
+			ss.Items[i].LastUsedDate = t
+		}
+	}
+
+	return ss, nil
+}
+
+func (s sessionService) FindByGUID(id uuid.UUID) (interface{}, error) {
+	sess, err := s.sessionRepo.FindByUUID(id)
+	if err != nil {
+		log.Printf("SessionService: %s", err)
+		return nil, err
+	}
+
+	return sess, nil
+}
+
+// Revoke closes a session of the same user, domain.ErrNotFound is
+// returned for sessions of somebody else, so they can not be probed.
+func (s sessionService) Revoke(other, sess domain.Session) error {
+	if other.UserId != sess.UserId {
+		log.Printf("SessionService: %s", domain.ErrNotFound)
+		return domain.ErrNotFound
+	}
+
+	err := s.sessionRepo.Delete(other)
+	if err != nil {
+		log.Printf("SessionService: %s", err)
+		return err
+	}
+
+	return nil
+}
+
+func (s sessionService) RevokeOthers(sess domain.Session) error {
+	err := s.sessionRepo.DeleteOthers(sess)
+	if err != nil {
+		log.Printf("SessionService: %s", err)
+		return err
+	}
+
+	return nil
+}
+
+// Touch only remembers when the session was used, Flush writes it down.
+func (s sessionService) Touch(sess domain.Session) {
+	s.lastUsed.set(sess.UUID, time.Now())
+}
+
+func (s sessionService) Flush() error {
+	times := s.lastUsed.take()
+	if len(times) == 0 {
+		return nil
+	}
+
+	err := s.sessionRepo.Touch(times)
+	if err != nil {
+		log.Printf("SessionService: %s", err)
+		return err
+	}
+
+	return nil
+}
+
+type lastUsedBuffer struct {
+	mu    sync.Mutex
+	times map[uuid.UUID]time.Time
+}
+
+func (b *lastUsedBuffer) set(id uuid.UUID, t time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.times[id] = t
+}
+
+func (b *lastUsedBuffer) get(id uuid.UUID) (time.Time, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	t, ok := b.times[id]
+	return t, ok
+}
+
+func (b *lastUsedBuffer) take() map[uuid.UUID]time.Time {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	times := b.times
+	b.times = make(map[uuid.UUID]time.Time)
+	return times
+}
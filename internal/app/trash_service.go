@@ -0,0 +1,327 @@
+package app
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/database"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/events"
+)
+
+var ErrRetentionExpired = domain.NewError(domain.ConflictError, "retention_expired", "the record was deleted too long ago to be restored")
+
+// TrashService lets members see and restore what was deleted from their
+// organizations for as long as the retention lasts.
+type TrashService interface {
+	FindRooms(oId uint64, p domain.Pagination, uId uint64) (domain.Page[domain.Room], error)
+	FindDevices(oId uint64, p domain.Pagination, uId uint64) (domain.Page[domain.Device], error)
+	FindDeletedOrganization(id uint64) (interface{}, error)
+	FindDeletedRoom(id uint64) (interface{}, error)
+	FindDeletedDevice(id uint64) (interface{}, error)
+	RestoreOrganization(o domain.Organization, uId uint64) (domain.Organization, error)
+	RestoreRoom(r domain.Room, uId uint64) (domain.Room, error)
+	RestoreDevice(d domain.Device, uId uint64) (domain.Device, error)
+}
+
+type trashService struct {
+	orgRepo       database.OrganizationRepository
+	roomRepo      database.RoomRepository
+	deviceRepo    database.DeviceRepository
+	memberService OrganizationMemberService
+	uow           database.UnitOfWork
+	eventBus      events.Bus
+	outbox        WebhookOutbox
+	retention     time.Duration
+}
+
+func NewTrashService(
+	or database.OrganizationRepository,
+	rr database.RoomRepository,
+	dr database.DeviceRepository,
+	ms OrganizationMemberService,
+	uow database.UnitOfWork,
+	eb events.Bus,
+	wo WebhookOutbox,
+	retention time.Duration) TrashService {
+	return trashService{
+		orgRepo:       or,
+		roomRepo:      rr,
+		deviceRepo:    dr,
+		memberService: ms,
+		uow:           uow,
+		eventBus:      eb,
+		outbox:        wo,
+		retention:     retention,
+	}
+}
+
+func (s trashService) FindRooms(oId uint64, p domain.Pagination, uId uint64) (domain.Page[domain.Room], error) {
+	err := s.memberService.Authorize(oId, uId, domain.ManagePermission)
+	if err != nil {
+		log.Printf("TrashService: %s", err)
+		return domain.Page[domain.Room]{}, err
+	}
+
+	since := time.Now().Add(-s.retention)
+	rooms, err := s.roomRepo.FindAll(domain.RoomFilters{OrganizationId: oId, Deleted: true, DeletedSince: &since}, p)
+	if err != nil {
+		log.Printf("TrashService: %s", err)
+		return domain.Page[domain.Room]{}, err
+	}
+
+	return rooms, nil
+}
+
+func (s trashService) FindDevices(oId uint64, p domain.Pagination, uId uint64) (domain.Page[domain.Device], error) {
+	err := s.memberService.Authorize(oId, uId, domain.ManagePermission)
+	if err != nil {
+		log.Printf("TrashService: %s", err)
+		return domain.Page[domain.Device]{}, err
+	}
+
+	since := time.Now().Add(-s.retention)
+	devs, err := s.deviceRepo.FindAll(domain.DeviceFilters{OrganizationId: oId, Deleted: true, DeletedSince: &since}, p)
+	if err != nil {
+		log.Printf("TrashService: %s", err)
+		return domain.Page[domain.Device]{}, err
+	}
+
+	return devs, nil
+}
+
+func (s trashService) FindDeletedOrganization(id uint64) (interface{}, error) {
+	o, err := s.orgRepo.FindDeletedById(id)
+	if err != nil {
+		log.Printf("TrashService: %s", err)
+		return nil, err
+	}
+
+	return o, nil
+}
+
+func (s trashService) FindDeletedRoom(id uint64) (interface{}, error) {
+	r, err := s.roomRepo.FindDeletedById(id)
+	if err != nil {
+		log.Printf("TrashService: %s", err)
+		return nil, err
+	}
+
+	return r, nil
+}
+
+func (s trashService) FindDeletedDevice(id uint64) (interface{}, error) {
+	d, err := s.deviceRepo.FindDeletedById(id)
+	if err != nil {
+		log.Printf("TrashService: %s", err)
+		return nil, err
+	}
+
+	return d, nil
+}
+
+// RestoreOrganization is left to the owners, it brings back the rooms
+// and devices deleted along with the organization as well.
+func (s trashService) RestoreOrganization(o domain.Organization, uId uint64) (domain.Organization, error) {
+	err := s.memberService.Authorize(o.Id, uId, domain.OwnPermission)
+	if err == nil {
+		err = s.checkRetention(o.DeletedDate)
+	}
+	var evts []domain.Event
+	if err == nil {
+		err = s.uow.Do(func(tx database.Tx) error {
+			var err error
+			evts, err = restoreOrganization(tx, o)
+			if err != nil {
+				return err
+			}
+			return addAllWithin(tx, s.outbox, evts)
+		})
+	}
+	if err != nil {
+		log.Printf("TrashService: %s", err)
+		return domain.Organization{}, err
+	}
+	for _, e := range evts {
+		s.eventBus.Publish(e)
+	}
+
+	o, err = s.orgRepo.FindById(o.Id)
+	if err != nil {
+		log.Printf("TrashService: %s", err)
+		return domain.Organization{}, err
+	}
+
+	return o, nil
+}
+
+func (s trashService) RestoreRoom(r domain.Room, uId uint64) (domain.Room, error) {
+	err := s.memberService.Authorize(r.OrganizationId, uId, domain.ManagePermission)
+	if err == nil {
+		err = s.checkRetention(r.DeletedDate)
+	}
+	var evts []domain.Event
+	if err == nil {
+		err = s.uow.Do(func(tx database.Tx) error {
+			var err error
+			evts, err = restoreRoom(tx, r)
+			if err != nil {
+				return err
+			}
+			return addAllWithin(tx, s.outbox, evts)
+		})
+	}
+	if err != nil {
+		log.Printf("TrashService: %s", err)
+		return domain.Room{}, err
+	}
+	for _, e := range evts {
+		s.eventBus.Publish(e)
+	}
+
+	r, err = s.roomRepo.FindById(r.Id)
+	if err != nil {
+		log.Printf("TrashService: %s", err)
+		return domain.Room{}, err
+	}
+
+	return r, nil
+}
+
+func (s trashService) RestoreDevice(d domain.Device, uId uint64) (domain.Device, error) {
+	err := s.memberService.Authorize(d.OrganizationId, uId, domain.ManagePermission)
+	if err == nil {
+		err = s.checkRetention(d.DeletedDate)
+	}
+	var evts []domain.Event
+	if err == nil {
+		err = s.uow.Do(func(tx database.Tx) error {
+			var err error
+			evts, err = restoreDevice(tx, d)
+			if err != nil {
+				return err
+			}
+			return addAllWithin(tx, s.outbox, evts)
+		})
+	}
+	if err != nil {
+		log.Printf("TrashService: %s", err)
+		return domain.Device{}, err
+	}
+	for _, e := range evts {
+		s.eventBus.Publish(e)
+	}
+
+	d, err = s.deviceRepo.FindById(d.Id)
+	if err != nil {
+		log.Printf("TrashService: %s", err)
+		return domain.Device{}, err
+	}
+
+	return d, nil
+}
+
+func (s trashService) checkRetention(deletedDate *time.Time) error {
+	if deletedDate != nil && time.Since(*deletedDate) > s.retention {
+		return ErrRetentionExpired
+	}
+	return nil
+}
+
+// restoreOrganization brings back what shares the deletion time of the
+// organization, things deleted before it stay deleted. It returns the
+// events of everything restored.
+func restoreOrganization(tx database.Tx, o domain.Organization) ([]domain.Event, error) {
+	err := tx.Organizations().Restore(o.Id)
+	if err == nil && o.DeletedDate != nil {
+		err = tx.Rooms().RestoreForOrganization(o.Id, *o.DeletedDate)
+		if err == nil {
+			err = tx.Devices().RestoreForOrganization(o.Id, *o.DeletedDate)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// nothing of a deleted organization is live, so what is now came back with it
+	o, err = tx.Organizations().FindById(o.Id)
+	if err != nil {
+		return nil, err
+	}
+	rooms, err := tx.Rooms().FindForOrganization(o.Id)
+	if err != nil {
+		return nil, err
+	}
+	devs, err := tx.Devices().FindForOrganization(o.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	evts := make([]domain.Event, 0, len(rooms)+len(devs)+1)
+	evts = append(evts, organizationEvent(domain.OrganizationRestored, o))
+	for _, m := range rooms {
+		evts = append(evts, roomEvent(domain.RoomRestored, m))
+	}
+	for _, d := range devs {
+		evts = append(evts, deviceEvent(domain.DeviceRestored, d))
+	}
+	return evts, nil
+}
+
+func restoreRoom(tx database.Tx, r domain.Room) ([]domain.Event, error) {
+	err := checkParent(tx.Organizations().FindById(r.OrganizationId))
+	if err == nil {
+		err = tx.Rooms().Restore(r.Id)
+	}
+	if err == nil && r.DeletedDate != nil {
+		err = tx.Devices().RestoreForRoom(r.Id, *r.DeletedDate)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// a deleted room keeps no live devices, so those in it now came back with it
+	r, err = tx.Rooms().FindById(r.Id)
+	if err != nil {
+		return nil, err
+	}
+	devs, err := tx.Devices().FindForRoom(r.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	evts := make([]domain.Event, 0, len(devs)+1)
+	evts = append(evts, roomEvent(domain.RoomRestored, r))
+	for _, d := range devs {
+		evts = append(evts, deviceEvent(domain.DeviceRestored, d))
+	}
+	return evts, nil
+}
+
+func restoreDevice(tx database.Tx, d domain.Device) ([]domain.Event, error) {
+	err := checkParent(tx.Organizations().FindById(d.OrganizationId))
+	if err == nil && d.RoomId != nil {
+		err = checkParent(tx.Rooms().FindById(*d.RoomId))
+	}
+	if err == nil {
+		err = tx.Devices().Restore(d.Id)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	d, err = tx.Devices().FindById(d.Id)
+	if err != nil {
+		return nil, err
+	}
+	return []domain.Event{deviceEvent(domain.DeviceRestored, d)}, nil
+}
+
+// checkParent turns a missing parent record into ErrParentDeleted.
+func checkParent[T any](_ T, err error) error {
+	if errors.Is(err, domain.ErrNotFound) {
+		return ErrParentDeleted
+	}
+	return err
+}
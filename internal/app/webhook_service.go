@@ -0,0 +1,244 @@
+package app
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/database"
+)
+
+const (
+	maxWebhookAttempts   = 8
+	webhookBackoffBase   = 30 * time.Second
+	webhookBackoffMax    = 6 * time.Hour
+	webhookDeliveryBatch = 50
+)
+
+// WebhookSender posts a delivery to the webhook URL and returns the response code.
+type WebhookSender interface {
+	Send(w domain.Webhook, d domain.WebhookDelivery) (int, error)
+}
+
+// WebhookOutbox queues a delivery of the event for every enabled webhook
+// of its organization interested in it. AddWithin writes them through tx,
+// so they are committed or rolled back along with the change itself.
+type WebhookOutbox interface {
+	Add(e domain.Event) error
+	AddWithin(tx database.Tx, e domain.Event) error
+}
+
+// addAllWithin queues the events in order through tx.
+func addAllWithin(tx database.Tx, wo WebhookOutbox, evts []domain.Event) error {
+	for _, e := range evts {
+		err := wo.AddWithin(tx, e)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type WebhookService interface {
+	Save(w domain.Webhook, uId uint64) (domain.Webhook, error)
+	Update(w domain.Webhook, uId uint64) (domain.Webhook, error)
+	Delete(w domain.Webhook, uId uint64) error
+	Find(id uint64) (interface{}, error)
+	FindForOrganization(oId uint64, p domain.Pagination, uId uint64) (domain.Page[domain.Webhook], error)
+	FindDeliveries(w domain.Webhook, p domain.Pagination, uId uint64) (domain.Page[domain.WebhookDelivery], error)
+	DeliverDue() error
+	CheckAccess(oId, uId uint64, p domain.Permission) error
+}
+
+type webhookService struct {
+	webhookRepo  database.WebhookRepository
+	deliveryRepo database.WebhookDeliveryRepository
+	members      OrganizationMemberService
+	sender       WebhookSender
+}
+
+func NewWebhookService(
+	wr database.WebhookRepository,
+	dr database.WebhookDeliveryRepository,
+	ms OrganizationMemberService,
+	ws WebhookSender) WebhookService {
+	return webhookService{
+		webhookRepo:  wr,
+		deliveryRepo: dr,
+		members:      ms,
+		sender:       ws,
+	}
+}
+
+func (s webhookService) Save(w domain.Webhook, uId uint64) (domain.Webhook, error) {
+	err := s.CheckAccess(w.OrganizationId, uId, domain.ManagePermission)
+	if err != nil {
+		log.Printf("WebhookService: %s", err)
+		return domain.Webhook{}, err
+	}
+
+	w.UserId = uId
+	w, err = s.webhookRepo.Save(w)
+	if err != nil {
+		log.Printf("WebhookService: %s", err)
+		return domain.Webhook{}, err
+	}
+
+	return w, nil
+}
+
+func (s webhookService) Update(w domain.Webhook, uId uint64) (domain.Webhook, error) {
+	err := s.CheckAccess(w.OrganizationId, uId, domain.ManagePermission)
+	if err != nil {
+		log.Printf("WebhookService: %s", err)
+		return domain.Webhook{}, err
+	}
+
+	w, err = s.webhookRepo.Update(w)
+	if err != nil {
+		log.Printf("WebhookService: %s", err)
+		return domain.Webhook{}, err
+	}
+
+	return w, nil
+}
+
+func (s webhookService) Delete(w domain.Webhook, uId uint64) error {
+	err := s.CheckAccess(w.OrganizationId, uId, domain.ManagePermission)
+	if err != nil {
+		log.Printf("WebhookService: %s", err)
+		return err
+	}
+
+	err = s.webhookRepo.Delete(w.Id)
+	if err != nil {
+		log.Printf("WebhookService: %s", err)
+		return err
+	}
+
+	return nil
+}
+
+func (s webhookService) Find(id uint64) (interface{}, error) {
+	w, err := s.webhookRepo.FindById(id)
+	if err != nil {
+		log.Printf("WebhookService: %s", err)
+		return nil, err
+	}
+
+	return w, nil
+}
+
+func (s webhookService) FindForOrganization(oId uint64, p domain.Pagination, uId uint64) (domain.Page[domain.Webhook], error) {
+	err := s.CheckAccess(oId, uId, domain.ManagePermission)
+	if err != nil {
+		log.Printf("WebhookService: %s", err)
+		return domain.Page[domain.Webhook]{}, err
+	}
+
+	hooks, err := s.webhookRepo.FindForOrganization(oId, p)
+	if err != nil {
+		log.Printf("WebhookService: %s", err)
+		return domain.Page[domain.Webhook]{}, err
+	}
+
+	return hooks, nil
+}
+
+func (s webhookService) FindDeliveries(w domain.Webhook, p domain.Pagination, uId uint64) (domain.Page[domain.WebhookDelivery], error) {
+	err := s.CheckAccess(w.OrganizationId, uId, domain.ManagePermission)
+	if err != nil {
+		log.Printf("WebhookService: %s", err)
+		return domain.Page[domain.WebhookDelivery]{}, err
+	}
+
+	dlvs, err := s.deliveryRepo.FindForWebhook(w.Id, p)
+	if err != nil {
+		log.Printf("WebhookService: %s", err)
+		return domain.Page[domain.WebhookDelivery]{}, err
+	}
+
+	return dlvs, nil
+}
+
+// DeliverDue sends deliveries whose next attempt is due. A failed attempt
+// is retried with exponential backoff until maxWebhookAttempts is reached.
+func (s webhookService) DeliverDue() error {
+	dlvs, err := s.deliveryRepo.FindDue(time.Now(), webhookDeliveryBatch)
+	if err != nil {
+		log.Printf("WebhookService: %s", err)
+		return err
+	}
+
+	for _, d := range dlvs {
+		w, err := s.webhookRepo.FindById(d.WebhookId)
+		if err != nil && !errors.Is(err, domain.ErrNotFound) {
+			log.Printf("WebhookService: %s", err)
+			return err
+		}
+
+		if err != nil || !w.Enabled {
+			msg := "webhook was removed or disabled"
+			d.Status = domain.DeliveryFailed
+			d.Error = &msg
+			d.NextAttemptDate = nil
+		} else {
+			d = s.attempt(w, d)
+		}
+
+		_, err = s.deliveryRepo.Update(d)
+		if err != nil {
+			log.Printf("WebhookService: %s", err)
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s webhookService) attempt(w domain.Webhook, d domain.WebhookDelivery) domain.WebhookDelivery {
+	code, err := s.sender.Send(w, d)
+	now := time.Now()
+	d.Attempts++
+	d.ResponseCode = nil
+	d.Error = nil
+	if code != 0 {
+		d.ResponseCode = &code
+	}
+
+	if err == nil {
+		d.Status = domain.DeliverySucceeded
+		d.DeliveredDate = &now
+		d.NextAttemptDate = nil
+		return d
+	}
+
+	msg := err.Error()
+	d.Error = &msg
+	if d.Attempts >= maxWebhookAttempts {
+		d.Status = domain.DeliveryFailed
+		d.NextAttemptDate = nil
+		return d
+	}
+
+	next := now.Add(webhookBackoff(d.Attempts))
+	d.NextAttemptDate = &next
+	return d
+}
+
+// webhookBackoff doubles the wait after every failed attempt, up to webhookBackoffMax.
+func webhookBackoff(attempts int) time.Duration {
+	backoff := webhookBackoffBase
+	for i := 1; i < attempts && backoff < webhookBackoffMax; i++ {
+		backoff *= 2
+	}
+	if backoff > webhookBackoffMax {
+		return webhookBackoffMax
+	}
+	return backoff
+}
+
+func (s webhookService) CheckAccess(oId, uId uint64, p domain.Permission) error {
+	return s.members.Authorize(oId, uId, p)
+}
@@ -0,0 +1,91 @@
+package app
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+)
+
+type fakeSender struct {
+	code int
+	err  error
+}
+
+func (f fakeSender) Send(domain.Webhook, domain.WebhookDelivery) (int, error) {
+	return f.code, f.err
+}
+
+func TestWebhookServiceAttempt(t *testing.T) {
+	failure := fakeSender{code: 503, err: errors.New("unexpected response status 503")}
+	tests := []struct {
+		name     string
+		sender   fakeSender
+		attempts int // made before this one
+		status   domain.DeliveryStatus
+		backoff  time.Duration // until the next attempt, 0 when there is none
+	}{
+		{"success", fakeSender{code: 200}, 0, domain.DeliverySucceeded, 0},
+		{"first failure", failure, 0, domain.DeliveryPending, webhookBackoffBase},
+		{"second failure", failure, 1, domain.DeliveryPending, 2 * webhookBackoffBase},
+		{"fourth failure", failure, 3, domain.DeliveryPending, 8 * webhookBackoffBase},
+		{"seventh failure", failure, 6, domain.DeliveryPending, 64 * webhookBackoffBase},
+		{"connection error", fakeSender{err: errors.New("connection refused")}, 2, domain.DeliveryPending, 4 * webhookBackoffBase},
+		{"last attempt", failure, maxWebhookAttempts - 1, domain.DeliveryFailed, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := webhookService{sender: tt.sender}
+			before := time.Now()
+			d := s.attempt(domain.Webhook{}, domain.WebhookDelivery{Status: domain.DeliveryPending, Attempts: tt.attempts})
+
+			if d.Attempts != tt.attempts+1 {
+				t.Errorf("attempts %d, want %d", d.Attempts, tt.attempts+1)
+			}
+			if d.Status != tt.status {
+				t.Errorf("status %s, want %s", d.Status, tt.status)
+			}
+			if (d.ResponseCode != nil) != (tt.sender.code != 0) {
+				t.Errorf("response code %v for sender code %d", d.ResponseCode, tt.sender.code)
+			}
+			if (d.Error != nil) != (tt.sender.err != nil) {
+				t.Errorf("error %v for sender error %v", d.Error, tt.sender.err)
+			}
+
+			if tt.backoff == 0 {
+				if d.NextAttemptDate != nil {
+					t.Errorf("next attempt at %s, want none", d.NextAttemptDate)
+				}
+				return
+			}
+			if d.NextAttemptDate == nil {
+				t.Fatal("no next attempt")
+			}
+			if got := d.NextAttemptDate.Sub(before); got < tt.backoff || got > tt.backoff+time.Second {
+				t.Errorf("next attempt in %s, want %s", got, tt.backoff)
+			}
+		})
+	}
+}
+
+func TestWebhookBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, 30 * time.Second},
+		{2, time.Minute},
+		{3, 2 * time.Minute},
+		{7, 32 * time.Minute},
+		{10, 256 * time.Minute},
+		{11, webhookBackoffMax},
+		{40, webhookBackoffMax},
+		{100, webhookBackoffMax},
+	}
+	for _, tt := range tests {
+		if got := webhookBackoff(tt.attempts); got != tt.want {
+			t.Errorf("after %d attempts: got %s, want %s", tt.attempts, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package domain
+
+import "time"
+
+// AlertRule watches readings of a single device or of every sensor in a room.
+// Units narrows a room rule down to sensors measuring the same quantity.
+type AlertRule struct {
+	Id             uint64
+	OrganizationId uint64
+	DeviceId       *uint64
+	RoomId         *uint64
+	UserId         uint64
+	Name           string
+	Units          *string
+	Operator       AlertOperator
+	Threshold      float64
+	Duration       time.Duration
+	Enabled        bool
+	CreatedDate    time.Time
+	UpdatedDate    time.Time
+	DeletedDate    *time.Time
+}
+
+type AlertOperator string
+
+const (
+	AlertGreater        AlertOperator = ">"
+	AlertGreaterOrEqual AlertOperator = ">="
+	AlertLess           AlertOperator = "<"
+	AlertLessOrEqual    AlertOperator = "<="
+)
+
+func (r AlertRule) Breached(value float64) bool {
+	switch r.Operator {
+	case AlertGreater:
+		return value > r.Threshold
+	case AlertGreaterOrEqual:
+		return value >= r.Threshold
+	case AlertLess:
+		return value < r.Threshold
+	case AlertLessOrEqual:
+		return value <= r.Threshold
+	}
+	return false
+}
+
+// Alert is PENDING while the condition holds for less than the rule duration,
+// OPEN once it has held long enough and RESOLVED when it clears.
+type Alert struct {
+	Id               uint64
+	RuleId           uint64
+	OrganizationId   uint64
+	DeviceId         uint64
+	RoomId           *uint64
+	Status           AlertStatus
+	Value            float64
+	StartedDate      time.Time
+	OpenedDate       *time.Time
+	ResolvedDate     *time.Time
+	AcknowledgedDate *time.Time
+	AcknowledgedBy   *uint64
+	CreatedDate      time.Time
+	UpdatedDate      time.Time
+}
+
+type AlertStatus string
+
+const (
+	AlertPending  AlertStatus = "PENDING"
+	AlertOpen     AlertStatus = "OPEN"
+	AlertResolved AlertStatus = "RESOLVED"
+)
@@ -0,0 +1,31 @@
+package domain
+
+import "testing"
+
+func TestAlertRuleBreached(t *testing.T) {
+	tests := []struct {
+		operator AlertOperator
+		value    float64
+		want     bool
+	}{
+		{AlertGreater, 30.5, true},
+		{AlertGreater, 30, false},
+		{AlertGreater, 29.5, false},
+		{AlertGreaterOrEqual, 30.5, true},
+		{AlertGreaterOrEqual, 30, true},
+		{AlertGreaterOrEqual, 29.5, false},
+		{AlertLess, 29.5, true},
+		{AlertLess, 30, false},
+		{AlertLess, 30.5, false},
+		{AlertLessOrEqual, 29.5, true},
+		{AlertLessOrEqual, 30, true},
+		{AlertLessOrEqual, 30.5, false},
+		{AlertOperator("=="), 30, false},
+	}
+	for _, tt := range tests {
+		r := AlertRule{Operator: tt.operator, Threshold: 30}
+		if got := r.Breached(tt.value); got != tt.want {
+			t.Errorf("%v %s 30: got %v, want %v", tt.value, tt.operator, got, tt.want)
+		}
+	}
+}
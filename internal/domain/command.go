@@ -0,0 +1,28 @@
+package domain
+
+import "time"
+
+type Command struct {
+	Id            uint64
+	DeviceId      uint64
+	UserId        uint64
+	Action        string
+	Value         *float64
+	Status        CommandStatus
+	Result        *string
+	ExpiresAt     time.Time
+	DeliveredDate *time.Time
+	AckedDate     *time.Time
+	CreatedDate   time.Time
+	UpdatedDate   time.Time
+}
+
+type CommandStatus string
+
+const (
+	CommandPending   CommandStatus = "PENDING"
+	CommandDelivered CommandStatus = "DELIVERED"
+	CommandAcked     CommandStatus = "ACKED"
+	CommandFailed    CommandStatus = "FAILED"
+	CommandExpired   CommandStatus = "EXPIRED"
+)
@@ -17,7 +17,56 @@ type Device struct {
 	Category         string
 	Units            *string
 	PowerConsumption *float64
+	Placement        *Placement
+	Status           DeviceStatus
+	LastSeenDate     *time.Time
 	CreatedDate      time.Time
 	UpdatedDate      time.Time
 	DeletedDate      *time.Time
 }
+
+// DeviceFilters narrows down the devices of an organization, RoomId
+// limits them to a single room and SerialNumber is matched as a prefix.
+// Deleted lists the deleted devices instead, those deleted after
+// DeletedSince when it is set.
+type DeviceFilters struct {
+	OrganizationId uint64
+	RoomId         *uint64
+	Category       string
+	SerialNumber   string
+	Status         DeviceStatus
+	Deleted        bool
+	DeletedSince   *time.Time
+}
+
+type DeviceStatus string
+
+const (
+	DeviceOnline  DeviceStatus = "ONLINE"
+	DeviceStale   DeviceStatus = "STALE"
+	DeviceOffline DeviceStatus = "OFFLINE"
+)
+
+// Placement locates a device relative to the origin of its room, in meters.
+// AnchorId refers to an anchor the AR client resolved the room origin from.
+type Placement struct {
+	X           float64
+	Y           float64
+	Z           float64
+	Orientation Quaternion
+	AnchorId    *string
+}
+
+type Quaternion struct {
+	X float64
+	Y float64
+	Z float64
+	W float64
+}
+
+// DeviceLocation is what a scanned device label resolves to.
+type DeviceLocation struct {
+	Device       Device
+	Room         *Room
+	Organization Organization
+}
@@ -0,0 +1,12 @@
+package domain
+
+import "time"
+
+type DeviceToken struct {
+	Id          uint64
+	DeviceId    uint64
+	UserId      uint64
+	TokenHash   string
+	CreatedDate time.Time
+	RevokedDate *time.Time
+}
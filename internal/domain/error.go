@@ -0,0 +1,51 @@
+package domain
+
+// ErrorKind groups failures by what the caller can do about them,
+// the HTTP layer answers every kind with its own status code.
+type ErrorKind string
+
+const (
+	NotFoundError        ErrorKind = "not_found"
+	ForbiddenError       ErrorKind = "forbidden"
+	ConflictError        ErrorKind = "conflict"
+	ValidationError      ErrorKind = "validation"
+	UnauthorizedError    ErrorKind = "unauthorized"
+	TooManyRequestsError ErrorKind = "too_many_requests"
+)
+
+// Error is a failure the client is expected to handle. Code identifies
+// it for API clients and stays the same when Message is reworded, Field
+// names the request field at fault when there is one.
+type Error struct {
+	Kind    ErrorKind
+	Code    string
+	Message string
+	Field   string
+}
+
+func NewError(kind ErrorKind, code, message string) *Error {
+	return &Error{
+		Kind:    kind,
+		Code:    code,
+		Message: message,
+	}
+}
+
+func NewFieldError(kind ErrorKind, code, field, message string) *Error {
+	return &Error{
+		Kind:    kind,
+		Code:    code,
+		Message: message,
+		Field:   field,
+	}
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+var (
+	ErrNotFound     = NewError(NotFoundError, "not_found", "record not found")
+	ErrAccessDenied = NewError(ForbiddenError, "access_denied", "access denied")
+	ErrEmailTaken   = NewFieldError(ConflictError, "email_taken", "email", "email is already used by another account")
+)
@@ -0,0 +1,41 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Event tells about a change within an organization. PrevRoomId is set
+// when a device leaves a room, RoomId is the room it is in afterwards.
+type Event struct {
+	Type           EventType
+	OrganizationId uint64
+	RoomId         *uint64
+	PrevRoomId     *uint64
+	DeviceGUID     *uuid.UUID
+	Data           interface{}
+	Date           time.Time
+}
+
+type EventType string
+
+const (
+	OrganizationCreated  EventType = "organization.created"
+	OrganizationUpdated  EventType = "organization.updated"
+	OrganizationDeleted  EventType = "organization.deleted"
+	OrganizationRestored EventType = "organization.restored"
+	RoomCreated          EventType = "room.created"
+	RoomUpdated          EventType = "room.updated"
+	RoomDeleted          EventType = "room.deleted"
+	RoomRestored         EventType = "room.restored"
+	DeviceCreated        EventType = "device.created"
+	DeviceUpdated        EventType = "device.updated"
+	DeviceDeleted        EventType = "device.deleted"
+	DeviceRestored       EventType = "device.restored"
+	DevicePresence       EventType = "device.presence"      // status changes only, never sent to webhooks
+	DeviceTokenRevoked   EventType = "device.token_revoked" // never sent to webhooks, ends the device's MQTT connections
+	CommandCreated       EventType = "command.created"
+	AlertOpened          EventType = "alert.opened"
+	AlertClosed          EventType = "alert.closed"
+)
@@ -0,0 +1,24 @@
+package domain
+
+import "time"
+
+// Invitation offers a role in an organization to whoever owns the email,
+// the token itself is only ever sent in the invitation message.
+type Invitation struct {
+	Id             uint64
+	OrganizationId uint64
+	Email          string
+	Role           OrganizationRole
+	InvitedBy      uint64
+	TokenHash      string
+	ExpiresDate    time.Time
+	AcceptedDate   *time.Time
+	AcceptedBy     *uint64
+	RevokedDate    *time.Time
+	CreatedDate    time.Time
+	UpdatedDate    time.Time
+}
+
+func (i Invitation) Pending(now time.Time) bool {
+	return i.AcceptedDate == nil && i.RevokedDate == nil && now.Before(i.ExpiresDate)
+}
@@ -0,0 +1,11 @@
+package domain
+
+import "time"
+
+type Measurement struct {
+	Id          uint64
+	DeviceId    uint64
+	Value       float64
+	MeasuredAt  time.Time
+	CreatedDate time.Time
+}
@@ -16,3 +16,14 @@ type Organization struct {
 	UpdatedDate time.Time
 	DeletedDate *time.Time
 }
+
+// DeviceDeletion tells what happens to the devices of a deleted room,
+// those of a deleted organization are always deleted along.
+type DeviceDeletion string
+
+const (
+	// CascadeDevices deletes the devices along, restoring brings them back.
+	CascadeDevices DeviceDeletion = "CASCADE"
+	// UnassignDevices keeps the devices, only takes them out of their rooms.
+	UnassignDevices DeviceDeletion = "UNASSIGN"
+)
@@ -0,0 +1,76 @@
+package domain
+
+import "time"
+
+// OrganizationMember grants a user a role within a single organization,
+// the user who creates an organization becomes its first owner.
+type OrganizationMember struct {
+	Id             uint64
+	OrganizationId uint64
+	UserId         uint64
+	Role           OrganizationRole
+	User           *User
+	CreatedDate    time.Time
+	UpdatedDate    time.Time
+}
+
+// MemberFilters narrows down the members of an organization,
+// Search matches the email and both names of the user.
+type MemberFilters struct {
+	OrganizationId uint64
+	Role           OrganizationRole
+	Search         string
+}
+
+type OrganizationRole string
+
+const (
+	OrganizationOwner      OrganizationRole = "owner"
+	OrganizationManager    OrganizationRole = "manager"
+	OrganizationTechnician OrganizationRole = "technician"
+	OrganizationViewer     OrganizationRole = "viewer"
+)
+
+// Permission is an action within an organization, each one needs
+// at least the role it is mapped to in permissionRoles.
+type Permission string
+
+const (
+	// ViewPermission covers reading anything of the organization.
+	ViewPermission Permission = "view"
+	// OperatePermission covers day-to-day work on existing devices:
+	// commands, moves between rooms, placement and alert acknowledgement.
+	OperatePermission Permission = "operate"
+	// ManagePermission covers creating and removing rooms and devices,
+	// alert rules, webhooks, device tokens and non-owner members.
+	ManagePermission Permission = "manage"
+	// OwnPermission covers the organization itself and its owners.
+	OwnPermission Permission = "own"
+)
+
+var roleRanks = map[OrganizationRole]int{
+	OrganizationViewer:     1,
+	OrganizationTechnician: 2,
+	OrganizationManager:    3,
+	OrganizationOwner:      4,
+}
+
+var permissionRoles = map[Permission]OrganizationRole{
+	ViewPermission:    OrganizationViewer,
+	OperatePermission: OrganizationTechnician,
+	ManagePermission:  OrganizationManager,
+	OwnPermission:     OrganizationOwner,
+}
+
+func (r OrganizationRole) Can(p Permission) bool {
+	min, ok := permissionRoles[p]
+	if !ok {
+		return false
+	}
+	return roleRanks[r] >= roleRanks[min]
+}
+
+// Outranks tells whether r is strictly higher than o.
+func (r OrganizationRole) Outranks(o OrganizationRole) bool {
+	return roleRanks[r] > roleRanks[o]
+}
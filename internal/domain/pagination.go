@@ -3,4 +3,15 @@ package domain
 type Pagination struct {
 	Page         uint64
 	CountPerPage uint64
+	// Sort names a field the way the API spells it, e.g. "createdDate",
+	// lists keep their default order for fields they can not be sorted by
+	Sort string
+	Desc bool
+}
+
+// Page is a single page of items out of Total.
+type Page[T any] struct {
+	Items []T
+	Total uint64
+	Pages uint
 }
@@ -0,0 +1,18 @@
+package domain
+
+import "time"
+
+type PasswordReset struct {
+	Id          uint64
+	UserId      uint64
+	TokenHash   string
+	ExpiresDate time.Time
+	UsedDate    *time.Time
+	CreatedDate time.Time
+}
+
+// PasswordChange is a reset token together with the password it sets.
+type PasswordChange struct {
+	Token       string
+	NewPassword string
+}
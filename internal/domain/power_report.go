@@ -0,0 +1,18 @@
+package domain
+
+type RoomPowerReport struct {
+	Room             Room
+	TotalConsumption float64
+	ByCategory       map[string]float64
+	DeviceCount      uint64
+	Overloaded       bool
+}
+
+type OrganizationPowerReport struct {
+	Organization     Organization
+	TotalConsumption float64
+	ByCategory       map[string]float64
+	Unassigned       float64
+	Rooms            []RoomPowerReport
+	OverloadedRooms  []uint64
+}
@@ -7,7 +7,28 @@ type Room struct {
 	Name           string
 	OrganizationId uint64
 	Description    string
+	PowerCapacity  *float64
+	FloorPlan      *string
+	Model          *string
 	CreatedDate    time.Time
 	UpdatedDate    time.Time
 	DeletedDate    *time.Time
 }
+
+// RoomFilters narrows down the rooms of an organization,
+// Search matches the name. Deleted lists the deleted rooms instead,
+// those deleted after DeletedSince when it is set.
+type RoomFilters struct {
+	OrganizationId uint64
+	Search         string
+	Deleted        bool
+	DeletedSince   *time.Time
+}
+
+// RoomAsset is a file attached to a room, kept under the file storage.
+type RoomAsset string
+
+const (
+	RoomFloorPlan RoomAsset = "floor-plan"
+	RoomModel     RoomAsset = "model"
+)
@@ -1,10 +1,33 @@
 package domain
 
 import (
+	"time"
+
 	"github.com/google/uuid"
 )
 
+// Session is a single login. Every refresh token issued for it belongs
+// to the same family, only the hash of the latest one is kept.
 type Session struct {
-	UserId uint64
-	UUID   uuid.UUID
+	UserId             uint64
+	UUID               uuid.UUID
+	RefreshHash        string
+	RefreshExpiresDate *time.Time
+	UserAgent          string
+	Ip                 string
+	CreatedDate        time.Time
+	LastUsedDate       time.Time
+}
+
+// Client describes where a login or a refresh comes from.
+type Client struct {
+	UserAgent string
+	Ip        string
+}
+
+type AuthTokens struct {
+	AccessToken        string
+	AccessExpiresDate  time.Time
+	RefreshToken       string
+	RefreshExpiresDate time.Time
 }
@@ -5,15 +5,25 @@ import (
 )
 
 type User struct {
-	Id          uint64
-	Email       string
-	Password    string
-	FirstName   string
-	SecondName  string
-	Role        Role
-	CreatedDate time.Time
-	UpdatedDate time.Time
-	DeletedDate *time.Time
+	Id           uint64
+	Email        string
+	Password     string
+	FirstName    string
+	SecondName   string
+	Role         Role
+	VerifiedDate *time.Time
+	BlockedDate  *time.Time
+	CreatedDate  time.Time
+	UpdatedDate  time.Time
+	DeletedDate  *time.Time
+}
+
+// UserFilters narrows down the users an administrator looks through,
+// Search matches the email and both names.
+type UserFilters struct {
+	Search  string
+	Role    Role
+	Deleted bool
 }
 
 type Role string
@@ -23,6 +33,13 @@ const (
 	CustomerRole Role = "CUSTOMER"
 )
 
+// Registration is a new user, optionally joining an organization
+// through an invitation at the same time.
+type Registration struct {
+	User            User
+	InvitationToken string
+}
+
 type ChangePassword struct {
 	OldPassword string
 	NewPassword string
@@ -31,3 +48,11 @@ type ChangePassword struct {
 func (u User) GetUserId() uint64 {
 	return u.Id
 }
+
+func (u User) Verified() bool {
+	return u.VerifiedDate != nil
+}
+
+func (u User) Blocked() bool {
+	return u.BlockedDate != nil
+}
@@ -0,0 +1,59 @@
+package domain
+
+import "time"
+
+// Webhook receives organization events as signed JSON,
+// an empty Events list means every event type but DevicePresence and
+// DeviceTokenRevoked.
+type Webhook struct {
+	Id             uint64
+	OrganizationId uint64
+	UserId         uint64
+	Url            string
+	Secret         string
+	Events         []EventType
+	Enabled        bool
+	CreatedDate    time.Time
+	UpdatedDate    time.Time
+	DeletedDate    *time.Time
+}
+
+func (w Webhook) Accepts(t EventType) bool {
+	if t == DevicePresence || t == DeviceTokenRevoked {
+		return false
+	}
+	if len(w.Events) == 0 {
+		return true
+	}
+	for _, e := range w.Events {
+		if e == t {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookDelivery is a single event sent to a single webhook,
+// it doubles as the delivery log entry.
+type WebhookDelivery struct {
+	Id              uint64
+	WebhookId       uint64
+	EventType       EventType
+	Payload         string
+	Status          DeliveryStatus
+	Attempts        int
+	ResponseCode    *int
+	Error           *string
+	NextAttemptDate *time.Time
+	DeliveredDate   *time.Time
+	CreatedDate     time.Time
+	UpdatedDate     time.Time
+}
+
+type DeliveryStatus string
+
+const (
+	DeliveryPending   DeliveryStatus = "PENDING"
+	DeliverySucceeded DeliveryStatus = "SUCCEEDED"
+	DeliveryFailed    DeliveryStatus = "FAILED"
+)
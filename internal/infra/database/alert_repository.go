@@ -0,0 +1,248 @@
+package database
+
+import (
+	"time"
+
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+	"github.com/upper/db/v4"
+)
+
+const AlertsTableName = "alerts"
+
+type alert struct {
+	Id               uint64             `db:"id,omitempty"`
+	RuleId           uint64             `db:"rule_id"`
+	OrganizationId   uint64             `db:"organization_id"`
+	DeviceId         uint64             `db:"device_id"`
+	RoomId           *uint64            `db:"room_id"`
+	Status           domain.AlertStatus `db:"status"`
+	Value            float64            `db:"value"`
+	StartedDate      time.Time          `db:"started_date"`
+	OpenedDate       *time.Time         `db:"opened_date"`
+	ResolvedDate     *time.Time         `db:"resolved_date"`
+	AcknowledgedDate *time.Time         `db:"acknowledged_date"`
+	AcknowledgedBy   *uint64            `db:"acknowledged_by"`
+	CreatedDate      time.Time          `db:"created_date"`
+	UpdatedDate      time.Time          `db:"updated_date"`
+}
+
+// ErrAlertActive is returned by Save when the rule already has a pending
+// or open alert for the device.
+var ErrAlertActive = domain.NewError(domain.ConflictError, "alert_active", "alert of the rule is already active for the device")
+
+var alertConflicts = map[string]*domain.Error{
+	"alerts_rule_id_device_id_active_idx": ErrAlertActive,
+}
+
+var alertSortColumns = map[string]string{
+	"id":          "id",
+	"status":      "status",
+	"value":       "value",
+	"startedDate": "started_date",
+	"openedDate":  "opened_date",
+}
+
+type AlertRepository interface {
+	Save(a domain.Alert) (domain.Alert, error)
+	Advance(a domain.Alert, from domain.AlertStatus) (domain.Alert, error)
+	Acknowledge(id, uId uint64) (domain.Alert, error)
+	FindById(id uint64) (domain.Alert, error)
+	FindForOrganization(oId uint64, p domain.Pagination, statuses ...domain.AlertStatus) (domain.Page[domain.Alert], error)
+	FindActive(ruleId, deviceId uint64) (*domain.Alert, error)
+	ResolveForRule(ruleId uint64) error
+	DeletePending(id uint64) error
+}
+
+type alertRepository struct {
+	coll db.Collection
+	sess db.Session
+}
+
+func NewAlertRepository(dbSession db.Session) AlertRepository {
+	return alertRepository{
+		coll: dbSession.Collection(AlertsTableName),
+		sess: dbSession,
+	}
+}
+
+func (r alertRepository) Save(a domain.Alert) (domain.Alert, error) {
+	alt := r.mapDomainToModel(a)
+	alt.CreatedDate, alt.UpdatedDate = time.Now(), time.Now()
+	err := r.coll.InsertReturning(&alt)
+	if err != nil {
+		return domain.Alert{}, uniqueViolation(err, alertConflicts)
+	}
+	a = r.mapModelToDomain(alt)
+	return a, nil
+}
+
+// Advance writes the evaluation state of the alert only while the stored
+// one still has the status from, so racing evaluations can not open or
+// resolve it twice or move it back. domain.ErrNotFound is returned when
+// nothing was updated.
+func (r alertRepository) Advance(a domain.Alert, from domain.AlertStatus) (domain.Alert, error) {
+	return r.updateWhere(db.Cond{"id": a.Id, "status": from}, map[string]interface{}{
+		"status":        a.Status,
+		"value":         a.Value,
+		"opened_date":   a.OpenedDate,
+		"resolved_date": a.ResolvedDate,
+		"updated_date":  time.Now(),
+	}, a.Id)
+}
+
+// Acknowledge records who acknowledged the alert unless someone did it
+// already. domain.ErrNotFound is returned when nothing was updated.
+func (r alertRepository) Acknowledge(id, uId uint64) (domain.Alert, error) {
+	now := time.Now()
+	return r.updateWhere(db.Cond{"id": id, "acknowledged_date": nil}, map[string]interface{}{
+		"acknowledged_date": now,
+		"acknowledged_by":   uId,
+		"updated_date":      now,
+	}, id)
+}
+
+func (r alertRepository) updateWhere(cond db.Cond, set map[string]interface{}, id uint64) (domain.Alert, error) {
+	res, err := r.sess.SQL().
+		Update(AlertsTableName).
+		Set(set).
+		Where(cond).
+		Exec()
+	if err != nil {
+		return domain.Alert{}, err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return domain.Alert{}, err
+	}
+	if n == 0 {
+		return domain.Alert{}, domain.ErrNotFound
+	}
+	return r.FindById(id)
+}
+
+func (r alertRepository) FindById(id uint64) (domain.Alert, error) {
+	var alt alert
+	err := r.coll.Find(db.Cond{"id": id}).One(&alt)
+	if err != nil {
+		return domain.Alert{}, notFound(err)
+	}
+	a := r.mapModelToDomain(alt)
+	return a, nil
+}
+
+func (r alertRepository) FindForOrganization(oId uint64, p domain.Pagination, statuses ...domain.AlertStatus) (domain.Page[domain.Alert], error) {
+	cond := db.Cond{"organization_id": oId}
+	if len(statuses) > 0 {
+		cond["status IN"] = statuses
+	}
+
+	order, err := orderBy(p, alertSortColumns, "-started_date", "id")
+	if err != nil {
+		return domain.Page[domain.Alert]{}, err
+	}
+
+	var alts []alert
+	res := r.coll.Find(cond).OrderBy(order...)
+	total, pages, err := paginate(res, p, &alts)
+	if err != nil {
+		return domain.Page[domain.Alert]{}, err
+	}
+	return domain.Page[domain.Alert]{
+		Items: r.mapModelToDomainCollection(alts),
+		Total: total,
+		Pages: pages,
+	}, nil
+}
+
+// FindActive returns the pending or open alert of the rule for the device,
+// nil when the condition is not currently met.
+func (r alertRepository) FindActive(ruleId, deviceId uint64) (*domain.Alert, error) {
+	var alts []alert
+	err := r.coll.
+		Find(db.Cond{
+			"rule_id":   ruleId,
+			"device_id": deviceId,
+			"status IN": []domain.AlertStatus{domain.AlertPending, domain.AlertOpen},
+		}).
+		OrderBy("-id").
+		Limit(1).
+		All(&alts)
+	if err != nil {
+		return nil, err
+	}
+	if len(alts) == 0 {
+		return nil, nil
+	}
+	a := r.mapModelToDomain(alts[0])
+	return &a, nil
+}
+
+// ResolveForRule closes open alerts of a rule and drops the pending ones.
+func (r alertRepository) ResolveForRule(ruleId uint64) error {
+	err := r.coll.Find(db.Cond{"rule_id": ruleId, "status": domain.AlertPending}).Delete()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	return r.coll.
+		Find(db.Cond{"rule_id": ruleId, "status": domain.AlertOpen}).
+		Update(map[string]interface{}{
+			"status":        domain.AlertResolved,
+			"resolved_date": now,
+			"updated_date":  now,
+		})
+}
+
+// DeletePending drops the alert unless it has been opened meanwhile.
+func (r alertRepository) DeletePending(id uint64) error {
+	return r.coll.Find(db.Cond{"id": id, "status": domain.AlertPending}).Delete()
+}
+
+func (r alertRepository) mapDomainToModel(d domain.Alert) alert {
+	return alert{
+		Id:               d.Id,
+		RuleId:           d.RuleId,
+		OrganizationId:   d.OrganizationId,
+		DeviceId:         d.DeviceId,
+		RoomId:           d.RoomId,
+		Status:           d.Status,
+		Value:            d.Value,
+		StartedDate:      d.StartedDate,
+		OpenedDate:       d.OpenedDate,
+		ResolvedDate:     d.ResolvedDate,
+		AcknowledgedDate: d.AcknowledgedDate,
+		AcknowledgedBy:   d.AcknowledgedBy,
+		CreatedDate:      d.CreatedDate,
+		UpdatedDate:      d.UpdatedDate,
+	}
+}
+
+func (r alertRepository) mapModelToDomain(d alert) domain.Alert {
+	return domain.Alert{
+		Id:               d.Id,
+		RuleId:           d.RuleId,
+		OrganizationId:   d.OrganizationId,
+		DeviceId:         d.DeviceId,
+		RoomId:           d.RoomId,
+		Status:           d.Status,
+		Value:            d.Value,
+		StartedDate:      d.StartedDate,
+		OpenedDate:       d.OpenedDate,
+		ResolvedDate:     d.ResolvedDate,
+		AcknowledgedDate: d.AcknowledgedDate,
+		AcknowledgedBy:   d.AcknowledgedBy,
+		CreatedDate:      d.CreatedDate,
+		UpdatedDate:      d.UpdatedDate,
+	}
+}
+
+func (r alertRepository) mapModelToDomainCollection(alts []alert) []domain.Alert {
+	var alerts []domain.Alert
+	for _, a := range alts {
+		alt := r.mapModelToDomain(a)
+		alerts = append(alerts, alt)
+	}
+	return alerts
+}
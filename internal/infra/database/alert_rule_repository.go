@@ -0,0 +1,175 @@
+package database
+
+import (
+	"time"
+
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+	"github.com/upper/db/v4"
+)
+
+const AlertRulesTableName = "alert_rules"
+
+type alertRule struct {
+	Id             uint64               `db:"id,omitempty"`
+	OrganizationId uint64               `db:"organization_id"`
+	DeviceId       *uint64              `db:"device_id"`
+	RoomId         *uint64              `db:"room_id"`
+	UserId         uint64               `db:"user_id"`
+	Name           string               `db:"name"`
+	Units          *string              `db:"units"`
+	Operator       domain.AlertOperator `db:"operator"`
+	Threshold      float64              `db:"threshold"`
+	Duration       int64                `db:"duration"`
+	Enabled        bool                 `db:"enabled"`
+	CreatedDate    time.Time            `db:"created_date"`
+	UpdatedDate    time.Time            `db:"updated_date"`
+	DeletedDate    *time.Time           `db:"deleted_date"`
+}
+
+var alertRuleSortColumns = map[string]string{
+	"id":          "id",
+	"name":        "name",
+	"createdDate": "created_date",
+	"updatedDate": "updated_date",
+}
+
+type AlertRuleRepository interface {
+	Save(ar domain.AlertRule) (domain.AlertRule, error)
+	Update(ar domain.AlertRule) (domain.AlertRule, error)
+	FindById(id uint64) (domain.AlertRule, error)
+	FindForOrganization(oId uint64, p domain.Pagination) (domain.Page[domain.AlertRule], error)
+	FindForDevice(dv domain.Device) ([]domain.AlertRule, error)
+	Delete(id uint64) error
+}
+
+type alertRuleRepository struct {
+	coll db.Collection
+	sess db.Session
+}
+
+func NewAlertRuleRepository(dbSession db.Session) AlertRuleRepository {
+	return alertRuleRepository{
+		coll: dbSession.Collection(AlertRulesTableName),
+		sess: dbSession,
+	}
+}
+
+func (r alertRuleRepository) Save(ar domain.AlertRule) (domain.AlertRule, error) {
+	rule := r.mapDomainToModel(ar)
+	rule.CreatedDate, rule.UpdatedDate = time.Now(), time.Now()
+	err := r.coll.InsertReturning(&rule)
+	if err != nil {
+		return domain.AlertRule{}, err
+	}
+	ar = r.mapModelToDomain(rule)
+	return ar, nil
+}
+
+func (r alertRuleRepository) Update(ar domain.AlertRule) (domain.AlertRule, error) {
+	rule := r.mapDomainToModel(ar)
+	rule.UpdatedDate = time.Now()
+	err := r.coll.Find(db.Cond{"id": rule.Id, "deleted_date": nil}).Update(&rule)
+	if err != nil {
+		return domain.AlertRule{}, err
+	}
+	ar = r.mapModelToDomain(rule)
+	return ar, nil
+}
+
+func (r alertRuleRepository) FindById(id uint64) (domain.AlertRule, error) {
+	var rule alertRule
+	err := r.coll.Find(db.Cond{"id": id, "deleted_date": nil}).One(&rule)
+	if err != nil {
+		return domain.AlertRule{}, notFound(err)
+	}
+	ar := r.mapModelToDomain(rule)
+	return ar, nil
+}
+
+func (r alertRuleRepository) FindForOrganization(oId uint64, p domain.Pagination) (domain.Page[domain.AlertRule], error) {
+	order, err := orderBy(p, alertRuleSortColumns, "id", "id")
+	if err != nil {
+		return domain.Page[domain.AlertRule]{}, err
+	}
+
+	var rules []alertRule
+	res := r.coll.Find(db.Cond{"organization_id": oId, "deleted_date": nil}).
+		OrderBy(order...)
+	total, pages, err := paginate(res, p, &rules)
+	if err != nil {
+		return domain.Page[domain.AlertRule]{}, err
+	}
+	return domain.Page[domain.AlertRule]{
+		Items: r.mapModelToDomainCollection(rules),
+		Total: total,
+		Pages: pages,
+	}, nil
+}
+
+// FindForDevice returns enabled rules set on the device itself
+// or on the room it currently stands in.
+func (r alertRuleRepository) FindForDevice(dv domain.Device) ([]domain.AlertRule, error) {
+	target := db.Or(db.Cond{"device_id": dv.Id})
+	if dv.RoomId != nil {
+		target = target.Or(db.Cond{"room_id": *dv.RoomId})
+	}
+
+	var rules []alertRule
+	err := r.coll.Find(db.Cond{"enabled": true, "deleted_date": nil}, target).All(&rules)
+	if err != nil {
+		return nil, err
+	}
+	res := r.mapModelToDomainCollection(rules)
+	return res, nil
+}
+
+func (r alertRuleRepository) Delete(id uint64) error {
+	return r.coll.Find(db.Cond{"id": id, "deleted_date": nil}).Update(map[string]interface{}{"deleted_date": time.Now()})
+}
+
+func (r alertRuleRepository) mapDomainToModel(d domain.AlertRule) alertRule {
+	return alertRule{
+		Id:             d.Id,
+		OrganizationId: d.OrganizationId,
+		DeviceId:       d.DeviceId,
+		RoomId:         d.RoomId,
+		UserId:         d.UserId,
+		Name:           d.Name,
+		Units:          d.Units,
+		Operator:       d.Operator,
+		Threshold:      d.Threshold,
+		Duration:       int64(d.Duration / time.Second),
+		Enabled:        d.Enabled,
+		CreatedDate:    d.CreatedDate,
+		UpdatedDate:    d.UpdatedDate,
+		DeletedDate:    d.DeletedDate,
+	}
+}
+
+func (r alertRuleRepository) mapModelToDomain(d alertRule) domain.AlertRule {
+	return domain.AlertRule{
+		Id:             d.Id,
+		OrganizationId: d.OrganizationId,
+		DeviceId:       d.DeviceId,
+		RoomId:         d.RoomId,
+		UserId:         d.UserId,
+		Name:           d.Name,
+		Units:          d.Units,
+		Operator:       d.Operator,
+		Threshold:      d.Threshold,
+		Duration:       time.Duration(d.Duration) * time.Second,
+		Enabled:        d.Enabled,
+		CreatedDate:    d.CreatedDate,
+		UpdatedDate:    d.UpdatedDate,
+		DeletedDate:    d.DeletedDate,
+	}
+}
+
+func (r alertRuleRepository) mapModelToDomainCollection(rules []alertRule) []domain.AlertRule {
+	var res []domain.AlertRule
+	for _, ar := range rules {
+		rule := r.mapModelToDomain(ar)
+		res = append(res, rule)
+	}
+	return res
+}
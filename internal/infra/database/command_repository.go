@@ -0,0 +1,221 @@
+package database
+
+import (
+	"time"
+
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+	"github.com/upper/db/v4"
+)
+
+const CommandsTableName = "commands"
+
+type command struct {
+	Id            uint64               `db:"id,omitempty"`
+	DeviceId      uint64               `db:"device_id"`
+	UserId        uint64               `db:"user_id"`
+	Action        string               `db:"action"`
+	Value         *float64             `db:"value"`
+	Status        domain.CommandStatus `db:"status"`
+	Result        *string              `db:"result"`
+	ExpiresAt     time.Time            `db:"expires_at"`
+	DeliveredDate *time.Time           `db:"delivered_date"`
+	AckedDate     *time.Time           `db:"acked_date"`
+	CreatedDate   time.Time            `db:"created_date"`
+	UpdatedDate   time.Time            `db:"updated_date"`
+}
+
+var commandSortColumns = map[string]string{
+	"id":          "id",
+	"action":      "action",
+	"status":      "status",
+	"createdDate": "created_date",
+}
+
+type CommandRepository interface {
+	Save(c domain.Command) (domain.Command, error)
+	FindById(id uint64) (domain.Command, error)
+	FindForDevice(dId uint64, statuses ...domain.CommandStatus) ([]domain.Command, error)
+	FindAll(dId uint64, p domain.Pagination, statuses ...domain.CommandStatus) (domain.Page[domain.Command], error)
+	Deliver(id uint64) (domain.Command, error)
+	Ack(id uint64, status domain.CommandStatus, result *string) (domain.Command, error)
+	ExpireOverdue(dId uint64) error
+}
+
+type commandRepository struct {
+	coll db.Collection
+	sess db.Session
+}
+
+func NewCommandRepository(dbSession db.Session) CommandRepository {
+	return commandRepository{
+		coll: dbSession.Collection(CommandsTableName),
+		sess: dbSession,
+	}
+}
+
+func (r commandRepository) Save(c domain.Command) (domain.Command, error) {
+	cmd := r.mapDomainToModel(c)
+	cmd.CreatedDate, cmd.UpdatedDate = time.Now(), time.Now()
+	err := r.coll.InsertReturning(&cmd)
+	if err != nil {
+		return domain.Command{}, err
+	}
+	c = r.mapModelToDomain(cmd)
+	return c, nil
+}
+
+func (r commandRepository) FindById(id uint64) (domain.Command, error) {
+	var cmd command
+	err := r.coll.Find(db.Cond{"id": id}).One(&cmd)
+	if err != nil {
+		return domain.Command{}, notFound(err)
+	}
+	c := r.mapModelToDomain(cmd)
+	return c, nil
+}
+
+func (r commandRepository) FindForDevice(dId uint64, statuses ...domain.CommandStatus) ([]domain.Command, error) {
+	cond := db.Cond{"device_id": dId}
+	if len(statuses) > 0 {
+		cond["status IN"] = statuses
+	}
+
+	var cmds []command
+	err := r.coll.Find(cond).OrderBy("created_date").All(&cmds)
+	if err != nil {
+		return nil, err
+	}
+	res := r.mapModelToDomainCollection(cmds)
+	return res, nil
+}
+
+func (r commandRepository) FindAll(dId uint64, p domain.Pagination, statuses ...domain.CommandStatus) (domain.Page[domain.Command], error) {
+	cond := db.Cond{"device_id": dId}
+	if len(statuses) > 0 {
+		cond["status IN"] = statuses
+	}
+
+	order, err := orderBy(p, commandSortColumns, "created_date", "id")
+	if err != nil {
+		return domain.Page[domain.Command]{}, err
+	}
+
+	var cmds []command
+	res := r.coll.Find(cond).OrderBy(order...)
+	total, pages, err := paginate(res, p, &cmds)
+	if err != nil {
+		return domain.Page[domain.Command]{}, err
+	}
+	return domain.Page[domain.Command]{
+		Items: r.mapModelToDomainCollection(cmds),
+		Total: total,
+		Pages: pages,
+	}, nil
+}
+
+// Deliver marks the command as delivered only while it is still pending
+// and not expired, so racing polls can not hand it out twice.
+// domain.ErrNotFound is returned when nothing was updated.
+func (r commandRepository) Deliver(id uint64) (domain.Command, error) {
+	now := time.Now()
+	return r.updateOpen(id, []domain.CommandStatus{domain.CommandPending}, map[string]interface{}{
+		"status":         domain.CommandDelivered,
+		"delivered_date": now,
+		"updated_date":   now,
+	})
+}
+
+// Ack stores the device's result only while the command is pending or
+// delivered and not expired, so a late poll or expiry can not overwrite
+// it and it can not be acknowledged twice. domain.ErrNotFound is returned
+// when nothing was updated.
+func (r commandRepository) Ack(id uint64, status domain.CommandStatus, result *string) (domain.Command, error) {
+	now := time.Now()
+	return r.updateOpen(id, []domain.CommandStatus{domain.CommandPending, domain.CommandDelivered}, map[string]interface{}{
+		"status":         status,
+		"result":         result,
+		"delivered_date": db.Raw("COALESCE(delivered_date, ?)", now),
+		"acked_date":     now,
+		"updated_date":   now,
+	})
+}
+
+func (r commandRepository) updateOpen(id uint64, from []domain.CommandStatus, set map[string]interface{}) (domain.Command, error) {
+	res, err := r.sess.SQL().
+		Update(CommandsTableName).
+		Set(set).
+		Where(db.Cond{
+			"id":           id,
+			"status IN":    from,
+			"expires_at >": time.Now(),
+		}).
+		Exec()
+	if err != nil {
+		return domain.Command{}, err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return domain.Command{}, err
+	}
+	if n == 0 {
+		return domain.Command{}, domain.ErrNotFound
+	}
+	return r.FindById(id)
+}
+
+func (r commandRepository) ExpireOverdue(dId uint64) error {
+	return r.coll.
+		Find(db.Cond{
+			"device_id":    dId,
+			"status IN":    []domain.CommandStatus{domain.CommandPending, domain.CommandDelivered},
+			"expires_at <": time.Now(),
+		}).
+		Update(map[string]interface{}{
+			"status":       domain.CommandExpired,
+			"updated_date": time.Now(),
+		})
+}
+
+func (r commandRepository) mapDomainToModel(d domain.Command) command {
+	return command{
+		Id:            d.Id,
+		DeviceId:      d.DeviceId,
+		UserId:        d.UserId,
+		Action:        d.Action,
+		Value:         d.Value,
+		Status:        d.Status,
+		Result:        d.Result,
+		ExpiresAt:     d.ExpiresAt,
+		DeliveredDate: d.DeliveredDate,
+		AckedDate:     d.AckedDate,
+		CreatedDate:   d.CreatedDate,
+		UpdatedDate:   d.UpdatedDate,
+	}
+}
+
+func (r commandRepository) mapModelToDomain(d command) domain.Command {
+	return domain.Command{
+		Id:            d.Id,
+		DeviceId:      d.DeviceId,
+		UserId:        d.UserId,
+		Action:        d.Action,
+		Value:         d.Value,
+		Status:        d.Status,
+		Result:        d.Result,
+		ExpiresAt:     d.ExpiresAt,
+		DeliveredDate: d.DeliveredDate,
+		AckedDate:     d.AckedDate,
+		CreatedDate:   d.CreatedDate,
+		UpdatedDate:   d.UpdatedDate,
+	}
+}
+
+func (r commandRepository) mapModelToDomainCollection(cmds []command) []domain.Command {
+	var commands []domain.Command
+	for _, c := range cmds {
+		cmd := r.mapModelToDomain(c)
+		commands = append(commands, cmd)
+	}
+	return commands
+}
@@ -0,0 +1,23 @@
+package database
+
+import (
+	"errors"
+
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+	"github.com/jackc/pgconn"
+)
+
+const uniqueViolationCode = "23505"
+
+// uniqueViolation replaces a unique constraint violation with the conflict
+// known for that constraint, any other error is returned as it is.
+func uniqueViolation(err error, conflicts map[string]*domain.Error) error {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) || pgErr.Code != uniqueViolationCode {
+		return err
+	}
+	if c, ok := conflicts[pgErr.ConstraintName]; ok {
+		return c
+	}
+	return domain.NewError(domain.ConflictError, "already_exists", pgErr.Message)
+}
@@ -1,7 +1,6 @@
 package database
 
 import (
-	"errors"
 	"time"
 
 	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
@@ -19,29 +18,72 @@ const (
 )
 
 type device struct {
-	Id               uint64         `db:"id,omitempty"`
-	OrganizationId   uint64         `db:"organization_id"`
-	RoomId           *uint64        `db:"room_id,omitempty"`
-	GUID             uuid.UUID      `db:"guid"`
-	InventoryNumber  string         `db:"inventory_number"`
-	SerialNumber     string         `db:"serial_number"`
-	Characteristics  string         `db:"characteristics"`
-	Category         DeviceCategory `db:"device_category"`
-	Units            *string        `db:"units,omitempty"`
-	PowerConsumption *float64       `db:"power_consumption,omitempty"`
-	CreatedDate      time.Time      `db:"created_date"`
-	UpdatedDate      time.Time      `db:"updated_date"`
-	DeletedDate      *time.Time     `db:"deleted_date"`
+	Id               uint64              `db:"id,omitempty"`
+	OrganizationId   uint64              `db:"organization_id"`
+	RoomId           *uint64             `db:"room_id,omitempty"`
+	GUID             uuid.UUID           `db:"guid"`
+	InventoryNumber  string              `db:"inventory_number"`
+	SerialNumber     string              `db:"serial_number"`
+	Characteristics  string              `db:"characteristics"`
+	Category         DeviceCategory      `db:"device_category"`
+	Units            *string             `db:"units,omitempty"`
+	PowerConsumption *float64            `db:"power_consumption,omitempty"`
+	PosX             *float64            `db:"pos_x"`
+	PosY             *float64            `db:"pos_y"`
+	PosZ             *float64            `db:"pos_z"`
+	RotX             *float64            `db:"rot_x"`
+	RotY             *float64            `db:"rot_y"`
+	RotZ             *float64            `db:"rot_z"`
+	RotW             *float64            `db:"rot_w"`
+	AnchorId         *string             `db:"anchor_id"`
+	Status           domain.DeviceStatus `db:"status"`
+	LastSeenDate     *time.Time          `db:"last_seen_date"`
+	CreatedDate      time.Time           `db:"created_date"`
+	UpdatedDate      time.Time           `db:"updated_date"`
+	DeletedDate      *time.Time          `db:"deleted_date"`
+}
+
+var deviceSortColumns = map[string]string{
+	"id":              "id",
+	"inventoryNumber": "inventory_number",
+	"serialNumber":    "serial_number",
+	"category":        "device_category",
+	"status":          "status",
+	"lastSeenDate":    "last_seen_date",
+	"createdDate":     "created_date",
+	"updatedDate":     "updated_date",
+	"deletedDate":     "deleted_date",
+}
+
+// deviceConflicts names the request field behind every unique constraint.
+var deviceConflicts = map[string]*domain.Error{
+	"devices_guid_key":             domain.NewFieldError(domain.ConflictError, "guid_taken", "guid", "guid is already used by another device"),
+	"devices_inventory_number_key": domain.NewFieldError(domain.ConflictError, "inventory_number_taken", "inventoryNumber", "inventory number is already used by another device"),
+	"devices_serial_number_key":    domain.NewFieldError(domain.ConflictError, "serial_number_taken", "serialNumber", "serial number is already used by another device"),
 }
 
 type DeviceRepository interface {
 	Save(dv domain.Device) (domain.Device, error)
 	Update(dv domain.Device) (domain.Device, error)
 	FindForRoom(mId uint64) ([]domain.Device, error)
+	FindForOrganization(oId uint64) ([]domain.Device, error)
+	FindPlacedForRoom(mId uint64) ([]domain.Device, error)
+	FindAll(f domain.DeviceFilters, p domain.Pagination) (domain.Page[domain.Device], error)
 	FindById(id uint64) (domain.Device, error)
+	FindByGUID(guid uuid.UUID) (domain.Device, error)
+	InventoryNumberTaken(number string) (bool, error)
 	SetDeviceToRoom(deviceId, roomId uint64) error
 	RemoveDeviceFromRoom(deviceId uint64) error
+	Touch(id uint64, seen time.Time) error
+	FindSilent(before time.Time, limit int, statuses ...domain.DeviceStatus) ([]domain.Device, error)
+	SetStatus(ids []uint64, before time.Time, status domain.DeviceStatus, from ...domain.DeviceStatus) ([]uint64, error)
+	FindDeletedById(id uint64) (domain.Device, error)
 	Delete(id uint64) error
+	DeleteForRoom(mId uint64, at time.Time) error
+	DeleteForOrganization(oId uint64, at time.Time) error
+	Restore(id uint64) error
+	RestoreForRoom(mId uint64, deletedAt time.Time) error
+	RestoreForOrganization(oId uint64, deletedAt time.Time) error
 }
 
 type deviceRepository struct {
@@ -60,11 +102,11 @@ func (r deviceRepository) Save(dv domain.Device) (domain.Device, error) {
 	if err := validateDevice(dv); err != nil {
 		return domain.Device{}, err
 	}
-	dv.CreatedDate, dv.UpdatedDate = time.Now(), time.Now()
 	dev := r.mapDomainToModel(dv)
-	err := r.coll.InsertReturning(&dv)
+	dev.CreatedDate, dev.UpdatedDate = time.Now(), time.Now()
+	err := r.coll.InsertReturning(&dev)
 	if err != nil {
-		return domain.Device{}, err
+		return domain.Device{}, uniqueViolation(err, deviceConflicts)
 	}
 	dv = r.mapModelToDomain(dev)
 	return dv, nil
@@ -78,7 +120,7 @@ func (r deviceRepository) Update(dv domain.Device) (domain.Device, error) {
 	dev.UpdatedDate = time.Now()
 	err := r.coll.Find(db.Cond{"id": dev.Id, "deleted_date": nil}).Update(&dev)
 	if err != nil {
-		return domain.Device{}, err
+		return domain.Device{}, uniqueViolation(err, deviceConflicts)
 	}
 	dv = r.mapModelToDomain(dev)
 	return dv, nil
@@ -94,30 +136,219 @@ func (r deviceRepository) FindForRoom(mId uint64) ([]domain.Device, error) {
 	return res, nil
 }
 
+func (r deviceRepository) FindForOrganization(oId uint64) ([]domain.Device, error) {
+	var devs []device
+	err := r.coll.Find(db.Cond{"organization_id": oId, "deleted_date": nil}).All(&devs)
+	if err != nil {
+		return nil, err
+	}
+	res := r.mapModelToDomainCollection(devs)
+	return res, nil
+}
+
+func (r deviceRepository) FindAll(f domain.DeviceFilters, p domain.Pagination) (domain.Page[domain.Device], error) {
+	cond := db.Cond{"organization_id": f.OrganizationId, "deleted_date": nil}
+	if f.Deleted {
+		delete(cond, "deleted_date")
+		cond["deleted_date IS NOT"] = nil
+		if f.DeletedSince != nil {
+			cond["deleted_date >="] = *f.DeletedSince
+		}
+	}
+	if f.RoomId != nil {
+		cond["room_id"] = *f.RoomId
+	}
+	if f.Category != "" {
+		cond["device_category"] = f.Category
+	}
+	if f.SerialNumber != "" {
+		cond["serial_number LIKE"] = likeEscaper.Replace(f.SerialNumber) + "%"
+	}
+	if f.Status != "" {
+		cond["status"] = f.Status
+	}
+
+	order, err := orderBy(p, deviceSortColumns, "id", "id")
+	if err != nil {
+		return domain.Page[domain.Device]{}, err
+	}
+
+	var devs []device
+	res := r.coll.Find(cond).OrderBy(order...)
+	total, pages, err := paginate(res, p, &devs)
+	if err != nil {
+		return domain.Page[domain.Device]{}, err
+	}
+
+	return domain.Page[domain.Device]{
+		Items: r.mapModelToDomainCollection(devs),
+		Total: total,
+		Pages: pages,
+	}, nil
+}
+
 func (r deviceRepository) FindById(id uint64) (domain.Device, error) {
+	if outOfSerial(id) {
+		return domain.Device{}, domain.ErrNotFound
+	}
 	var dev device
 	err := r.coll.Find(db.Cond{"id": id, "deleted_date": nil}).One(&dev)
 	if err != nil {
-		return domain.Device{}, err
+		return domain.Device{}, notFound(err)
 	}
 	dv := r.mapModelToDomain(dev)
 	return dv, nil
 }
 
+func (r deviceRepository) FindByGUID(guid uuid.UUID) (domain.Device, error) {
+	var dev device
+	err := r.coll.Find(db.Cond{"guid": guid, "deleted_date": nil}).One(&dev)
+	if err != nil {
+		return domain.Device{}, notFound(err)
+	}
+	dv := r.mapModelToDomain(dev)
+	return dv, nil
+}
+
+// InventoryNumberTaken counts deleted devices as well, they keep
+// their numbers until they are purged.
+func (r deviceRepository) InventoryNumberTaken(number string) (bool, error) {
+	return r.coll.Find(db.Cond{"inventory_number": number}).Exists()
+}
+
+// SetDeviceToRoom also drops the placement, it is relative to the previous room.
 func (r deviceRepository) SetDeviceToRoom(deviceId, roomId uint64) error {
-	return r.coll.Find(db.Cond{"id": deviceId, "deleted_date": nil}).Update(map[string]interface{}{"room_id": roomId})
+	upd := noPlacement()
+	upd["room_id"] = roomId
+	return r.coll.Find(db.Cond{"id": deviceId, "deleted_date": nil}).Update(upd)
 }
 
 func (r deviceRepository) RemoveDeviceFromRoom(deviceId uint64) error {
-	return r.coll.Find(db.Cond{"id": deviceId, "deleted_date": nil}).Update(map[string]interface{}{"room_id": nil})
+	upd := noPlacement()
+	upd["room_id"] = nil
+	return r.coll.Find(db.Cond{"id": deviceId, "deleted_date": nil}).Update(upd)
+}
+
+func (r deviceRepository) FindPlacedForRoom(mId uint64) ([]domain.Device, error) {
+	var devs []device
+	err := r.coll.Find(db.Cond{"room_id": mId, "pos_x": db.IsNotNull(), "deleted_date": nil}).All(&devs)
+	if err != nil {
+		return nil, err
+	}
+	res := r.mapModelToDomainCollection(devs)
+	return res, nil
+}
+
+func (r deviceRepository) Touch(id uint64, seen time.Time) error {
+	return r.coll.Find(db.Cond{"id": id, "deleted_date": nil}).Update(map[string]interface{}{
+		"last_seen_date": seen,
+		"status":         domain.DeviceOnline,
+	})
+}
+
+// FindSilent returns up to limit devices in one of the given statuses
+// that have not been heard from since before.
+func (r deviceRepository) FindSilent(before time.Time, limit int, statuses ...domain.DeviceStatus) ([]domain.Device, error) {
+	var devs []device
+	err := r.coll.
+		Find(db.Cond{
+			"status IN":        statuses,
+			"last_seen_date <": before,
+			"deleted_date":     nil,
+		}).
+		OrderBy("id").
+		Limit(limit).
+		All(&devs)
+	if err != nil {
+		return nil, err
+	}
+	res := r.mapModelToDomainCollection(devs)
+	return res, nil
+}
+
+// SetStatus moves the devices found by FindSilent to status, skipping
+// those heard from or moved to another status since, and returns the ids
+// of the devices actually changed.
+func (r deviceRepository) SetStatus(ids []uint64, before time.Time, status domain.DeviceStatus, from ...domain.DeviceStatus) ([]uint64, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	upd := r.sess.SQL().
+		Update(DevicesTableName).
+		Set("status", status).
+		Where(db.Cond{
+			"id IN":            ids,
+			"status IN":        from,
+			"last_seen_date <": before,
+		}).
+		Amend(func(query string) string { return query + " RETURNING id" })
+	stmt, err := upd.Prepare()
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.Query(upd.Arguments()...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var changed []uint64
+	for rows.Next() {
+		var id uint64
+		err = rows.Scan(&id)
+		if err != nil {
+			return nil, err
+		}
+		changed = append(changed, id)
+	}
+	return changed, rows.Err()
 }
 
 func (r deviceRepository) Delete(id uint64) error {
 	return r.coll.Find(db.Cond{"id": id, "deleted_date": nil}).Update(map[string]interface{}{"deleted_date": time.Now()})
 }
 
+func (r deviceRepository) DeleteForRoom(mId uint64, at time.Time) error {
+	return r.coll.Find(db.Cond{"room_id": mId, "deleted_date": nil}).Update(map[string]interface{}{"deleted_date": at})
+}
+
+func (r deviceRepository) DeleteForOrganization(oId uint64, at time.Time) error {
+	return r.coll.Find(db.Cond{"organization_id": oId, "deleted_date": nil}).Update(map[string]interface{}{"deleted_date": at})
+}
+
+func (r deviceRepository) FindDeletedById(id uint64) (domain.Device, error) {
+	if outOfSerial(id) {
+		return domain.Device{}, domain.ErrNotFound
+	}
+	var dev device
+	err := r.coll.Find(db.Cond{"id": id, "deleted_date IS NOT": nil}).One(&dev)
+	if err != nil {
+		return domain.Device{}, notFound(err)
+	}
+	return r.mapModelToDomain(dev), nil
+}
+
+func (r deviceRepository) Restore(id uint64) error {
+	return r.coll.Find(db.Cond{"id": id, "deleted_date IS NOT": nil}).Update(map[string]interface{}{"deleted_date": nil, "updated_date": time.Now()})
+}
+
+// RestoreForRoom brings back the devices deleted along with the room,
+// they share its deletion time.
+func (r deviceRepository) RestoreForRoom(mId uint64, deletedAt time.Time) error {
+	return r.coll.Find(db.Cond{"room_id": mId, "deleted_date": deletedAt}).Update(map[string]interface{}{"deleted_date": nil, "updated_date": time.Now()})
+}
+
+// RestoreForOrganization brings back the devices deleted along with
+// the organization, they share its deletion time.
+func (r deviceRepository) RestoreForOrganization(oId uint64, deletedAt time.Time) error {
+	return r.coll.Find(db.Cond{"organization_id": oId, "deleted_date": deletedAt}).Update(map[string]interface{}{"deleted_date": nil, "updated_date": time.Now()})
+}
+
 func (r deviceRepository) mapDomainToModel(dv domain.Device) device {
-	return device{
+	dev := device{
 		Id:               dv.Id,
 		OrganizationId:   dv.OrganizationId,
 		RoomId:           dv.RoomId,
@@ -128,14 +359,23 @@ func (r deviceRepository) mapDomainToModel(dv domain.Device) device {
 		Category:         DeviceCategory(dv.Category),
 		Units:            dv.Units,
 		PowerConsumption: dv.PowerConsumption,
+		Status:           dv.Status,
+		LastSeenDate:     dv.LastSeenDate,
 		CreatedDate:      dv.CreatedDate,
 		UpdatedDate:      dv.UpdatedDate,
 		DeletedDate:      dv.DeletedDate,
 	}
+	if p := dv.Placement; p != nil {
+		dev.PosX, dev.PosY, dev.PosZ = &p.X, &p.Y, &p.Z
+		q := p.Orientation
+		dev.RotX, dev.RotY, dev.RotZ, dev.RotW = &q.X, &q.Y, &q.Z, &q.W
+		dev.AnchorId = p.AnchorId
+	}
+	return dev
 }
 
 func (r deviceRepository) mapModelToDomain(dv device) domain.Device {
-	return domain.Device{
+	dev := domain.Device{
 		Id:               dv.Id,
 		OrganizationId:   dv.OrganizationId,
 		RoomId:           dv.RoomId,
@@ -146,10 +386,20 @@ func (r deviceRepository) mapModelToDomain(dv device) domain.Device {
 		Category:         string(dv.Category),
 		Units:            dv.Units,
 		PowerConsumption: dv.PowerConsumption,
+		Status:           dv.Status,
+		LastSeenDate:     dv.LastSeenDate,
 		CreatedDate:      dv.CreatedDate,
 		UpdatedDate:      dv.UpdatedDate,
 		DeletedDate:      dv.DeletedDate,
 	}
+	if dv.PosX != nil && dv.PosY != nil && dv.PosZ != nil {
+		p := domain.Placement{X: *dv.PosX, Y: *dv.PosY, Z: *dv.PosZ, AnchorId: dv.AnchorId}
+		if dv.RotX != nil && dv.RotY != nil && dv.RotZ != nil && dv.RotW != nil {
+			p.Orientation = domain.Quaternion{X: *dv.RotX, Y: *dv.RotY, Z: *dv.RotZ, W: *dv.RotW}
+		}
+		dev.Placement = &p
+	}
+	return dev
 }
 
 func (r deviceRepository) mapModelToDomainCollection(devs []device) []domain.Device {
@@ -161,12 +411,25 @@ func (r deviceRepository) mapModelToDomainCollection(devs []device) []domain.Dev
 	return devices
 }
 
+func noPlacement() map[string]interface{} {
+	return map[string]interface{}{
+		"pos_x":     nil,
+		"pos_y":     nil,
+		"pos_z":     nil,
+		"rot_x":     nil,
+		"rot_y":     nil,
+		"rot_z":     nil,
+		"rot_w":     nil,
+		"anchor_id": nil,
+	}
+}
+
 func validateDevice(dv domain.Device) error {
 	if dv.Category == "ACTUATOR" && dv.PowerConsumption == nil {
-		return errors.New("PowerConsumption is required for ACTUATOR")
+		return domain.NewError(domain.ValidationError, "power_consumption_required", "PowerConsumption is required for ACTUATOR")
 	}
 	if dv.Category == "SENSOR" && dv.Units == nil {
-		return errors.New("Units is required for SENSOR")
+		return domain.NewError(domain.ValidationError, "units_required", "Units is required for SENSOR")
 	}
 	return nil
 }
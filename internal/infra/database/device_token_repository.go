@@ -0,0 +1,92 @@
+package database
+
+import (
+	"time"
+
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+	"github.com/upper/db/v4"
+)
+
+const DeviceTokensTableName = "device_tokens"
+
+type deviceToken struct {
+	Id          uint64     `db:"id,omitempty"`
+	DeviceId    uint64     `db:"device_id"`
+	UserId      uint64     `db:"user_id"`
+	TokenHash   string     `db:"token_hash"`
+	CreatedDate time.Time  `db:"created_date"`
+	RevokedDate *time.Time `db:"revoked_date"`
+}
+
+// ErrTokenIssued is returned by Save when the device has got another live
+// token meanwhile.
+var ErrTokenIssued = domain.NewError(domain.ConflictError, "token_issued", "another token is being issued for the device, try again")
+
+var deviceTokenConflicts = map[string]*domain.Error{
+	"device_tokens_device_id_live_idx": ErrTokenIssued,
+}
+
+type DeviceTokenRepository interface {
+	Save(t domain.DeviceToken) (domain.DeviceToken, error)
+	FindByHash(hash string) (domain.DeviceToken, error)
+	RevokeForDevice(dId uint64) error
+}
+
+type deviceTokenRepository struct {
+	coll db.Collection
+	sess db.Session
+}
+
+func NewDeviceTokenRepository(dbSession db.Session) DeviceTokenRepository {
+	return deviceTokenRepository{
+		coll: dbSession.Collection(DeviceTokensTableName),
+		sess: dbSession,
+	}
+}
+
+func (r deviceTokenRepository) Save(t domain.DeviceToken) (domain.DeviceToken, error) {
+	tkn := r.mapDomainToModel(t)
+	tkn.CreatedDate = time.Now()
+	err := r.coll.InsertReturning(&tkn)
+	if err != nil {
+		return domain.DeviceToken{}, uniqueViolation(err, deviceTokenConflicts)
+	}
+	t = r.mapModelToDomain(tkn)
+	return t, nil
+}
+
+func (r deviceTokenRepository) FindByHash(hash string) (domain.DeviceToken, error) {
+	var tkn deviceToken
+	err := r.coll.Find(db.Cond{"token_hash": hash, "revoked_date": nil}).One(&tkn)
+	if err != nil {
+		return domain.DeviceToken{}, notFound(err)
+	}
+	t := r.mapModelToDomain(tkn)
+	return t, nil
+}
+
+func (r deviceTokenRepository) RevokeForDevice(dId uint64) error {
+	return r.coll.Find(db.Cond{"device_id": dId, "revoked_date": nil}).Update(map[string]interface{}{"revoked_date": time.Now()})
+}
+
+func (r deviceTokenRepository) mapDomainToModel(d domain.DeviceToken) deviceToken {
+	return deviceToken{
+		Id:          d.Id,
+		DeviceId:    d.DeviceId,
+		UserId:      d.UserId,
+		TokenHash:   d.TokenHash,
+		CreatedDate: d.CreatedDate,
+		RevokedDate: d.RevokedDate,
+	}
+}
+
+func (r deviceTokenRepository) mapModelToDomain(d deviceToken) domain.DeviceToken {
+	return domain.DeviceToken{
+		Id:          d.Id,
+		DeviceId:    d.DeviceId,
+		UserId:      d.UserId,
+		TokenHash:   d.TokenHash,
+		CreatedDate: d.CreatedDate,
+		RevokedDate: d.RevokedDate,
+	}
+}
@@ -0,0 +1,172 @@
+package database
+
+import (
+	"time"
+
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+	"github.com/upper/db/v4"
+)
+
+const InvitationsTableName = "invitations"
+
+type invitation struct {
+	Id             uint64                  `db:"id,omitempty"`
+	OrganizationId uint64                  `db:"organization_id"`
+	Email          string                  `db:"email"`
+	Role           domain.OrganizationRole `db:"role"`
+	InvitedBy      uint64                  `db:"invited_by"`
+	TokenHash      string                  `db:"token_hash"`
+	ExpiresDate    time.Time               `db:"expires_date"`
+	AcceptedDate   *time.Time              `db:"accepted_date"`
+	AcceptedBy     *uint64                 `db:"accepted_by"`
+	RevokedDate    *time.Time              `db:"revoked_date"`
+	CreatedDate    time.Time               `db:"created_date"`
+	UpdatedDate    time.Time               `db:"updated_date"`
+}
+
+var invitationSortColumns = map[string]string{
+	"id":          "id",
+	"email":       "email",
+	"role":        "role",
+	"createdDate": "created_date",
+	"expiresDate": "expires_date",
+}
+
+type InvitationRepository interface {
+	Save(i domain.Invitation) (domain.Invitation, error)
+	Update(i domain.Invitation) (domain.Invitation, error)
+	FindById(id uint64) (domain.Invitation, error)
+	FindByHash(hash string) (domain.Invitation, error)
+	FindPendingForOrganization(oId uint64, p domain.Pagination) (domain.Page[domain.Invitation], error)
+	RevokePending(oId uint64, email string) error
+}
+
+type invitationRepository struct {
+	coll db.Collection
+	sess db.Session
+}
+
+func NewInvitationRepository(dbSession db.Session) InvitationRepository {
+	return invitationRepository{
+		coll: dbSession.Collection(InvitationsTableName),
+		sess: dbSession,
+	}
+}
+
+func (r invitationRepository) Save(i domain.Invitation) (domain.Invitation, error) {
+	inv := r.mapDomainToModel(i)
+	inv.CreatedDate, inv.UpdatedDate = time.Now(), time.Now()
+	err := r.coll.InsertReturning(&inv)
+	if err != nil {
+		return domain.Invitation{}, err
+	}
+	return r.mapModelToDomain(inv), nil
+}
+
+func (r invitationRepository) Update(i domain.Invitation) (domain.Invitation, error) {
+	inv := r.mapDomainToModel(i)
+	inv.UpdatedDate = time.Now()
+	err := r.coll.Find(db.Cond{"id": inv.Id}).Update(&inv)
+	if err != nil {
+		return domain.Invitation{}, err
+	}
+	return r.mapModelToDomain(inv), nil
+}
+
+func (r invitationRepository) FindById(id uint64) (domain.Invitation, error) {
+	if outOfSerial(id) {
+		return domain.Invitation{}, domain.ErrNotFound
+	}
+	var inv invitation
+	err := r.coll.Find(db.Cond{"id": id}).One(&inv)
+	if err != nil {
+		return domain.Invitation{}, notFound(err)
+	}
+	return r.mapModelToDomain(inv), nil
+}
+
+func (r invitationRepository) FindByHash(hash string) (domain.Invitation, error) {
+	var inv invitation
+	err := r.coll.Find(db.Cond{"token_hash": hash}).One(&inv)
+	if err != nil {
+		return domain.Invitation{}, notFound(err)
+	}
+	return r.mapModelToDomain(inv), nil
+}
+
+func (r invitationRepository) FindPendingForOrganization(oId uint64, p domain.Pagination) (domain.Page[domain.Invitation], error) {
+	order, err := orderBy(p, invitationSortColumns, "-created_date", "id")
+	if err != nil {
+		return domain.Page[domain.Invitation]{}, err
+	}
+
+	var invs []invitation
+	res := r.coll.Find(db.Cond{
+		"organization_id": oId,
+		"accepted_date":   nil,
+		"revoked_date":    nil,
+		"expires_date >":  time.Now(),
+	}).OrderBy(order...)
+	total, pages, err := paginate(res, p, &invs)
+	if err != nil {
+		return domain.Page[domain.Invitation]{}, err
+	}
+	return domain.Page[domain.Invitation]{
+		Items: r.mapModelToDomainCollection(invs),
+		Total: total,
+		Pages: pages,
+	}, nil
+}
+
+// RevokePending revokes whatever is still open for the email, so that
+// only the latest invitation to an organization can be accepted.
+func (r invitationRepository) RevokePending(oId uint64, email string) error {
+	return r.coll.Find(db.Cond{
+		"organization_id": oId,
+		"email":           email,
+		"accepted_date":   nil,
+		"revoked_date":    nil,
+	}).Update(map[string]interface{}{"revoked_date": time.Now(), "updated_date": time.Now()})
+}
+
+func (r invitationRepository) mapDomainToModel(d domain.Invitation) invitation {
+	return invitation{
+		Id:             d.Id,
+		OrganizationId: d.OrganizationId,
+		Email:          d.Email,
+		Role:           d.Role,
+		InvitedBy:      d.InvitedBy,
+		TokenHash:      d.TokenHash,
+		ExpiresDate:    d.ExpiresDate,
+		AcceptedDate:   d.AcceptedDate,
+		AcceptedBy:     d.AcceptedBy,
+		RevokedDate:    d.RevokedDate,
+		CreatedDate:    d.CreatedDate,
+		UpdatedDate:    d.UpdatedDate,
+	}
+}
+
+func (r invitationRepository) mapModelToDomain(m invitation) domain.Invitation {
+	return domain.Invitation{
+		Id:             m.Id,
+		OrganizationId: m.OrganizationId,
+		Email:          m.Email,
+		Role:           m.Role,
+		InvitedBy:      m.InvitedBy,
+		TokenHash:      m.TokenHash,
+		ExpiresDate:    m.ExpiresDate,
+		AcceptedDate:   m.AcceptedDate,
+		AcceptedBy:     m.AcceptedBy,
+		RevokedDate:    m.RevokedDate,
+		CreatedDate:    m.CreatedDate,
+		UpdatedDate:    m.UpdatedDate,
+	}
+}
+
+func (r invitationRepository) mapModelToDomainCollection(invs []invitation) []domain.Invitation {
+	res := make([]domain.Invitation, 0, len(invs))
+	for _, i := range invs {
+		res = append(res, r.mapModelToDomain(i))
+	}
+	return res
+}
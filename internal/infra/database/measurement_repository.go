@@ -0,0 +1,106 @@
+package database
+
+import (
+	"time"
+
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+	"github.com/upper/db/v4"
+)
+
+const MeasurementsTableName = "measurements"
+
+type measurement struct {
+	Id          uint64    `db:"id,omitempty"`
+	DeviceId    uint64    `db:"device_id"`
+	Value       float64   `db:"value"`
+	MeasuredAt  time.Time `db:"measured_at"`
+	CreatedDate time.Time `db:"created_date"`
+}
+
+var measurementSortColumns = map[string]string{
+	"id":         "id",
+	"value":      "value",
+	"measuredAt": "measured_at",
+}
+
+type MeasurementRepository interface {
+	Save(m domain.Measurement) (domain.Measurement, error)
+	FindForDevice(dId uint64, from, to time.Time, p domain.Pagination) (domain.Page[domain.Measurement], error)
+}
+
+type measurementRepository struct {
+	coll db.Collection
+	sess db.Session
+}
+
+func NewMeasurementRepository(dbSession db.Session) MeasurementRepository {
+	return measurementRepository{
+		coll: dbSession.Collection(MeasurementsTableName),
+		sess: dbSession,
+	}
+}
+
+func (r measurementRepository) Save(m domain.Measurement) (domain.Measurement, error) {
+	msr := r.mapDomainToModel(m)
+	msr.CreatedDate = time.Now()
+	err := r.coll.InsertReturning(&msr)
+	if err != nil {
+		return domain.Measurement{}, err
+	}
+	m = r.mapModelToDomain(msr)
+	return m, nil
+}
+
+func (r measurementRepository) FindForDevice(dId uint64, from, to time.Time, p domain.Pagination) (domain.Page[domain.Measurement], error) {
+	order, err := orderBy(p, measurementSortColumns, "measured_at", "id")
+	if err != nil {
+		return domain.Page[domain.Measurement]{}, err
+	}
+
+	var msrs []measurement
+	res := r.coll.
+		Find(db.Cond{
+			"device_id":      dId,
+			"measured_at >=": from,
+			"measured_at <=": to,
+		}).
+		OrderBy(order...)
+	total, pages, err := paginate(res, p, &msrs)
+	if err != nil {
+		return domain.Page[domain.Measurement]{}, err
+	}
+	return domain.Page[domain.Measurement]{
+		Items: r.mapModelToDomainCollection(msrs),
+		Total: total,
+		Pages: pages,
+	}, nil
+}
+
+func (r measurementRepository) mapDomainToModel(d domain.Measurement) measurement {
+	return measurement{
+		Id:          d.Id,
+		DeviceId:    d.DeviceId,
+		Value:       d.Value,
+		MeasuredAt:  d.MeasuredAt,
+		CreatedDate: d.CreatedDate,
+	}
+}
+
+func (r measurementRepository) mapModelToDomain(d measurement) domain.Measurement {
+	return domain.Measurement{
+		Id:          d.Id,
+		DeviceId:    d.DeviceId,
+		Value:       d.Value,
+		MeasuredAt:  d.MeasuredAt,
+		CreatedDate: d.CreatedDate,
+	}
+}
+
+func (r measurementRepository) mapModelToDomainCollection(msrs []measurement) []domain.Measurement {
+	var measurements []domain.Measurement
+	for _, m := range msrs {
+		msr := r.mapModelToDomain(m)
+		measurements = append(measurements, msr)
+	}
+	return measurements
+}
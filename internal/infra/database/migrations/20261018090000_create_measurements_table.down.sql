@@ -0,0 +1 @@
+DROP TABLE IF EXISTS public.measurements CASCADE;
\ No newline at end of file
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS public.measurements
+(
+    id              bigserial PRIMARY KEY,
+    device_id       integer NOT NULL REFERENCES public.devices(id),
+    "value"         double precision NOT NULL,
+    measured_at     timestamptz NOT NULL,
+    created_date    timestamptz NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS measurements_device_id_measured_at_idx
+    ON public.measurements (device_id, measured_at);
@@ -0,0 +1,6 @@
+ALTER TABLE public.devices ALTER COLUMN room_id SET NOT NULL;
+ALTER TABLE public.devices ALTER COLUMN units SET NOT NULL;
+ALTER TABLE public.devices ALTER COLUMN power_consumption SET NOT NULL;
+ALTER TABLE public.devices ALTER COLUMN power_consumption TYPE integer;
+ALTER TABLE public.devices RENAME COLUMN power_consumption TO powerconsumption;
+ALTER TABLE public.devices RENAME COLUMN device_category TO category;
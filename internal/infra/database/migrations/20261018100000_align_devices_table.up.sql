@@ -0,0 +1,6 @@
+ALTER TABLE public.devices RENAME COLUMN category TO device_category;
+ALTER TABLE public.devices RENAME COLUMN powerconsumption TO power_consumption;
+ALTER TABLE public.devices ALTER COLUMN power_consumption TYPE double precision;
+ALTER TABLE public.devices ALTER COLUMN power_consumption DROP NOT NULL;
+ALTER TABLE public.devices ALTER COLUMN units DROP NOT NULL;
+ALTER TABLE public.devices ALTER COLUMN room_id DROP NOT NULL;
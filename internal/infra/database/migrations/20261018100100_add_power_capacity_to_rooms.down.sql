@@ -0,0 +1 @@
+ALTER TABLE public.rooms DROP COLUMN IF EXISTS power_capacity;
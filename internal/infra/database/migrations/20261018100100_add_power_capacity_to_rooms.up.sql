@@ -0,0 +1 @@
+ALTER TABLE public.rooms ADD COLUMN IF NOT EXISTS power_capacity double precision;
@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS public.commands
+(
+    id              bigserial PRIMARY KEY,
+    device_id       integer NOT NULL REFERENCES public.devices(id),
+    user_id         integer NOT NULL REFERENCES public.users(id),
+    "action"        varchar(50) NOT NULL,
+    "value"         double precision,
+    status          varchar(20) NOT NULL,
+    result          text,
+    expires_at      timestamptz NOT NULL,
+    delivered_date  timestamptz,
+    acked_date      timestamptz,
+    created_date    timestamptz NOT NULL,
+    updated_date    timestamptz NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS commands_device_id_status_idx
+    ON public.commands (device_id, status);
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS public.device_tokens
+(
+    id              serial PRIMARY KEY,
+    device_id       integer NOT NULL REFERENCES public.devices(id),
+    user_id         integer NOT NULL REFERENCES public.users(id),
+    token_hash      varchar(64) NOT NULL UNIQUE,
+    created_date    timestamptz NOT NULL,
+    revoked_date    timestamptz
+);
+
+CREATE INDEX IF NOT EXISTS device_tokens_device_id_idx
+    ON public.device_tokens (device_id);
@@ -0,0 +1 @@
+ALTER TABLE public.devices DROP COLUMN IF EXISTS last_seen_date;
@@ -0,0 +1 @@
+ALTER TABLE public.devices ADD COLUMN IF NOT EXISTS last_seen_date timestamptz;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS public.devices_status_last_seen_date_idx;
+ALTER TABLE public.devices DROP COLUMN IF EXISTS status;
@@ -0,0 +1,4 @@
+ALTER TABLE public.devices ADD COLUMN IF NOT EXISTS status varchar(20) NOT NULL DEFAULT 'OFFLINE';
+
+CREATE INDEX IF NOT EXISTS devices_status_last_seen_date_idx
+    ON public.devices (status, last_seen_date);
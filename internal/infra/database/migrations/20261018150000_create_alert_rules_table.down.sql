@@ -0,0 +1 @@
+DROP TABLE IF EXISTS public.alert_rules CASCADE;
\ No newline at end of file
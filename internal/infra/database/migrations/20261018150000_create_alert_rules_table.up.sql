@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS public.alert_rules
+(
+    id              bigserial PRIMARY KEY,
+    organization_id integer NOT NULL REFERENCES public.organizations(id),
+    device_id       integer REFERENCES public.devices(id),
+    room_id         integer REFERENCES public.rooms(id),
+    user_id         integer NOT NULL REFERENCES public.users(id),
+    "name"          varchar(100) NOT NULL,
+    units           varchar(50),
+    operator        varchar(2) NOT NULL,
+    threshold       double precision NOT NULL,
+    duration        integer NOT NULL DEFAULT 0,
+    enabled         boolean NOT NULL DEFAULT true,
+    created_date    timestamptz NOT NULL,
+    updated_date    timestamptz NOT NULL,
+    deleted_date    timestamptz,
+    CHECK ((device_id IS NULL) <> (room_id IS NULL))
+);
+
+CREATE INDEX IF NOT EXISTS alert_rules_device_id_idx ON public.alert_rules (device_id);
+CREATE INDEX IF NOT EXISTS alert_rules_room_id_idx ON public.alert_rules (room_id);
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS public.alerts CASCADE;
\ No newline at end of file
@@ -0,0 +1,22 @@
+CREATE TABLE IF NOT EXISTS public.alerts
+(
+    id                bigserial PRIMARY KEY,
+    rule_id           integer NOT NULL REFERENCES public.alert_rules(id),
+    organization_id   integer NOT NULL REFERENCES public.organizations(id),
+    device_id         integer NOT NULL REFERENCES public.devices(id),
+    room_id           integer REFERENCES public.rooms(id),
+    status            varchar(20) NOT NULL,
+    "value"           double precision NOT NULL,
+    started_date      timestamptz NOT NULL,
+    opened_date       timestamptz,
+    resolved_date     timestamptz,
+    acknowledged_date timestamptz,
+    acknowledged_by   integer REFERENCES public.users(id),
+    created_date      timestamptz NOT NULL,
+    updated_date      timestamptz NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS alerts_rule_id_device_id_status_idx
+    ON public.alerts (rule_id, device_id, status);
+CREATE INDEX IF NOT EXISTS alerts_organization_id_status_idx
+    ON public.alerts (organization_id, status);
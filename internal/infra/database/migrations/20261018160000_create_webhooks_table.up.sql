@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS public.webhooks
+(
+    id              bigserial PRIMARY KEY,
+    organization_id integer NOT NULL REFERENCES public.organizations(id),
+    user_id         integer NOT NULL REFERENCES public.users(id),
+    url             varchar(2048) NOT NULL,
+    secret          varchar(255) NOT NULL,
+    events          text NOT NULL DEFAULT '',
+    enabled         boolean NOT NULL DEFAULT true,
+    created_date    timestamptz NOT NULL,
+    updated_date    timestamptz NOT NULL,
+    deleted_date    timestamptz
+);
+
+CREATE INDEX IF NOT EXISTS webhooks_organization_id_idx ON public.webhooks (organization_id);
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS public.webhook_deliveries CASCADE;
\ No newline at end of file
@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS public.webhook_deliveries
+(
+    id                bigserial PRIMARY KEY,
+    webhook_id        integer NOT NULL REFERENCES public.webhooks(id),
+    event_type        varchar(50) NOT NULL,
+    payload           text NOT NULL,
+    status            varchar(20) NOT NULL,
+    attempts          integer NOT NULL DEFAULT 0,
+    response_code     integer,
+    error             text,
+    next_attempt_date timestamptz,
+    delivered_date    timestamptz,
+    created_date      timestamptz NOT NULL,
+    updated_date      timestamptz NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS webhook_deliveries_status_next_attempt_date_idx
+    ON public.webhook_deliveries (status, next_attempt_date);
+CREATE INDEX IF NOT EXISTS webhook_deliveries_webhook_id_idx
+    ON public.webhook_deliveries (webhook_id);
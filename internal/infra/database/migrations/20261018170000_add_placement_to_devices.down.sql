@@ -0,0 +1,9 @@
+ALTER TABLE public.devices
+    DROP COLUMN IF EXISTS pos_x,
+    DROP COLUMN IF EXISTS pos_y,
+    DROP COLUMN IF EXISTS pos_z,
+    DROP COLUMN IF EXISTS rot_x,
+    DROP COLUMN IF EXISTS rot_y,
+    DROP COLUMN IF EXISTS rot_z,
+    DROP COLUMN IF EXISTS rot_w,
+    DROP COLUMN IF EXISTS anchor_id;
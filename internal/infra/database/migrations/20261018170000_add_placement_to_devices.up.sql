@@ -0,0 +1,9 @@
+ALTER TABLE public.devices
+    ADD COLUMN IF NOT EXISTS pos_x double precision,
+    ADD COLUMN IF NOT EXISTS pos_y double precision,
+    ADD COLUMN IF NOT EXISTS pos_z double precision,
+    ADD COLUMN IF NOT EXISTS rot_x double precision,
+    ADD COLUMN IF NOT EXISTS rot_y double precision,
+    ADD COLUMN IF NOT EXISTS rot_z double precision,
+    ADD COLUMN IF NOT EXISTS rot_w double precision,
+    ADD COLUMN IF NOT EXISTS anchor_id varchar(255);
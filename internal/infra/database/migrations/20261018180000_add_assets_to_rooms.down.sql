@@ -0,0 +1,3 @@
+ALTER TABLE public.rooms
+    DROP COLUMN IF EXISTS floor_plan,
+    DROP COLUMN IF EXISTS model;
@@ -0,0 +1,3 @@
+ALTER TABLE public.rooms
+    ADD COLUMN IF NOT EXISTS floor_plan varchar(255),
+    ADD COLUMN IF NOT EXISTS model varchar(255);
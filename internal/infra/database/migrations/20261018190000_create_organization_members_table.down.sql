@@ -0,0 +1 @@
+DROP TABLE IF EXISTS public.organization_members CASCADE;
\ No newline at end of file
@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS public.organization_members
+(
+    id                  serial PRIMARY KEY,
+    organization_id     integer NOT NULL REFERENCES public.organizations(id),
+    user_id             integer NOT NULL REFERENCES public.users(id),
+    "role"              varchar(20) NOT NULL CHECK ("role" IN ('owner', 'manager', 'technician', 'viewer')),
+    created_date        timestamptz NOT NULL,
+    updated_date        timestamptz NOT NULL,
+    UNIQUE (organization_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS organization_members_user_id_idx
+    ON public.organization_members (user_id);
+
+INSERT INTO public.organization_members (organization_id, user_id, "role", created_date, updated_date)
+SELECT id, user_id, 'owner', created_date, created_date
+FROM public.organizations
+ON CONFLICT DO NOTHING;
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS public.invitations CASCADE;
\ No newline at end of file
@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS public.invitations
+(
+    id                  serial PRIMARY KEY,
+    organization_id     integer NOT NULL REFERENCES public.organizations(id),
+    email               varchar(255) NOT NULL,
+    "role"              varchar(20) NOT NULL CHECK ("role" IN ('owner', 'manager', 'technician', 'viewer')),
+    invited_by          integer NOT NULL REFERENCES public.users(id),
+    token_hash          varchar(64) NOT NULL UNIQUE,
+    expires_date        timestamptz NOT NULL,
+    accepted_date       timestamptz,
+    accepted_by         integer REFERENCES public.users(id),
+    revoked_date        timestamptz,
+    created_date        timestamptz NOT NULL,
+    updated_date        timestamptz NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS invitations_organization_id_idx
+    ON public.invitations (organization_id);
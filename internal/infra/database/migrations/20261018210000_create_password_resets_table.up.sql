@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS public.password_resets
+(
+    id              serial PRIMARY KEY,
+    user_id         integer NOT NULL REFERENCES public.users(id),
+    token_hash      varchar(64) NOT NULL UNIQUE,
+    expires_date    timestamptz NOT NULL,
+    used_date       timestamptz,
+    created_date    timestamptz NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS password_resets_user_id_idx
+    ON public.password_resets (user_id);
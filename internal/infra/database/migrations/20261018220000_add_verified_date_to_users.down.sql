@@ -0,0 +1 @@
+ALTER TABLE public.users DROP COLUMN IF EXISTS verified_date;
\ No newline at end of file
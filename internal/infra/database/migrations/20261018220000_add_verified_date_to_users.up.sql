@@ -0,0 +1,4 @@
+ALTER TABLE public.users ADD COLUMN IF NOT EXISTS verified_date timestamp NULL;
+
+-- accounts created before verification existed are trusted as they are
+UPDATE public.users SET verified_date = created_date WHERE verified_date IS NULL;
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS public.sessions_uuid_idx;
+
+ALTER TABLE public.sessions
+    DROP COLUMN IF EXISTS refresh_hash,
+    DROP COLUMN IF EXISTS refresh_expires_date;
\ No newline at end of file
@@ -0,0 +1,5 @@
+ALTER TABLE public.sessions
+    ADD COLUMN IF NOT EXISTS refresh_hash         varchar(64) NULL,
+    ADD COLUMN IF NOT EXISTS refresh_expires_date timestamp   NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS sessions_uuid_idx ON public.sessions (uuid);
@@ -0,0 +1,5 @@
+ALTER TABLE public.sessions
+    DROP COLUMN IF EXISTS created_date,
+    DROP COLUMN IF EXISTS last_used_date,
+    DROP COLUMN IF EXISTS user_agent,
+    DROP COLUMN IF EXISTS ip;
\ No newline at end of file
@@ -0,0 +1,5 @@
+ALTER TABLE public.sessions
+    ADD COLUMN IF NOT EXISTS created_date   timestamp    NOT NULL DEFAULT now(),
+    ADD COLUMN IF NOT EXISTS last_used_date timestamp    NOT NULL DEFAULT now(),
+    ADD COLUMN IF NOT EXISTS user_agent     varchar(255) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS ip             varchar(45)  NOT NULL DEFAULT '';
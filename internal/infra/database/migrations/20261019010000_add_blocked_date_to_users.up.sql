@@ -0,0 +1 @@
+ALTER TABLE public.users ADD COLUMN IF NOT EXISTS blocked_date timestamp NULL;
@@ -0,0 +1,13 @@
+-- accounts registered twice with one email before the index existed:
+-- the oldest one is kept, the others are soft deleted, so an admin
+-- can still look at them and restore one once the email is free again
+UPDATE public.users u
+SET deleted_date = now()
+FROM public.users o
+WHERE o.email = u.email
+  AND o.id < u.id
+  AND o.deleted_date IS NULL
+  AND u.deleted_date IS NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS users_email_idx
+    ON public.users (email) WHERE deleted_date IS NULL;
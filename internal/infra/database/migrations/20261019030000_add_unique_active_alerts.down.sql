@@ -0,0 +1 @@
+DROP INDEX IF EXISTS public.alerts_rule_id_device_id_active_idx;
\ No newline at end of file
@@ -0,0 +1,24 @@
+-- duplicates opened by concurrent evaluations before the index existed:
+-- the newest alert stays active, older pending ones are dropped and
+-- older open ones are resolved
+DELETE FROM public.alerts a
+USING public.alerts n
+WHERE n.rule_id = a.rule_id
+  AND n.device_id = a.device_id
+  AND n.id > a.id
+  AND n.status <> 'RESOLVED'
+  AND a.status = 'PENDING';
+
+UPDATE public.alerts a
+SET status        = 'RESOLVED',
+    resolved_date = now(),
+    updated_date  = now()
+FROM public.alerts n
+WHERE n.rule_id = a.rule_id
+  AND n.device_id = a.device_id
+  AND n.id > a.id
+  AND n.status <> 'RESOLVED'
+  AND a.status <> 'RESOLVED';
+
+CREATE UNIQUE INDEX IF NOT EXISTS alerts_rule_id_device_id_active_idx
+    ON public.alerts (rule_id, device_id) WHERE status <> 'RESOLVED';
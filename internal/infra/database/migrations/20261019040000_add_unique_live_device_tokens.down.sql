@@ -0,0 +1 @@
+DROP INDEX IF EXISTS public.device_tokens_device_id_live_idx;
\ No newline at end of file
@@ -0,0 +1,12 @@
+-- devices left with several live tokens by concurrent issues before the
+-- index existed keep the newest one
+UPDATE public.device_tokens t
+SET revoked_date = now()
+FROM public.device_tokens n
+WHERE n.device_id = t.device_id
+  AND n.id > t.id
+  AND n.revoked_date IS NULL
+  AND t.revoked_date IS NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS device_tokens_device_id_live_idx
+    ON public.device_tokens (device_id) WHERE revoked_date IS NULL;
@@ -0,0 +1,17 @@
+package database
+
+import (
+	"errors"
+
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+	"github.com/upper/db/v4"
+)
+
+// notFound replaces the driver's "no more rows" with domain.ErrNotFound,
+// any other error is returned as it is.
+func notFound(err error) error {
+	if errors.Is(err, db.ErrNoMoreRows) {
+		return domain.ErrNotFound
+	}
+	return err
+}
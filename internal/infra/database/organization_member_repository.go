@@ -0,0 +1,217 @@
+package database
+
+import (
+	"time"
+
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+	"github.com/upper/db/v4"
+)
+
+const OrganizationMembersTableName = "organization_members"
+
+type organizationMember struct {
+	Id             uint64                  `db:"id,omitempty"`
+	OrganizationId uint64                  `db:"organization_id"`
+	UserId         uint64                  `db:"user_id"`
+	Role           domain.OrganizationRole `db:"role"`
+	CreatedDate    time.Time               `db:"created_date"`
+	UpdatedDate    time.Time               `db:"updated_date"`
+}
+
+// organizationMemberUser is a member row joined with its user.
+type organizationMemberUser struct {
+	organizationMember `db:",inline"`
+	Email              string `db:"email"`
+	FirstName          string `db:"first_name"`
+	SecondName         string `db:"second_name"`
+}
+
+var memberSortColumns = map[string]string{
+	"id":          "m.id",
+	"role":        "m.role",
+	"email":       "u.email",
+	"firstName":   "u.first_name",
+	"secondName":  "u.second_name",
+	"createdDate": "m.created_date",
+}
+
+type OrganizationMemberRepository interface {
+	Save(m domain.OrganizationMember) (domain.OrganizationMember, error)
+	Update(m domain.OrganizationMember) (domain.OrganizationMember, error)
+	FindById(id uint64) (domain.OrganizationMember, error)
+	FindMember(oId, uId uint64) (domain.OrganizationMember, error)
+	FindAll(f domain.MemberFilters, p domain.Pagination) (domain.Page[domain.OrganizationMember], error)
+	LockWithRole(oId uint64, role domain.OrganizationRole) ([]uint64, error)
+	Delete(id uint64) error
+}
+
+type organizationMemberRepository struct {
+	coll db.Collection
+	sess db.Session
+}
+
+func NewOrganizationMemberRepository(dbSession db.Session) OrganizationMemberRepository {
+	return organizationMemberRepository{
+		coll: dbSession.Collection(OrganizationMembersTableName),
+		sess: dbSession,
+	}
+}
+
+func (r organizationMemberRepository) Save(m domain.OrganizationMember) (domain.OrganizationMember, error) {
+	mem := r.mapDomainToModel(m)
+	mem.CreatedDate, mem.UpdatedDate = time.Now(), time.Now()
+	err := r.coll.InsertReturning(&mem)
+	if err != nil {
+		return domain.OrganizationMember{}, err
+	}
+	return r.mapModelToDomain(mem), nil
+}
+
+func (r organizationMemberRepository) Update(m domain.OrganizationMember) (domain.OrganizationMember, error) {
+	mem := r.mapDomainToModel(m)
+	mem.UpdatedDate = time.Now()
+	err := r.coll.Find(db.Cond{"id": mem.Id}).Update(&mem)
+	if err != nil {
+		return domain.OrganizationMember{}, err
+	}
+	res := r.mapModelToDomain(mem)
+	res.User = m.User
+	return res, nil
+}
+
+func (r organizationMemberRepository) FindById(id uint64) (domain.OrganizationMember, error) {
+	if outOfSerial(id) {
+		return domain.OrganizationMember{}, domain.ErrNotFound
+	}
+	var mem organizationMemberUser
+	err := r.withUsers().Where(db.Cond{"m.id": id}).One(&mem)
+	if err != nil {
+		return domain.OrganizationMember{}, notFound(err)
+	}
+	return r.mapJoinedToDomain(mem), nil
+}
+
+// FindMember looks the user up among the members of an organization
+// that has not been deleted.
+func (r organizationMemberRepository) FindMember(oId, uId uint64) (domain.OrganizationMember, error) {
+	if outOfSerial(oId) || outOfSerial(uId) {
+		return domain.OrganizationMember{}, domain.ErrNotFound
+	}
+	var mem organizationMember
+	err := r.sess.SQL().
+		Select("m.*").
+		From(OrganizationMembersTableName + " AS m").
+		Join(OrganizationsTableName + " AS o").On("o.id = m.organization_id").
+		Where(db.Cond{"m.organization_id": oId, "m.user_id": uId, "o.deleted_date": nil}).
+		One(&mem)
+	if err != nil {
+		return domain.OrganizationMember{}, err
+	}
+	return r.mapModelToDomain(mem), nil
+}
+
+func (r organizationMemberRepository) FindAll(f domain.MemberFilters, p domain.Pagination) (domain.Page[domain.OrganizationMember], error) {
+	cond := db.And(db.Cond{"m.organization_id": f.OrganizationId})
+	if f.Role != "" {
+		cond = cond.And(db.Cond{"m.role": f.Role})
+	}
+	if f.Search != "" {
+		pattern := "%" + likeEscaper.Replace(f.Search) + "%"
+		cond = cond.And(db.Or(
+			db.Cond{"u.email ILIKE": pattern},
+			db.Cond{"u.first_name ILIKE": pattern},
+			db.Cond{"u.second_name ILIKE": pattern},
+		))
+	}
+
+	order, err := orderBy(p, memberSortColumns, "m.id", "m.id")
+	if err != nil {
+		return domain.Page[domain.OrganizationMember]{}, err
+	}
+
+	var mems []organizationMemberUser
+	sel := r.withUsers().
+		Where(cond).
+		OrderBy(order...)
+	total, pages, err := paginateQuery(sel, p, &mems)
+	if err != nil {
+		return domain.Page[domain.OrganizationMember]{}, err
+	}
+
+	res := make([]domain.OrganizationMember, 0, len(mems))
+	for _, m := range mems {
+		res = append(res, r.mapJoinedToDomain(m))
+	}
+	return domain.Page[domain.OrganizationMember]{
+		Items: res,
+		Total: total,
+		Pages: pages,
+	}, nil
+}
+
+// LockWithRole returns the ids of the members having the role and keeps
+// them locked until the transaction ends, so the set can not change
+// under a decision made on it.
+func (r organizationMemberRepository) LockWithRole(oId uint64, role domain.OrganizationRole) ([]uint64, error) {
+	var mems []organizationMember
+	err := r.sess.SQL().
+		Select("*").
+		From(OrganizationMembersTableName).
+		Where(db.Cond{"organization_id": oId, "role": role}).
+		OrderBy("id").
+		Amend(func(query string) string { return query + " FOR UPDATE" }).
+		All(&mems)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]uint64, 0, len(mems))
+	for _, m := range mems {
+		ids = append(ids, m.Id)
+	}
+	return ids, nil
+}
+
+func (r organizationMemberRepository) Delete(id uint64) error {
+	return r.coll.Find(db.Cond{"id": id}).Delete()
+}
+
+func (r organizationMemberRepository) withUsers() db.Selector {
+	return r.sess.SQL().
+		Select("m.*", "u.email", "u.first_name", "u.second_name").
+		From(OrganizationMembersTableName + " AS m").
+		Join(UsersTableName + " AS u").On("u.id = m.user_id")
+}
+
+func (r organizationMemberRepository) mapDomainToModel(d domain.OrganizationMember) organizationMember {
+	return organizationMember{
+		Id:             d.Id,
+		OrganizationId: d.OrganizationId,
+		UserId:         d.UserId,
+		Role:           d.Role,
+		CreatedDate:    d.CreatedDate,
+		UpdatedDate:    d.UpdatedDate,
+	}
+}
+
+func (r organizationMemberRepository) mapModelToDomain(m organizationMember) domain.OrganizationMember {
+	return domain.OrganizationMember{
+		Id:             m.Id,
+		OrganizationId: m.OrganizationId,
+		UserId:         m.UserId,
+		Role:           m.Role,
+		CreatedDate:    m.CreatedDate,
+		UpdatedDate:    m.UpdatedDate,
+	}
+}
+
+func (r organizationMemberRepository) mapJoinedToDomain(m organizationMemberUser) domain.OrganizationMember {
+	res := r.mapModelToDomain(m.organizationMember)
+	res.User = &domain.User{
+		Id:         m.UserId,
+		Email:      m.Email,
+		FirstName:  m.FirstName,
+		SecondName: m.SecondName,
+	}
+	return res
+}
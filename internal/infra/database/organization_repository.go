@@ -23,12 +23,22 @@ type organization struct {
 	DeletedDate *time.Time `db:"deleted_date"`
 }
 
+var organizationSortColumns = map[string]string{
+	"id":          "o.id",
+	"name":        "o.name",
+	"city":        "o.city",
+	"createdDate": "o.created_date",
+	"updatedDate": "o.updated_date",
+}
+
 type OrganizationRepository interface {
 	Save(o domain.Organization) (domain.Organization, error)
-	FindForUser(uId uint64) ([]domain.Organization, error)
+	FindForUser(uId uint64, p domain.Pagination) (domain.Page[domain.Organization], error)
 	FindById(id uint64) (domain.Organization, error)
 	Update(o domain.Organization) (domain.Organization, error)
+	FindDeletedById(id uint64) (domain.Organization, error)
 	Delete(id uint64) error
+	Restore(id uint64) error
 }
 
 type organizationRepository struct {
@@ -54,21 +64,40 @@ func (r organizationRepository) Save(o domain.Organization) (domain.Organization
 	return o, nil
 }
 
-func (r organizationRepository) FindForUser(uId uint64) ([]domain.Organization, error) {
+// FindForUser lists the organizations the user is a member of.
+func (r organizationRepository) FindForUser(uId uint64, p domain.Pagination) (domain.Page[domain.Organization], error) {
+	order, err := orderBy(p, organizationSortColumns, "o.id", "o.id")
+	if err != nil {
+		return domain.Page[domain.Organization]{}, err
+	}
+
 	var orgs []organization
-	err := r.coll.Find(db.Cond{"user_id": uId, "deleted_date": nil}).All(&orgs)
+	sel := r.sess.SQL().
+		Select("o.*").
+		From(OrganizationsTableName + " AS o").
+		Join(OrganizationMembersTableName + " AS m").On("m.organization_id = o.id").
+		Where(db.Cond{"m.user_id": uId, "o.deleted_date": nil}).
+		OrderBy(order...)
+	total, pages, err := paginateQuery(sel, p, &orgs)
 	if err != nil {
-		return nil, err
+		return domain.Page[domain.Organization]{}, err
 	}
-	res := r.mapModelToDomainCollection(orgs)
-	return res, nil
+
+	return domain.Page[domain.Organization]{
+		Items: r.mapModelToDomainCollection(orgs),
+		Total: total,
+		Pages: pages,
+	}, nil
 }
 
 func (r organizationRepository) FindById(id uint64) (domain.Organization, error) {
+	if outOfSerial(id) {
+		return domain.Organization{}, domain.ErrNotFound
+	}
 	var org organization
 	err := r.coll.Find(db.Cond{"id": id, "deleted_date": nil}).One(&org)
 	if err != nil {
-		return domain.Organization{}, err
+		return domain.Organization{}, notFound(err)
 	}
 	o := r.mapModelToDomain(org)
 	return o, nil
@@ -89,6 +118,22 @@ func (r organizationRepository) Delete(id uint64) error {
 	return r.coll.Find(db.Cond{"id": id, "deleted_date": nil}).Update(map[string]interface{}{"deleted_date": time.Now()})
 }
 
+func (r organizationRepository) FindDeletedById(id uint64) (domain.Organization, error) {
+	if outOfSerial(id) {
+		return domain.Organization{}, domain.ErrNotFound
+	}
+	var org organization
+	err := r.coll.Find(db.Cond{"id": id, "deleted_date IS NOT": nil}).One(&org)
+	if err != nil {
+		return domain.Organization{}, notFound(err)
+	}
+	return r.mapModelToDomain(org), nil
+}
+
+func (r organizationRepository) Restore(id uint64) error {
+	return r.coll.Find(db.Cond{"id": id, "deleted_date IS NOT": nil}).Update(map[string]interface{}{"deleted_date": nil, "updated_date": time.Now()})
+}
+
 func (r organizationRepository) mapDomainToModel(d domain.Organization) organization {
 	return organization{
 		Id:          d.Id,
@@ -0,0 +1,72 @@
+package database
+
+import (
+	"strings"
+
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+	"github.com/upper/db/v4"
+)
+
+// likeEscaper makes user input match literally inside a LIKE pattern.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+var ErrInvalidSort = domain.NewFieldError(domain.ValidationError, "invalid_sort", "sort", "the list can not be sorted by this field")
+
+// orderBy sorts by the column p.Sort stands for in columns, or by def when
+// no field is given, a field that is not sortable is an ErrInvalidSort.
+// key comes last, so that pages never overlap.
+func orderBy(p domain.Pagination, columns map[string]string, def, key string) ([]interface{}, error) {
+	order := def
+	if p.Sort != "" {
+		col, ok := columns[p.Sort]
+		if !ok {
+			return nil, ErrInvalidSort
+		}
+		order = col
+		if p.Desc {
+			order = "-" + col
+		}
+	}
+	if order == key || order == "-"+key {
+		return []interface{}{order}, nil
+	}
+	return []interface{}{order, key}, nil
+}
+
+// paginate loads a single page of res into dest and counts all of them.
+func paginate(res db.Result, p domain.Pagination, dest interface{}) (uint64, uint, error) {
+	res = res.Paginate(uint(p.CountPerPage))
+	total, err := res.TotalEntries()
+	if err != nil {
+		return 0, 0, err
+	}
+	pages, err := res.TotalPages()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	err = res.Page(uint(p.Page)).All(dest)
+	if err != nil {
+		return 0, 0, err
+	}
+	return total, pages, nil
+}
+
+// paginateQuery is paginate for queries built with the SQL builder.
+func paginateQuery(sel db.Selector, p domain.Pagination, dest interface{}) (uint64, uint, error) {
+	pag := sel.Paginate(uint(p.CountPerPage))
+	total, err := pag.TotalEntries()
+	if err != nil {
+		return 0, 0, err
+	}
+	pages, err := pag.TotalPages()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	err = pag.Page(uint(p.Page)).All(dest)
+	if err != nil {
+		return 0, 0, err
+	}
+	return total, pages, nil
+}
@@ -0,0 +1,109 @@
+package database
+
+import (
+	"time"
+
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+	"github.com/upper/db/v4"
+)
+
+const PasswordResetsTableName = "password_resets"
+
+type passwordReset struct {
+	Id          uint64     `db:"id,omitempty"`
+	UserId      uint64     `db:"user_id"`
+	TokenHash   string     `db:"token_hash"`
+	ExpiresDate time.Time  `db:"expires_date"`
+	UsedDate    *time.Time `db:"used_date"`
+	CreatedDate time.Time  `db:"created_date"`
+}
+
+type PasswordResetRepository interface {
+	Save(p domain.PasswordReset) (domain.PasswordReset, error)
+	Use(hash string) (domain.PasswordReset, error)
+	UseAllForUser(uId uint64) error
+}
+
+type passwordResetRepository struct {
+	coll db.Collection
+	sess db.Session
+}
+
+func NewPasswordResetRepository(dbSession db.Session) PasswordResetRepository {
+	return passwordResetRepository{
+		coll: dbSession.Collection(PasswordResetsTableName),
+		sess: dbSession,
+	}
+}
+
+func (r passwordResetRepository) Save(p domain.PasswordReset) (domain.PasswordReset, error) {
+	pr := r.mapDomainToModel(p)
+	pr.CreatedDate = time.Now()
+	err := r.coll.InsertReturning(&pr)
+	if err != nil {
+		return domain.PasswordReset{}, err
+	}
+	return r.mapModelToDomain(pr), nil
+}
+
+// Use marks the reset as used only while it is still usable, so racing
+// requests can not redeem the same token twice. domain.ErrNotFound is
+// returned when nothing was updated.
+func (r passwordResetRepository) Use(hash string) (domain.PasswordReset, error) {
+	res, err := r.sess.SQL().
+		Update(PasswordResetsTableName).
+		Set("used_date", time.Now()).
+		Where(db.Cond{
+			"token_hash":     hash,
+			"used_date":      nil,
+			"expires_date >": time.Now(),
+		}).
+		Exec()
+	if err != nil {
+		return domain.PasswordReset{}, err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return domain.PasswordReset{}, err
+	}
+	if n == 0 {
+		return domain.PasswordReset{}, domain.ErrNotFound
+	}
+
+	var pr passwordReset
+	err = r.coll.Find(db.Cond{"token_hash": hash}).One(&pr)
+	if err != nil {
+		return domain.PasswordReset{}, notFound(err)
+	}
+	return r.mapModelToDomain(pr), nil
+}
+
+// UseAllForUser marks every outstanding reset of the user as used,
+// so a token can not be redeemed twice and older ones die with it.
+func (r passwordResetRepository) UseAllForUser(uId uint64) error {
+	return r.coll.Find(db.Cond{"user_id": uId, "used_date": nil}).
+		Update(map[string]interface{}{"used_date": time.Now()})
+}
+
+func (r passwordResetRepository) mapDomainToModel(d domain.PasswordReset) passwordReset {
+	return passwordReset{
+		Id:          d.Id,
+		UserId:      d.UserId,
+		TokenHash:   d.TokenHash,
+		ExpiresDate: d.ExpiresDate,
+		UsedDate:    d.UsedDate,
+		CreatedDate: d.CreatedDate,
+	}
+}
+
+func (r passwordResetRepository) mapModelToDomain(m passwordReset) domain.PasswordReset {
+	return domain.PasswordReset{
+		Id:          m.Id,
+		UserId:      m.UserId,
+		TokenHash:   m.TokenHash,
+		ExpiresDate: m.ExpiresDate,
+		UsedDate:    m.UsedDate,
+		CreatedDate: m.CreatedDate,
+	}
+}
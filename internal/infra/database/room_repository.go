@@ -14,17 +14,33 @@ type room struct {
 	Name           string     `db:"name"`
 	OrganizationId uint64     `db:"organization_id"`
 	Description    string     `db:"description"`
+	PowerCapacity  *float64   `db:"power_capacity"`
+	FloorPlan      *string    `db:"floor_plan"`
+	Model          *string    `db:"model"`
 	CreatedDate    time.Time  `db:"created_date"`
 	UpdatedDate    time.Time  `db:"updated_date"`
 	DeletedDate    *time.Time `db:"deleted_date"`
 }
 
+var roomSortColumns = map[string]string{
+	"id":          "id",
+	"name":        "name",
+	"createdDate": "created_date",
+	"updatedDate": "updated_date",
+	"deletedDate": "deleted_date",
+}
+
 type RoomRepository interface {
 	Save(r domain.Room) (domain.Room, error)
 	FindForOrganization(oId uint64) ([]domain.Room, error)
+	FindAll(f domain.RoomFilters, p domain.Pagination) (domain.Page[domain.Room], error)
 	FindById(id uint64) (domain.Room, error)
 	Update(m domain.Room) (domain.Room, error)
+	FindDeletedById(id uint64) (domain.Room, error)
 	Delete(id uint64) error
+	DeleteForOrganization(oId uint64, at time.Time) error
+	Restore(id uint64) error
+	RestoreForOrganization(oId uint64, deletedAt time.Time) error
 }
 
 type roomRepository struct {
@@ -60,11 +76,46 @@ func (r roomRepository) FindForOrganization(oId uint64) ([]domain.Room, error) {
 	return res, nil
 }
 
+func (r roomRepository) FindAll(f domain.RoomFilters, p domain.Pagination) (domain.Page[domain.Room], error) {
+	cond := db.Cond{"organization_id": f.OrganizationId, "deleted_date": nil}
+	if f.Deleted {
+		delete(cond, "deleted_date")
+		cond["deleted_date IS NOT"] = nil
+		if f.DeletedSince != nil {
+			cond["deleted_date >="] = *f.DeletedSince
+		}
+	}
+	if f.Search != "" {
+		cond["name ILIKE"] = "%" + likeEscaper.Replace(f.Search) + "%"
+	}
+
+	order, err := orderBy(p, roomSortColumns, "id", "id")
+	if err != nil {
+		return domain.Page[domain.Room]{}, err
+	}
+
+	var roms []room
+	res := r.coll.Find(cond).OrderBy(order...)
+	total, pages, err := paginate(res, p, &roms)
+	if err != nil {
+		return domain.Page[domain.Room]{}, err
+	}
+
+	return domain.Page[domain.Room]{
+		Items: r.mapModelToDomainCollection(roms),
+		Total: total,
+		Pages: pages,
+	}, nil
+}
+
 func (r roomRepository) FindById(id uint64) (domain.Room, error) {
+	if outOfSerial(id) {
+		return domain.Room{}, domain.ErrNotFound
+	}
 	var rom room
 	err := r.coll.Find(db.Cond{"id": id, "deleted_date": nil}).One(&rom)
 	if err != nil {
-		return domain.Room{}, err
+		return domain.Room{}, notFound(err)
 	}
 	m := r.mapModelToDomain(rom)
 	return m, nil
@@ -85,12 +136,41 @@ func (r roomRepository) Delete(id uint64) error {
 	return r.coll.Find(db.Cond{"id": id, "deleted_date": nil}).Update(map[string]interface{}{"deleted_date": time.Now()})
 }
 
+func (r roomRepository) DeleteForOrganization(oId uint64, at time.Time) error {
+	return r.coll.Find(db.Cond{"organization_id": oId, "deleted_date": nil}).Update(map[string]interface{}{"deleted_date": at})
+}
+
+func (r roomRepository) FindDeletedById(id uint64) (domain.Room, error) {
+	if outOfSerial(id) {
+		return domain.Room{}, domain.ErrNotFound
+	}
+	var rom room
+	err := r.coll.Find(db.Cond{"id": id, "deleted_date IS NOT": nil}).One(&rom)
+	if err != nil {
+		return domain.Room{}, notFound(err)
+	}
+	return r.mapModelToDomain(rom), nil
+}
+
+func (r roomRepository) Restore(id uint64) error {
+	return r.coll.Find(db.Cond{"id": id, "deleted_date IS NOT": nil}).Update(map[string]interface{}{"deleted_date": nil, "updated_date": time.Now()})
+}
+
+// RestoreForOrganization brings back the rooms deleted along with the
+// organization, they share its deletion time.
+func (r roomRepository) RestoreForOrganization(oId uint64, deletedAt time.Time) error {
+	return r.coll.Find(db.Cond{"organization_id": oId, "deleted_date": deletedAt}).Update(map[string]interface{}{"deleted_date": nil, "updated_date": time.Now()})
+}
+
 func (r roomRepository) mapDomainToModel(d domain.Room) room {
 	return room{
 		Id:             d.Id,
 		Name:           d.Name,
 		OrganizationId: d.OrganizationId,
 		Description:    d.Description,
+		PowerCapacity:  d.PowerCapacity,
+		FloorPlan:      d.FloorPlan,
+		Model:          d.Model,
 		CreatedDate:    d.CreatedDate,
 		UpdatedDate:    d.UpdatedDate,
 		DeletedDate:    d.DeletedDate,
@@ -103,6 +183,9 @@ func (r roomRepository) mapModelToDomain(d room) domain.Room {
 		Name:           d.Name,
 		OrganizationId: d.OrganizationId,
 		Description:    d.Description,
+		PowerCapacity:  d.PowerCapacity,
+		FloorPlan:      d.FloorPlan,
+		Model:          d.Model,
 		CreatedDate:    d.CreatedDate,
 		UpdatedDate:    d.UpdatedDate,
 		DeletedDate:    d.DeletedDate,
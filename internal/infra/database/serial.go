@@ -0,0 +1,9 @@
+package database
+
+import "math"
+
+// outOfSerial tells whether the id can not be stored in a serial column,
+// the driver fails to encode such ids instead of finding nothing.
+func outOfSerial(id uint64) bool {
+	return id > math.MaxInt32
+}
@@ -2,6 +2,8 @@ package database
 
 import (
 	"fmt"
+	"time"
+
 	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
 	"github.com/google/uuid"
 	"github.com/upper/db/v4"
@@ -10,28 +12,48 @@ import (
 const SessionsTableName = "sessions"
 
 type sessions struct {
-	UserId uint64    `db:"user_id"`
-	UUID   uuid.UUID `db:"uuid"`
+	UserId             uint64     `db:"user_id"`
+	UUID               uuid.UUID  `db:"uuid"`
+	RefreshHash        *string    `db:"refresh_hash"`
+	RefreshExpiresDate *time.Time `db:"refresh_expires_date"`
+	UserAgent          string     `db:"user_agent"`
+	Ip                 string     `db:"ip"`
+	CreatedDate        time.Time  `db:"created_date"`
+	LastUsedDate       time.Time  `db:"last_used_date"`
+}
+
+var sessionSortColumns = map[string]string{
+	"createdDate":  "created_date",
+	"lastUsedDate": "last_used_date",
 }
 
 type SessionRepository interface {
 	Save(sess domain.Session) error
 	Exists(sess domain.Session) error
+	FindByUUID(id uuid.UUID) (domain.Session, error)
+	FindForUser(uId uint64, p domain.Pagination) (domain.Page[domain.Session], error)
+	Rotate(sess domain.Session, oldHash string) error
+	Touch(lastUsed map[uuid.UUID]time.Time) error
 	Delete(sess domain.Session) error
+	DeleteOthers(sess domain.Session) error
+	DeleteForUser(uId uint64) error
 }
 
 type sessionRepository struct {
 	coll db.Collection
+	sess db.Session
 }
 
 func NewSessRepository(dbSession db.Session) SessionRepository {
 	return sessionRepository{
 		coll: dbSession.Collection(SessionsTableName),
+		sess: dbSession,
 	}
 }
 
 func (r sessionRepository) Save(sess domain.Session) error {
 	a := r.mapDomainToModel(sess)
+	a.CreatedDate, a.LastUsedDate = time.Now(), time.Now()
 	err := r.coll.InsertReturning(&a)
 	if err != nil {
 		return err
@@ -48,13 +70,127 @@ func (r sessionRepository) Exists(sess domain.Session) error {
 	return err
 }
 
+func (r sessionRepository) FindByUUID(id uuid.UUID) (domain.Session, error) {
+	var s sessions
+	err := r.coll.Find(db.Cond{"uuid": id}).One(&s)
+	if err != nil {
+		return domain.Session{}, notFound(err)
+	}
+	return r.mapModelToDomain(s), nil
+}
+
+func (r sessionRepository) FindForUser(uId uint64, p domain.Pagination) (domain.Page[domain.Session], error) {
+	order, err := orderBy(p, sessionSortColumns, "-last_used_date", "uuid")
+	if err != nil {
+		return domain.Page[domain.Session]{}, err
+	}
+
+	var ss []sessions
+	res := r.coll.Find(db.Cond{"user_id": uId}).OrderBy(order...)
+	total, pages, err := paginate(res, p, &ss)
+	if err != nil {
+		return domain.Page[domain.Session]{}, err
+	}
+	return domain.Page[domain.Session]{
+		Items: r.mapModelToDomainCollection(ss),
+		Total: total,
+		Pages: pages,
+	}, nil
+}
+
+// Rotate stores the new refresh token only while oldHash is still the
+// current one, so a token can not be exchanged twice by racing requests.
+// domain.ErrNotFound is returned when nothing was updated.
+func (r sessionRepository) Rotate(sess domain.Session, oldHash string) error {
+	res, err := r.sess.SQL().
+		Update(SessionsTableName).
+		Set(map[string]interface{}{
+			"refresh_hash":         sess.RefreshHash,
+			"refresh_expires_date": sess.RefreshExpiresDate,
+			"user_agent":           sess.UserAgent,
+			"ip":                   sess.Ip,
+			"last_used_date":       time.Now(),
+		}).
+		Where(db.Cond{"user_id": sess.UserId, "uuid": sess.UUID, "refresh_hash": oldHash}).
+		Exec()
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+// Touch writes the collected last use times in a single transaction.
+func (r sessionRepository) Touch(lastUsed map[uuid.UUID]time.Time) error {
+	return r.sess.Tx(func(tx db.Session) error {
+		for id, t := range lastUsed {
+			err := tx.Collection(SessionsTableName).
+				Find(db.Cond{"uuid": id, "last_used_date <": t}).
+				Update(map[string]interface{}{"last_used_date": t})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
 func (r sessionRepository) Delete(sess domain.Session) error {
 	return r.coll.Find(db.Cond{"user_id": sess.UserId, "uuid": sess.UUID}).Delete()
 }
 
+// DeleteOthers removes every session of the user except sess itself.
+func (r sessionRepository) DeleteOthers(sess domain.Session) error {
+	return r.coll.Find(db.Cond{"user_id": sess.UserId, "uuid !=": sess.UUID}).Delete()
+}
+
+func (r sessionRepository) DeleteForUser(uId uint64) error {
+	return r.coll.Find(db.Cond{"user_id": uId}).Delete()
+}
+
 func (r sessionRepository) mapDomainToModel(d domain.Session) sessions {
-	return sessions{
-		UserId: d.UserId,
-		UUID:   d.UUID,
+	s := sessions{
+		UserId:             d.UserId,
+		UUID:               d.UUID,
+		RefreshExpiresDate: d.RefreshExpiresDate,
+		UserAgent:          d.UserAgent,
+		Ip:                 d.Ip,
+		CreatedDate:        d.CreatedDate,
+		LastUsedDate:       d.LastUsedDate,
+	}
+	if d.RefreshHash != "" {
+		s.RefreshHash = &d.RefreshHash
+	}
+	return s
+}
+
+func (r sessionRepository) mapModelToDomain(m sessions) domain.Session {
+	s := domain.Session{
+		UserId:             m.UserId,
+		UUID:               m.UUID,
+		RefreshExpiresDate: m.RefreshExpiresDate,
+		UserAgent:          m.UserAgent,
+		Ip:                 m.Ip,
+		CreatedDate:        m.CreatedDate,
+		LastUsedDate:       m.LastUsedDate,
+	}
+	if m.RefreshHash != nil {
+		s.RefreshHash = *m.RefreshHash
+	}
+	return s
+}
+
+func (r sessionRepository) mapModelToDomainCollection(ss []sessions) []domain.Session {
+	res := make([]domain.Session, 0, len(ss))
+	for _, s := range ss {
+		res = append(res, r.mapModelToDomain(s))
 	}
+	return res
 }
@@ -0,0 +1,89 @@
+package database
+
+import "github.com/upper/db/v4"
+
+// Tx hands out repositories working within one transaction.
+type Tx interface {
+	Users() UserRepository
+	Sessions() SessionRepository
+	PasswordResets() PasswordResetRepository
+	Organizations() OrganizationRepository
+	OrganizationMembers() OrganizationMemberRepository
+	Invitations() InvitationRepository
+	Rooms() RoomRepository
+	Devices() DeviceRepository
+	DeviceTokens() DeviceTokenRepository
+	Webhooks() WebhookRepository
+	WebhookDeliveries() WebhookDeliveryRepository
+}
+
+// UnitOfWork runs multi-step changes atomically: everything done through
+// the Tx is committed when fn returns nil and rolled back otherwise,
+// the error of fn is returned as it is.
+type UnitOfWork interface {
+	Do(fn func(tx Tx) error) error
+}
+
+type unitOfWork struct {
+	sess db.Session
+}
+
+func NewUnitOfWork(dbSession db.Session) UnitOfWork {
+	return unitOfWork{
+		sess: dbSession,
+	}
+}
+
+func (u unitOfWork) Do(fn func(tx Tx) error) error {
+	return u.sess.Tx(func(sess db.Session) error {
+		return fn(tx{sess: sess})
+	})
+}
+
+type tx struct {
+	sess db.Session
+}
+
+func (t tx) Users() UserRepository {
+	return NewUserRepository(t.sess)
+}
+
+func (t tx) Sessions() SessionRepository {
+	return NewSessRepository(t.sess)
+}
+
+func (t tx) PasswordResets() PasswordResetRepository {
+	return NewPasswordResetRepository(t.sess)
+}
+
+func (t tx) Organizations() OrganizationRepository {
+	return NewOrganizationRepository(t.sess)
+}
+
+func (t tx) OrganizationMembers() OrganizationMemberRepository {
+	return NewOrganizationMemberRepository(t.sess)
+}
+
+func (t tx) Invitations() InvitationRepository {
+	return NewInvitationRepository(t.sess)
+}
+
+func (t tx) Rooms() RoomRepository {
+	return NewRoomRepository(t.sess)
+}
+
+func (t tx) Devices() DeviceRepository {
+	return NewDeviceRepository(t.sess)
+}
+
+func (t tx) DeviceTokens() DeviceTokenRepository {
+	return NewDeviceTokenRepository(t.sess)
+}
+
+func (t tx) Webhooks() WebhookRepository {
+	return NewWebhookRepository(t.sess)
+}
+
+func (t tx) WebhookDeliveries() WebhookDeliveryRepository {
+	return NewWebhookDeliveryRepository(t.sess)
+}
@@ -10,24 +10,45 @@ import (
 const UsersTableName = "users"
 
 type user struct {
-	Id          uint64      `db:"id,omitempty"`
-	FirstName   string      `db:"first_name"`
-	SecondName  string      `db:"second_name"`
-	Password    string      `db:"password"`
-	Email       string      `db:"email"`
-	Role        domain.Role `db:"role"`
-	CreatedDate time.Time   `db:"created_date,omitempty"`
-	UpdatedDate time.Time   `db:"updated_date,omitempty"`
-	DeletedDate *time.Time  `db:"deleted_date,omitempty"`
+	Id           uint64      `db:"id,omitempty"`
+	FirstName    string      `db:"first_name"`
+	SecondName   string      `db:"second_name"`
+	Password     string      `db:"password"`
+	Email        string      `db:"email"`
+	Role         domain.Role `db:"role"`
+	VerifiedDate *time.Time  `db:"verified_date"`
+	BlockedDate  *time.Time  `db:"blocked_date"`
+	CreatedDate  time.Time   `db:"created_date,omitempty"`
+	UpdatedDate  time.Time   `db:"updated_date,omitempty"`
+	DeletedDate  *time.Time  `db:"deleted_date,omitempty"`
+}
+
+var userSortColumns = map[string]string{
+	"id":          "id",
+	"email":       "email",
+	"firstName":   "first_name",
+	"secondName":  "second_name",
+	"createdDate": "created_date",
+}
+
+// userConflicts names the request field behind every unique constraint.
+var userConflicts = map[string]*domain.Error{
+	"users_email_idx": domain.ErrEmailTaken,
 }
 
 type UserRepository interface {
 	FindByEmail(phone string) (domain.User, error)
 	FindById(id uint64) (domain.User, error)
 	Find(id uint64) (interface{}, error)
+	FindAll(f domain.UserFilters, p domain.Pagination) (domain.Page[domain.User], error)
 	Save(user domain.User) (domain.User, error)
 	Update(user domain.User) (domain.User, error)
+	SetPassword(id uint64, hash string) (domain.User, error)
+	SetRole(id uint64, role domain.Role) (domain.User, error)
+	SetBlocked(id uint64, blocked *time.Time) (domain.User, error)
+	SetVerified(id uint64, email string) (domain.User, error)
 	Delete(id uint64) error
+	Restore(id uint64) error
 }
 
 type userRepository struct {
@@ -46,17 +67,20 @@ func (r userRepository) FindByEmail(email string) (domain.User, error) {
 	var u user
 	err := r.coll.Find(db.Cond{"email": email, "deleted_date": nil}).One(&u)
 	if err != nil {
-		return domain.User{}, err
+		return domain.User{}, notFound(err)
 	}
 
 	return r.mapModelToDomain(u), nil
 }
 
 func (r userRepository) FindById(id uint64) (domain.User, error) {
+	if outOfSerial(id) {
+		return domain.User{}, domain.ErrNotFound
+	}
 	var usr user
 	err := r.coll.Find(db.Cond{"id": id}).One(&usr)
 	if err != nil {
-		return domain.User{}, err
+		return domain.User{}, notFound(err)
 	}
 
 	return r.mapModelToDomain(usr), nil
@@ -66,60 +90,162 @@ func (r userRepository) Find(id uint64) (interface{}, error) {
 	var usr user
 	err := r.coll.Find(db.Cond{"id": id}).One(&usr)
 	if err != nil {
-		return domain.User{}, err
+		return domain.User{}, notFound(err)
 	}
 
 	return r.mapModelToDomain(usr), nil
 }
 
+func (r userRepository) FindAll(f domain.UserFilters, p domain.Pagination) (domain.Page[domain.User], error) {
+	cond := db.And(db.Cond{"deleted_date": nil})
+	if f.Deleted {
+		cond = db.And(db.Cond{"deleted_date IS NOT": nil})
+	}
+	if f.Role != "" {
+		cond = cond.And(db.Cond{"role": f.Role})
+	}
+	if f.Search != "" {
+		pattern := "%" + likeEscaper.Replace(f.Search) + "%"
+		cond = cond.And(db.Or(
+			db.Cond{"email ILIKE": pattern},
+			db.Cond{"first_name ILIKE": pattern},
+			db.Cond{"second_name ILIKE": pattern},
+		))
+	}
+
+	order, err := orderBy(p, userSortColumns, "id", "id")
+	if err != nil {
+		return domain.Page[domain.User]{}, err
+	}
+
+	var users []user
+	res := r.coll.Find(cond).OrderBy(order...)
+	total, pages, err := paginate(res, p, &users)
+	if err != nil {
+		return domain.Page[domain.User]{}, err
+	}
+
+	return domain.Page[domain.User]{
+		Items: r.mapModelToDomainCollection(users),
+		Total: total,
+		Pages: pages,
+	}, nil
+}
+
 func (r userRepository) Save(user domain.User) (domain.User, error) {
 	u := r.mapDomainToModel(user)
 	u.CreatedDate, u.UpdatedDate = time.Now(), time.Now()
 	err := r.coll.InsertReturning(&u)
 	if err != nil {
-		return domain.User{}, err
+		return domain.User{}, uniqueViolation(err, userConflicts)
 	}
 	return r.mapModelToDomain(u), nil
 }
 
+// Update writes the profile of the user. The columns owned by other
+// flows, e.g. the password or the role, are left as they are in the
+// database, so a profile edit can not undo a concurrent change of them.
+// A new email is left unverified.
 func (r userRepository) Update(user domain.User) (domain.User, error) {
-	u := r.mapDomainToModel(user)
-	u.UpdatedDate = time.Now()
-	err := r.coll.Find(db.Cond{"id": u.Id, "deleted_date": nil}).Update(&u)
+	return r.set(db.Cond{"id": user.Id}, map[string]interface{}{
+		"first_name":    user.FirstName,
+		"second_name":   user.SecondName,
+		"email":         user.Email,
+		"verified_date": db.Raw("CASE WHEN lower(email) = lower(?) THEN verified_date END", user.Email),
+	}, user.Id)
+}
+
+func (r userRepository) SetPassword(id uint64, hash string) (domain.User, error) {
+	return r.set(db.Cond{"id": id}, map[string]interface{}{"password": hash}, id)
+}
+
+func (r userRepository) SetRole(id uint64, role domain.Role) (domain.User, error) {
+	return r.set(db.Cond{"id": id}, map[string]interface{}{"role": role}, id)
+}
+
+// SetBlocked blocks the user at the given time, nil unblocks.
+func (r userRepository) SetBlocked(id uint64, blocked *time.Time) (domain.User, error) {
+	return r.set(db.Cond{"id": id}, map[string]interface{}{"blocked_date": blocked}, id)
+}
+
+// SetVerified marks the email as verified only while it is still the
+// email of the user, a verification of an address changed meanwhile
+// returns domain.ErrNotFound. An earlier verification date is kept.
+func (r userRepository) SetVerified(id uint64, email string) (domain.User, error) {
+	return r.set(db.Cond{"id": id, "email": email}, map[string]interface{}{
+		"verified_date": db.Raw("COALESCE(verified_date, ?)", time.Now()),
+	}, id)
+}
+
+// set updates only the given columns of a live user and reads it back,
+// domain.ErrNotFound is returned when nothing was updated.
+func (r userRepository) set(cond db.Cond, set map[string]interface{}, id uint64) (domain.User, error) {
+	cond["deleted_date"] = nil
+	set["updated_date"] = time.Now()
+	res, err := r.sess.SQL().
+		Update(UsersTableName).
+		Set(set).
+		Where(cond).
+		Exec()
+	if err != nil {
+		return domain.User{}, uniqueViolation(err, userConflicts)
+	}
+
+	n, err := res.RowsAffected()
 	if err != nil {
 		return domain.User{}, err
 	}
-	return r.mapModelToDomain(u), nil
+	if n == 0 {
+		return domain.User{}, domain.ErrNotFound
+	}
+	return r.FindById(id)
 }
 
 func (r userRepository) Delete(id uint64) error {
 	return r.coll.Find(db.Cond{"id": id, "deleted_date": nil}).Update(map[string]interface{}{"deleted_date": time.Now()})
 }
 
+func (r userRepository) Restore(id uint64) error {
+	err := r.coll.Find(db.Cond{"id": id, "deleted_date IS NOT": nil}).Update(map[string]interface{}{"deleted_date": nil, "updated_date": time.Now()})
+	return uniqueViolation(err, userConflicts)
+}
+
 func (r userRepository) mapDomainToModel(d domain.User) user {
 	return user{
-		Id:          d.Id,
-		Email:       d.Email,
-		Password:    d.Password,
-		FirstName:   d.FirstName,
-		SecondName:  d.SecondName,
-		Role:        d.Role,
-		CreatedDate: d.CreatedDate,
-		UpdatedDate: d.UpdatedDate,
-		DeletedDate: d.DeletedDate,
+		Id:           d.Id,
+		Email:        d.Email,
+		Password:     d.Password,
+		FirstName:    d.FirstName,
+		SecondName:   d.SecondName,
+		Role:         d.Role,
+		VerifiedDate: d.VerifiedDate,
+		BlockedDate:  d.BlockedDate,
+		CreatedDate:  d.CreatedDate,
+		UpdatedDate:  d.UpdatedDate,
+		DeletedDate:  d.DeletedDate,
 	}
 }
 
 func (r userRepository) mapModelToDomain(m user) domain.User {
 	return domain.User{
-		Id:          m.Id,
-		Email:       m.Email,
-		Password:    m.Password,
-		FirstName:   m.FirstName,
-		SecondName:  m.SecondName,
-		Role:        m.Role,
-		CreatedDate: m.CreatedDate,
-		UpdatedDate: m.UpdatedDate,
-		DeletedDate: m.DeletedDate,
+		Id:           m.Id,
+		Email:        m.Email,
+		Password:     m.Password,
+		FirstName:    m.FirstName,
+		SecondName:   m.SecondName,
+		Role:         m.Role,
+		VerifiedDate: m.VerifiedDate,
+		BlockedDate:  m.BlockedDate,
+		CreatedDate:  m.CreatedDate,
+		UpdatedDate:  m.UpdatedDate,
+		DeletedDate:  m.DeletedDate,
+	}
+}
+
+func (r userRepository) mapModelToDomainCollection(users []user) []domain.User {
+	res := make([]domain.User, 0, len(users))
+	for _, u := range users {
+		res = append(res, r.mapModelToDomain(u))
 	}
+	return res
 }
@@ -0,0 +1,150 @@
+package database
+
+import (
+	"time"
+
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+	"github.com/upper/db/v4"
+)
+
+const WebhookDeliveriesTableName = "webhook_deliveries"
+
+type webhookDelivery struct {
+	Id              uint64                `db:"id,omitempty"`
+	WebhookId       uint64                `db:"webhook_id"`
+	EventType       domain.EventType      `db:"event_type"`
+	Payload         string                `db:"payload"`
+	Status          domain.DeliveryStatus `db:"status"`
+	Attempts        int                   `db:"attempts"`
+	ResponseCode    *int                  `db:"response_code"`
+	Error           *string               `db:"error"`
+	NextAttemptDate *time.Time            `db:"next_attempt_date"`
+	DeliveredDate   *time.Time            `db:"delivered_date"`
+	CreatedDate     time.Time             `db:"created_date"`
+	UpdatedDate     time.Time             `db:"updated_date"`
+}
+
+var webhookDeliverySortColumns = map[string]string{
+	"id":          "id",
+	"eventType":   "event_type",
+	"status":      "status",
+	"createdDate": "created_date",
+}
+
+type WebhookDeliveryRepository interface {
+	Save(d domain.WebhookDelivery) (domain.WebhookDelivery, error)
+	Update(d domain.WebhookDelivery) (domain.WebhookDelivery, error)
+	FindDue(now time.Time, limit int) ([]domain.WebhookDelivery, error)
+	FindForWebhook(wId uint64, p domain.Pagination) (domain.Page[domain.WebhookDelivery], error)
+}
+
+type webhookDeliveryRepository struct {
+	coll db.Collection
+	sess db.Session
+}
+
+func NewWebhookDeliveryRepository(dbSession db.Session) WebhookDeliveryRepository {
+	return webhookDeliveryRepository{
+		coll: dbSession.Collection(WebhookDeliveriesTableName),
+		sess: dbSession,
+	}
+}
+
+func (r webhookDeliveryRepository) Save(d domain.WebhookDelivery) (domain.WebhookDelivery, error) {
+	dlv := r.mapDomainToModel(d)
+	dlv.CreatedDate, dlv.UpdatedDate = time.Now(), time.Now()
+	err := r.coll.InsertReturning(&dlv)
+	if err != nil {
+		return domain.WebhookDelivery{}, err
+	}
+	d = r.mapModelToDomain(dlv)
+	return d, nil
+}
+
+func (r webhookDeliveryRepository) Update(d domain.WebhookDelivery) (domain.WebhookDelivery, error) {
+	dlv := r.mapDomainToModel(d)
+	dlv.UpdatedDate = time.Now()
+	err := r.coll.Find(db.Cond{"id": dlv.Id}).Update(&dlv)
+	if err != nil {
+		return domain.WebhookDelivery{}, err
+	}
+	d = r.mapModelToDomain(dlv)
+	return d, nil
+}
+
+func (r webhookDeliveryRepository) FindDue(now time.Time, limit int) ([]domain.WebhookDelivery, error) {
+	var dlvs []webhookDelivery
+	err := r.coll.
+		Find(db.Cond{"status": domain.DeliveryPending, "next_attempt_date <=": now}).
+		OrderBy("next_attempt_date").
+		Limit(limit).
+		All(&dlvs)
+	if err != nil {
+		return nil, err
+	}
+	res := r.mapModelToDomainCollection(dlvs)
+	return res, nil
+}
+
+func (r webhookDeliveryRepository) FindForWebhook(wId uint64, p domain.Pagination) (domain.Page[domain.WebhookDelivery], error) {
+	order, err := orderBy(p, webhookDeliverySortColumns, "-id", "id")
+	if err != nil {
+		return domain.Page[domain.WebhookDelivery]{}, err
+	}
+
+	var dlvs []webhookDelivery
+	res := r.coll.Find(db.Cond{"webhook_id": wId}).
+		OrderBy(order...)
+	total, pages, err := paginate(res, p, &dlvs)
+	if err != nil {
+		return domain.Page[domain.WebhookDelivery]{}, err
+	}
+	return domain.Page[domain.WebhookDelivery]{
+		Items: r.mapModelToDomainCollection(dlvs),
+		Total: total,
+		Pages: pages,
+	}, nil
+}
+
+func (r webhookDeliveryRepository) mapDomainToModel(d domain.WebhookDelivery) webhookDelivery {
+	return webhookDelivery{
+		Id:              d.Id,
+		WebhookId:       d.WebhookId,
+		EventType:       d.EventType,
+		Payload:         d.Payload,
+		Status:          d.Status,
+		Attempts:        d.Attempts,
+		ResponseCode:    d.ResponseCode,
+		Error:           d.Error,
+		NextAttemptDate: d.NextAttemptDate,
+		DeliveredDate:   d.DeliveredDate,
+		CreatedDate:     d.CreatedDate,
+		UpdatedDate:     d.UpdatedDate,
+	}
+}
+
+func (r webhookDeliveryRepository) mapModelToDomain(d webhookDelivery) domain.WebhookDelivery {
+	return domain.WebhookDelivery{
+		Id:              d.Id,
+		WebhookId:       d.WebhookId,
+		EventType:       d.EventType,
+		Payload:         d.Payload,
+		Status:          d.Status,
+		Attempts:        d.Attempts,
+		ResponseCode:    d.ResponseCode,
+		Error:           d.Error,
+		NextAttemptDate: d.NextAttemptDate,
+		DeliveredDate:   d.DeliveredDate,
+		CreatedDate:     d.CreatedDate,
+		UpdatedDate:     d.UpdatedDate,
+	}
+}
+
+func (r webhookDeliveryRepository) mapModelToDomainCollection(dlvs []webhookDelivery) []domain.WebhookDelivery {
+	var res []domain.WebhookDelivery
+	for _, d := range dlvs {
+		dlv := r.mapModelToDomain(d)
+		res = append(res, dlv)
+	}
+	return res
+}
@@ -0,0 +1,167 @@
+package database
+
+import (
+	"strings"
+	"time"
+
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+	"github.com/upper/db/v4"
+)
+
+const WebhooksTableName = "webhooks"
+
+type webhook struct {
+	Id             uint64     `db:"id,omitempty"`
+	OrganizationId uint64     `db:"organization_id"`
+	UserId         uint64     `db:"user_id"`
+	Url            string     `db:"url"`
+	Secret         string     `db:"secret"`
+	Events         string     `db:"events"`
+	Enabled        bool       `db:"enabled"`
+	CreatedDate    time.Time  `db:"created_date"`
+	UpdatedDate    time.Time  `db:"updated_date"`
+	DeletedDate    *time.Time `db:"deleted_date"`
+}
+
+var webhookSortColumns = map[string]string{
+	"id":          "id",
+	"url":         "url",
+	"createdDate": "created_date",
+	"updatedDate": "updated_date",
+}
+
+type WebhookRepository interface {
+	Save(w domain.Webhook) (domain.Webhook, error)
+	Update(w domain.Webhook) (domain.Webhook, error)
+	FindById(id uint64) (domain.Webhook, error)
+	FindForOrganization(oId uint64, p domain.Pagination) (domain.Page[domain.Webhook], error)
+	FindEnabledForOrganization(oId uint64) ([]domain.Webhook, error)
+	Delete(id uint64) error
+}
+
+type webhookRepository struct {
+	coll db.Collection
+	sess db.Session
+}
+
+func NewWebhookRepository(dbSession db.Session) WebhookRepository {
+	return webhookRepository{
+		coll: dbSession.Collection(WebhooksTableName),
+		sess: dbSession,
+	}
+}
+
+func (r webhookRepository) Save(w domain.Webhook) (domain.Webhook, error) {
+	hook := r.mapDomainToModel(w)
+	hook.CreatedDate, hook.UpdatedDate = time.Now(), time.Now()
+	err := r.coll.InsertReturning(&hook)
+	if err != nil {
+		return domain.Webhook{}, err
+	}
+	w = r.mapModelToDomain(hook)
+	return w, nil
+}
+
+func (r webhookRepository) Update(w domain.Webhook) (domain.Webhook, error) {
+	hook := r.mapDomainToModel(w)
+	hook.UpdatedDate = time.Now()
+	err := r.coll.Find(db.Cond{"id": hook.Id, "deleted_date": nil}).Update(&hook)
+	if err != nil {
+		return domain.Webhook{}, err
+	}
+	w = r.mapModelToDomain(hook)
+	return w, nil
+}
+
+func (r webhookRepository) FindById(id uint64) (domain.Webhook, error) {
+	var hook webhook
+	err := r.coll.Find(db.Cond{"id": id, "deleted_date": nil}).One(&hook)
+	if err != nil {
+		return domain.Webhook{}, notFound(err)
+	}
+	w := r.mapModelToDomain(hook)
+	return w, nil
+}
+
+func (r webhookRepository) FindForOrganization(oId uint64, p domain.Pagination) (domain.Page[domain.Webhook], error) {
+	order, err := orderBy(p, webhookSortColumns, "id", "id")
+	if err != nil {
+		return domain.Page[domain.Webhook]{}, err
+	}
+
+	var hooks []webhook
+	res := r.coll.Find(db.Cond{"organization_id": oId, "deleted_date": nil}).
+		OrderBy(order...)
+	total, pages, err := paginate(res, p, &hooks)
+	if err != nil {
+		return domain.Page[domain.Webhook]{}, err
+	}
+	return domain.Page[domain.Webhook]{
+		Items: r.mapModelToDomainCollection(hooks),
+		Total: total,
+		Pages: pages,
+	}, nil
+}
+
+func (r webhookRepository) FindEnabledForOrganization(oId uint64) ([]domain.Webhook, error) {
+	var hooks []webhook
+	err := r.coll.Find(db.Cond{"organization_id": oId, "enabled": true, "deleted_date": nil}).All(&hooks)
+	if err != nil {
+		return nil, err
+	}
+	res := r.mapModelToDomainCollection(hooks)
+	return res, nil
+}
+
+func (r webhookRepository) Delete(id uint64) error {
+	return r.coll.Find(db.Cond{"id": id, "deleted_date": nil}).Update(map[string]interface{}{"deleted_date": time.Now()})
+}
+
+func (r webhookRepository) mapDomainToModel(d domain.Webhook) webhook {
+	events := make([]string, 0, len(d.Events))
+	for _, e := range d.Events {
+		events = append(events, string(e))
+	}
+	return webhook{
+		Id:             d.Id,
+		OrganizationId: d.OrganizationId,
+		UserId:         d.UserId,
+		Url:            d.Url,
+		Secret:         d.Secret,
+		Events:         strings.Join(events, ","),
+		Enabled:        d.Enabled,
+		CreatedDate:    d.CreatedDate,
+		UpdatedDate:    d.UpdatedDate,
+		DeletedDate:    d.DeletedDate,
+	}
+}
+
+func (r webhookRepository) mapModelToDomain(d webhook) domain.Webhook {
+	var events []domain.EventType
+	if d.Events != "" {
+		for _, e := range strings.Split(d.Events, ",") {
+			events = append(events, domain.EventType(e))
+		}
+	}
+	return domain.Webhook{
+		Id:             d.Id,
+		OrganizationId: d.OrganizationId,
+		UserId:         d.UserId,
+		Url:            d.Url,
+		Secret:         d.Secret,
+		Events:         events,
+		Enabled:        d.Enabled,
+		CreatedDate:    d.CreatedDate,
+		UpdatedDate:    d.UpdatedDate,
+		DeletedDate:    d.DeletedDate,
+	}
+}
+
+func (r webhookRepository) mapModelToDomainCollection(hooks []webhook) []domain.Webhook {
+	var res []domain.Webhook
+	for _, h := range hooks {
+		hook := r.mapModelToDomain(h)
+		res = append(res, hook)
+	}
+	return res
+}
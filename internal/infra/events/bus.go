@@ -0,0 +1,67 @@
+package events
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+)
+
+const subscriberBufferSize = 64
+
+type Bus interface {
+	Publish(e domain.Event)
+	Subscribe() (<-chan domain.Event, func())
+}
+
+type bus struct {
+	mu          sync.RWMutex
+	subscribers map[chan domain.Event]struct{}
+}
+
+func NewBus() Bus {
+	return &bus{
+		subscribers: make(map[chan domain.Event]struct{}),
+	}
+}
+
+// Publish never blocks the caller: a subscriber that does not keep up
+// loses the event instead of slowing down the request that produced it.
+func (b *bus) Publish(e domain.Event) {
+	if e.Date.IsZero() {
+		e.Date = time.Now()
+	}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for ch := range b.subscribers {
+		select {
+		case ch <- e:
+		default:
+			log.Printf("EventBus: subscriber is full, %s event dropped", e.Type)
+		}
+	}
+}
+
+// Subscribe returns a channel with all further events and a function
+// that must be called to release it.
+func (b *bus) Subscribe() (<-chan domain.Event, func()) {
+	ch := make(chan domain.Event, subscriberBufferSize)
+
+	b.mu.Lock()
+	b.subscribers[ch] = struct{}{}
+	b.mu.Unlock()
+
+	var once sync.Once
+	unsubscribe := func() {
+		once.Do(func() {
+			b.mu.Lock()
+			delete(b.subscribers, ch)
+			b.mu.Unlock()
+			close(ch)
+		})
+	}
+
+	return ch, unsubscribe
+}
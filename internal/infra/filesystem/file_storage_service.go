@@ -0,0 +1,44 @@
+package filesystem
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+type FileStorageService interface {
+	SaveFile(filename string, content []byte) error
+	RemoveFile(filename string) error
+}
+
+type fileStorageService struct {
+	loc string
+}
+
+func NewFileStorageService(location string) FileStorageService {
+	return fileStorageService{
+		loc: location,
+	}
+}
+
+// SaveFile writes the content under the storage location, filename may
+// contain subdirectories which are created on demand.
+func (s fileStorageService) SaveFile(filename string, content []byte) error {
+	location := filepath.Join(s.loc, filepath.Clean("/"+filename))
+	err := os.MkdirAll(filepath.Dir(location), os.ModePerm)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(location, content, 0644)
+}
+
+func (s fileStorageService) RemoveFile(filename string) error {
+	location := filepath.Join(s.loc, filepath.Clean("/"+filename))
+	err := os.Remove(location)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
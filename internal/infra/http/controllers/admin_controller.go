@@ -0,0 +1,215 @@
+package controllers
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/app"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/http/requests"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/http/resources"
+)
+
+type AdminController struct {
+	adminService app.AdminService
+}
+
+func NewAdminController(as app.AdminService) AdminController {
+	return AdminController{
+		adminService: as,
+	}
+}
+
+func (c AdminController) FindUsers() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		f, err := requests.UserFilters(r)
+		if err != nil {
+			log.Printf("AdminController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+		p, err := requests.Pagination(r)
+		if err != nil {
+			log.Printf("AdminController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		users, err := c.adminService.FindUsers(f, p)
+		if err != nil {
+			log.Printf("AdminController: %s", err)
+			Error(w, err)
+			return
+		}
+
+		var usersDto resources.UsersDto
+		Success(w, usersDto.DomainToDto(users))
+	}
+}
+
+func (c AdminController) FindUser() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		u := r.Context().Value(PathUserKey).(domain.User)
+
+		var userDto resources.UserDto
+		Success(w, userDto.DomainToDto(u))
+	}
+}
+
+func (c AdminController) BlockUser() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		admin := r.Context().Value(UserKey).(domain.User)
+		u := r.Context().Value(PathUserKey).(domain.User)
+
+		u, err := c.adminService.BlockUser(u, admin.Id)
+		if err != nil {
+			log.Printf("AdminController: %s", err)
+			Error(w, err)
+			return
+		}
+
+		var userDto resources.UserDto
+		Success(w, userDto.DomainToDto(u))
+	}
+}
+
+func (c AdminController) UnblockUser() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		u := r.Context().Value(PathUserKey).(domain.User)
+
+		u, err := c.adminService.UnblockUser(u)
+		if err != nil {
+			log.Printf("AdminController: %s", err)
+			Error(w, err)
+			return
+		}
+
+		var userDto resources.UserDto
+		Success(w, userDto.DomainToDto(u))
+	}
+}
+
+func (c AdminController) ChangeRole() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		role, err := requests.Bind(r, requests.UserRoleRequest{}, domain.Role(""))
+		if err != nil {
+			log.Printf("AdminController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		admin := r.Context().Value(UserKey).(domain.User)
+		u := r.Context().Value(PathUserKey).(domain.User)
+		u, err = c.adminService.ChangeRole(u, role, admin.Id)
+		if err != nil {
+			log.Printf("AdminController: %s", err)
+			Error(w, err)
+			return
+		}
+
+		var userDto resources.UserDto
+		Success(w, userDto.DomainToDto(u))
+	}
+}
+
+func (c AdminController) RestoreUser() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		u := r.Context().Value(PathUserKey).(domain.User)
+
+		u, err := c.adminService.RestoreUser(u)
+		if err != nil {
+			log.Printf("AdminController: %s", err)
+			Error(w, err)
+			return
+		}
+
+		var userDto resources.UserDto
+		Success(w, userDto.DomainToDto(u))
+	}
+}
+
+func (c AdminController) FindOrganization() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		org := r.Context().Value(OrgKey).(domain.Organization)
+
+		var orgDto resources.OrgDto
+		Success(w, orgDto.DomainToDto(org))
+	}
+}
+
+func (c AdminController) FindMembers() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		org := r.Context().Value(OrgKey).(domain.Organization)
+		f, err := requests.MemberFilters(r)
+		if err != nil {
+			log.Printf("AdminController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+		p, err := requests.Pagination(r)
+		if err != nil {
+			log.Printf("AdminController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		f.OrganizationId = org.Id
+		mems, err := c.adminService.FindMembers(f, p)
+		if err != nil {
+			log.Printf("AdminController: %s", err)
+			Error(w, err)
+			return
+		}
+
+		var memsDto resources.MembersDto
+		Success(w, memsDto.DomainToDto(mems))
+	}
+}
+
+func (c AdminController) RestoreOrganization() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		org := r.Context().Value(OrgKey).(domain.Organization)
+
+		org, err := c.adminService.RestoreOrganization(org)
+		if err != nil {
+			log.Printf("AdminController: %s", err)
+			Error(w, err)
+			return
+		}
+
+		var orgDto resources.OrgDto
+		Success(w, orgDto.DomainToDto(org))
+	}
+}
+
+func (c AdminController) RestoreRoom() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		rom := r.Context().Value(RoomKey).(domain.Room)
+
+		rom, err := c.adminService.RestoreRoom(rom)
+		if err != nil {
+			log.Printf("AdminController: %s", err)
+			Error(w, err)
+			return
+		}
+
+		var romDto resources.RomDto
+		Success(w, romDto.DomainToDto(rom))
+	}
+}
+
+func (c AdminController) RestoreDevice() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		dev := r.Context().Value(DeviceKey).(domain.Device)
+
+		dev, err := c.adminService.RestoreDevice(dev)
+		if err != nil {
+			log.Printf("AdminController: %s", err)
+			Error(w, err)
+			return
+		}
+
+		var devDto resources.DevDto
+		Success(w, devDto.DomainToDto(dev))
+	}
+}
@@ -0,0 +1,99 @@
+package controllers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/app"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/http/requests"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/http/resources"
+)
+
+type AlertController struct {
+	alertService app.AlertService
+}
+
+func NewAlertController(as app.AlertService) AlertController {
+	return AlertController{
+		alertService: as,
+	}
+}
+
+func (c AlertController) FindForOrganization() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := r.Context().Value(UserKey).(domain.User)
+		orgId, err := organizationParam(r)
+		if err != nil {
+			log.Printf("AlertController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		var status *domain.AlertStatus
+		if s := r.URL.Query().Get("status"); s != "" {
+			st := domain.AlertStatus(s)
+			status = &st
+		}
+		p, err := requests.Pagination(r)
+		if err != nil {
+			log.Printf("AlertController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		alerts, err := c.alertService.FindForOrganization(orgId, status, p, user.Id)
+		if err != nil {
+			log.Printf("AlertController: %s", err)
+			Error(w, err)
+			return
+		}
+
+		var alertsDto resources.AlertsDto
+		Success(w, alertsDto.DomainToDto(alerts))
+	}
+}
+
+func (c AlertController) Find() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := r.Context().Value(UserKey).(domain.User)
+		alert := r.Context().Value(AlertKey).(domain.Alert)
+
+		err := c.alertService.CheckAccess(alert.OrganizationId, user.Id, domain.ViewPermission)
+		if err != nil {
+			log.Printf("AlertController: %s", err)
+			Error(w, err)
+			return
+		}
+
+		var alertDto resources.AlertDto
+		Success(w, alertDto.DomainToDto(alert))
+	}
+}
+
+func (c AlertController) Acknowledge() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := r.Context().Value(UserKey).(domain.User)
+		alert := r.Context().Value(AlertKey).(domain.Alert)
+
+		alert, err := c.alertService.Acknowledge(alert, user.Id)
+		if err != nil {
+			log.Printf("AlertController: %s", err)
+			Error(w, err)
+			return
+		}
+
+		var alertDto resources.AlertDto
+		Success(w, alertDto.DomainToDto(alert))
+	}
+}
+
+func organizationParam(r *http.Request) (uint64, error) {
+	orgId, err := strconv.ParseUint(r.URL.Query().Get("organizationId"), 10, 64)
+	if err != nil {
+		return 0, errors.New("invalid organizationId parameter(only non-negative integers)")
+	}
+	return orgId, nil
+}
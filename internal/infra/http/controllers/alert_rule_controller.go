@@ -0,0 +1,133 @@
+package controllers
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/app"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/http/requests"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/http/resources"
+)
+
+type AlertRuleController struct {
+	alertRuleService app.AlertRuleService
+}
+
+func NewAlertRuleController(ars app.AlertRuleService) AlertRuleController {
+	return AlertRuleController{
+		alertRuleService: ars,
+	}
+}
+
+func (c AlertRuleController) Save() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := r.Context().Value(UserKey).(domain.User)
+		rule, err := requests.Bind(r, requests.AlertRuleRequest{}, domain.AlertRule{})
+		if err != nil {
+			log.Printf("AlertRuleController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		rule, err = c.alertRuleService.Save(rule, user.Id)
+		if err != nil {
+			log.Printf("AlertRuleController: %s", err)
+			Error(w, err)
+			return
+		}
+
+		var ruleDto resources.AlertRuleDto
+		Created(w, ruleDto.DomainToDto(rule))
+	}
+}
+
+func (c AlertRuleController) FindForOrganization() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := r.Context().Value(UserKey).(domain.User)
+		orgId, err := organizationParam(r)
+		if err != nil {
+			log.Printf("AlertRuleController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+		p, err := requests.Pagination(r)
+		if err != nil {
+			log.Printf("AlertRuleController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		rules, err := c.alertRuleService.FindForOrganization(orgId, p, user.Id)
+		if err != nil {
+			log.Printf("AlertRuleController: %s", err)
+			Error(w, err)
+			return
+		}
+
+		var rulesDto resources.AlertRulesDto
+		Success(w, rulesDto.DomainToDto(rules))
+	}
+}
+
+func (c AlertRuleController) Find() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := r.Context().Value(UserKey).(domain.User)
+		rule := r.Context().Value(RuleKey).(domain.AlertRule)
+
+		err := c.alertRuleService.CheckAccess(rule.OrganizationId, user.Id, domain.ViewPermission)
+		if err != nil {
+			log.Printf("AlertRuleController: %s", err)
+			Error(w, err)
+			return
+		}
+
+		var ruleDto resources.AlertRuleDto
+		Success(w, ruleDto.DomainToDto(rule))
+	}
+}
+
+func (c AlertRuleController) Update() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := r.Context().Value(UserKey).(domain.User)
+		upd, err := requests.Bind(r, requests.UpdateAlertRuleRequest{}, domain.AlertRule{})
+		if err != nil {
+			log.Printf("AlertRuleController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		rule := r.Context().Value(RuleKey).(domain.AlertRule)
+		rule.Name = upd.Name
+		rule.Units = upd.Units
+		rule.Operator = upd.Operator
+		rule.Threshold = upd.Threshold
+		rule.Duration = upd.Duration
+		rule.Enabled = upd.Enabled
+		rule, err = c.alertRuleService.Update(rule, user.Id)
+		if err != nil {
+			log.Printf("AlertRuleController: %s", err)
+			Error(w, err)
+			return
+		}
+
+		var ruleDto resources.AlertRuleDto
+		Success(w, ruleDto.DomainToDto(rule))
+	}
+}
+
+func (c AlertRuleController) Delete() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := r.Context().Value(UserKey).(domain.User)
+		rule := r.Context().Value(RuleKey).(domain.AlertRule)
+
+		err := c.alertRuleService.Delete(rule, user.Id)
+		if err != nil {
+			log.Printf("AlertRuleController: %s", err)
+			Error(w, err)
+			return
+		}
+
+		Ok(w)
+	}
+}
@@ -7,39 +7,69 @@ import (
 	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/http/requests"
 	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/http/resources"
 	"log"
+	"net"
 	"net/http"
 )
 
 type AuthController struct {
-	authService app.AuthService
-	userService app.UserService
+	authService         app.AuthService
+	userService         app.UserService
+	invitationService   app.InvitationService
+	resetService        app.PasswordResetService
+	verificationService app.EmailVerificationService
 }
 
-func NewAuthController(as app.AuthService, us app.UserService) AuthController {
+func NewAuthController(
+	as app.AuthService,
+	us app.UserService,
+	is app.InvitationService,
+	prs app.PasswordResetService,
+	evs app.EmailVerificationService) AuthController {
 	return AuthController{
-		authService: as,
-		userService: us,
+		authService:         as,
+		userService:         us,
+		invitationService:   is,
+		resetService:        prs,
+		verificationService: evs,
 	}
 }
 
 func (c AuthController) Register() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		user, err := requests.Bind(r, requests.RegisterRequest{}, domain.User{})
+		reg, err := requests.Bind(r, requests.RegisterRequest{}, domain.Registration{})
 		if err != nil {
 			log.Printf("AuthController: %s", err)
 			BadRequest(w, errors.New("invalid request body"))
 			return
 		}
 
-		user, token, err := c.authService.Register(user)
+		// a bad invitation is turned down before the account is created
+		if reg.InvitationToken != "" {
+			_, err = c.invitationService.Check(reg.InvitationToken, reg.User.Email)
+			if err != nil {
+				log.Printf("AuthController: %s", err)
+				Error(w, err)
+				return
+			}
+		}
+
+		user, tokens, err := c.authService.Register(reg, clientFrom(r))
 		if err != nil {
 			log.Printf("AuthController: %s", err)
-			BadRequest(w, err)
+			Error(w, err)
 			return
 		}
 
+		if !user.Verified() {
+			err = c.verificationService.Send(user)
+			if err != nil {
+				// not fatal, the link can be requested again later
+				log.Printf("AuthController: %s", err)
+			}
+		}
+
 		var authDto resources.AuthDto
-		Success(w, authDto.DomainToDto(token, user))
+		Success(w, authDto.DomainToDto(tokens, user))
 	}
 }
 
@@ -52,15 +82,37 @@ func (c AuthController) Login() http.HandlerFunc {
 			return
 		}
 
-		u, token, err := c.authService.Login(user)
+		u, tokens, err := c.authService.Login(user, clientFrom(r))
+		if err != nil {
+			log.Printf("AuthController: %s", err)
+			Error(w, err)
+			return
+		}
+
+		var authDto resources.AuthDto
+		Success(w, authDto.DomainToDto(tokens, u))
+	}
+}
+
+// Refresh rotates the refresh token, the one sent in can not be used again.
+func (c AuthController) Refresh() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token, err := requests.Bind(r, requests.RefreshRequest{}, "")
+		if err != nil {
+			log.Printf("AuthController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		u, tokens, err := c.authService.Refresh(token, clientFrom(r))
 		if err != nil {
 			log.Printf("AuthController: %s", err)
-			InternalServerError(w, err)
+			Error(w, err)
 			return
 		}
 
 		var authDto resources.AuthDto
-		Success(w, authDto.DomainToDto(token, u))
+		Success(w, authDto.DomainToDto(tokens, u))
 	}
 }
 
@@ -70,10 +122,98 @@ func (c AuthController) Logout() http.HandlerFunc {
 		err := c.authService.Logout(sess)
 		if err != nil {
 			log.Printf("AuthController: %s", err)
-			InternalServerError(w, err)
+			Error(w, err)
+			return
+		}
+
+		noContent(w)
+	}
+}
+
+// ForgotPassword answers the same way whether the email is known or not.
+func (c AuthController) ForgotPassword() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		email, err := requests.Bind(r, requests.ForgotPasswordRequest{}, "")
+		if err != nil {
+			log.Printf("AuthController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		err = c.resetService.Request(email)
+		if err != nil {
+			log.Printf("AuthController: %s", err)
+			Error(w, err)
+			return
+		}
+
+		noContent(w)
+	}
+}
+
+func (c AuthController) ResetPassword() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		pc, err := requests.Bind(r, requests.ResetPasswordRequest{}, domain.PasswordChange{})
+		if err != nil {
+			log.Printf("AuthController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		err = c.resetService.Reset(pc)
+		if err != nil {
+			log.Printf("AuthController: %s", err)
+			Error(w, err)
 			return
 		}
 
 		noContent(w)
 	}
 }
+
+func (c AuthController) VerifyEmail() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token, err := requests.Bind(r, requests.VerifyEmailRequest{}, "")
+		if err != nil {
+			log.Printf("AuthController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		user, err := c.verificationService.Verify(token)
+		if err != nil {
+			log.Printf("AuthController: %s", err)
+			Error(w, err)
+			return
+		}
+
+		var userDto resources.UserDto
+		Success(w, userDto.DomainToDto(user))
+	}
+}
+
+func (c AuthController) ResendVerification() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := r.Context().Value(UserKey).(domain.User)
+		err := c.verificationService.Resend(user)
+		if err != nil {
+			log.Printf("AuthController: %s", err)
+			Error(w, err)
+			return
+		}
+
+		noContent(w)
+	}
+}
+
+func clientFrom(r *http.Request) domain.Client {
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		// already without a port when set from a proxy header
+		ip = r.RemoteAddr
+	}
+	return domain.Client{
+		UserAgent: r.UserAgent(),
+		Ip:        ip,
+	}
+}
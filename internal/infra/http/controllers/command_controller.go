@@ -0,0 +1,117 @@
+package controllers
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/app"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/http/requests"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/http/resources"
+)
+
+type CommandController struct {
+	commandService app.CommandService
+}
+
+func NewCommandController(cs app.CommandService) CommandController {
+	return CommandController{
+		commandService: cs,
+	}
+}
+
+func (c CommandController) Save() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := r.Context().Value(UserKey).(domain.User)
+		dev := r.Context().Value(DeviceKey).(domain.Device)
+		cmd, err := requests.Bind(r, requests.CommandRequest{}, domain.Command{})
+		if err != nil {
+			log.Printf("CommandController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		cmd, err = c.commandService.Save(dev, cmd, user.Id)
+		if err != nil {
+			log.Printf("CommandController: %s", err)
+			Error(w, err)
+			return
+		}
+
+		var cmdDto resources.CommandDto
+		Created(w, cmdDto.DomainToDto(cmd))
+	}
+}
+
+func (c CommandController) FindForDevice() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := r.Context().Value(UserKey).(domain.User)
+		dev := r.Context().Value(DeviceKey).(domain.Device)
+
+		var status *domain.CommandStatus
+		if s := r.URL.Query().Get("status"); s != "" {
+			st := domain.CommandStatus(s)
+			status = &st
+		}
+		p, err := requests.Pagination(r)
+		if err != nil {
+			log.Printf("CommandController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		cmds, err := c.commandService.FindForDevice(dev, status, p, user.Id)
+		if err != nil {
+			log.Printf("CommandController: %s", err)
+			Error(w, err)
+			return
+		}
+
+		var cmdsDto resources.CommandsDto
+		Success(w, cmdsDto.DomainToDto(cmds))
+	}
+}
+
+func (c CommandController) Poll() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		dev := r.Context().Value(DeviceKey).(domain.Device)
+
+		cmds, err := c.commandService.Poll(dev)
+		if err != nil {
+			log.Printf("CommandController: %s", err)
+			Error(w, err)
+			return
+		}
+
+		// a poll always hands over everything pending at once
+		var cmdsDto resources.CommandsDto
+		Success(w, cmdsDto.DomainToDto(domain.Page[domain.Command]{
+			Items: cmds,
+			Total: uint64(len(cmds)),
+			Pages: 1,
+		}))
+	}
+}
+
+func (c CommandController) Ack() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		dev := r.Context().Value(DeviceKey).(domain.Device)
+		cmd := r.Context().Value(CommandKey).(domain.Command)
+		ack, err := requests.Bind(r, requests.AckCommandRequest{}, domain.Command{})
+		if err != nil {
+			log.Printf("CommandController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		cmd, err = c.commandService.Ack(dev, cmd, ack)
+		if err != nil {
+			log.Printf("CommandController: %s", err)
+			Error(w, err)
+			return
+		}
+
+		var cmdDto resources.CommandDto
+		Success(w, cmdDto.DomainToDto(cmd))
+	}
+}
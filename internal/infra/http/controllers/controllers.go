@@ -2,9 +2,12 @@ package controllers
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
+
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
 )
 
 /* should not use built-in type string as key for value;
@@ -15,11 +18,19 @@ type CtxKey struct {
 }
 
 var (
-	UserKey   = CtxKey{Name: "user"}
-	SessKey   = CtxKey{Name: "sess"}
-	OrgKey    = CtxKey{Name: "org"}
-	RoomKey   = CtxKey{Name: "rom"}
-	DeviceKey = CtxKey{Name: "dev"}
+	UserKey       = CtxKey{Name: "user"}
+	SessKey       = CtxKey{Name: "sess"}
+	OrgKey        = CtxKey{Name: "org"}
+	RoomKey       = CtxKey{Name: "rom"}
+	DeviceKey     = CtxKey{Name: "dev"}
+	CommandKey    = CtxKey{Name: "cmd"}
+	RuleKey       = CtxKey{Name: "rul"}
+	AlertKey      = CtxKey{Name: "alr"}
+	WebhookKey    = CtxKey{Name: "hok"}
+	MemberKey     = CtxKey{Name: "mem"}
+	InvitationKey = CtxKey{Name: "inv"}
+	PathSessKey   = CtxKey{Name: "pss"}
+	PathUserKey   = CtxKey{Name: "pus"}
 )
 
 func Ok(w http.ResponseWriter) {
@@ -48,30 +59,85 @@ func Created(w http.ResponseWriter, body interface{}) {
 	}
 }
 
+// file writes a non-JSON body, a filename makes browsers download it.
+func file(w http.ResponseWriter, contentType, filename string, content []byte) {
+	w.Header().Set("Content-Type", contentType)
+	if filename != "" {
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	}
+	w.WriteHeader(http.StatusOK)
+
+	_, err := w.Write(content)
+	if err != nil {
+		log.Print(err)
+	}
+}
+
 func noContent(w http.ResponseWriter) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// Error answers with the status code the kind of err stands for,
+// anything that is not a domain.Error is an internal server error.
+func Error(w http.ResponseWriter, err error) {
+	var de *domain.Error
+	switch {
+	case errors.As(err, &de):
+		switch de.Kind {
+		case domain.NotFoundError:
+			NotFound(w, err)
+		case domain.ForbiddenError:
+			Forbidden(w, err)
+		case domain.ConflictError:
+			Conflict(w, err)
+		case domain.ValidationError:
+			BadRequest(w, err)
+		case domain.UnauthorizedError:
+			Unauthorized(w, err)
+		case domain.TooManyRequestsError:
+			TooManyRequests(w, err)
+		default:
+			InternalServerError(w, err)
+		}
+	default:
+		InternalServerError(w, err)
+	}
+}
+
 func BadRequest(w http.ResponseWriter, err error) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusBadRequest)
 
-	encodeErrorBody(w, err)
+	encodeErrorBody(w, err, domain.ValidationError)
 }
 
 func Forbidden(w http.ResponseWriter, err error) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusForbidden)
 
-	encodeErrorBody(w, err)
+	encodeErrorBody(w, err, domain.ForbiddenError)
+}
+
+func Conflict(w http.ResponseWriter, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusConflict)
+
+	encodeErrorBody(w, err, domain.ConflictError)
+}
+
+func TooManyRequests(w http.ResponseWriter, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusTooManyRequests)
+
+	encodeErrorBody(w, err, domain.TooManyRequestsError)
 }
 
 func InternalServerError(w http.ResponseWriter, err error) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusInternalServerError)
 
-	encodeErrorBody(w, err)
+	encodeErrorBody(w, err, internalError)
 }
 
 // nolint
@@ -79,7 +145,7 @@ func validationError(w http.ResponseWriter, err error) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusUnprocessableEntity)
 
-	encodeErrorBody(w, err)
+	encodeErrorBody(w, err, domain.ValidationError)
 }
 
 // nolint
@@ -87,27 +153,41 @@ func genericError(w http.ResponseWriter, err error) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusBadRequest)
 
-	encodeErrorBody(w, err)
+	encodeErrorBody(w, err, domain.ValidationError)
 }
 
 func NotFound(w http.ResponseWriter, err error) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusNotFound)
 
-	body := "Not Found"
-
-	if err != nil {
-		body = fmt.Sprint(err)
+	if err == nil {
+		err = errors.New("Not Found")
 	}
 
-	e := json.NewEncoder(w).Encode(map[string]interface{}{"error": body})
-	if e != nil {
-		log.Print(e)
-	}
+	encodeErrorBody(w, err, domain.NotFoundError)
 }
 
-func encodeErrorBody(w http.ResponseWriter, err error) {
-	e := json.NewEncoder(w).Encode(map[string]interface{}{"error": err.Error()})
+// internalError is the code of errors the client can not do anything about.
+const internalError domain.ErrorKind = "internal"
+
+// encodeErrorBody writes the message along with a code clients can rely on,
+// the code of a domain.Error wins over the one of the status.
+func encodeErrorBody(w http.ResponseWriter, err error, kind domain.ErrorKind) {
+	body := map[string]interface{}{
+		"error": err.Error(),
+		"code":  string(kind),
+	}
+	var de *domain.Error
+	if errors.As(err, &de) {
+		if de.Code != "" {
+			body["code"] = de.Code
+		}
+		if de.Field != "" {
+			body["field"] = de.Field
+		}
+	}
+
+	e := json.NewEncoder(w).Encode(body)
 	if e != nil {
 		log.Print(e)
 	}
@@ -117,5 +197,5 @@ func Unauthorized(w http.ResponseWriter, err error) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusUnauthorized)
 
-	encodeErrorBody(w, err)
+	encodeErrorBody(w, err, domain.UnauthorizedError)
 }
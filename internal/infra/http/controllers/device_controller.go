@@ -1,25 +1,24 @@
 package controllers
 
 import (
-	"fmt"
 	"log"
 	"net/http"
-	"strconv"
 
 	"github.com/BohdanBoriak/boilerplate-go-back/internal/app"
 	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
 	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/http/requests"
 	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/http/resources"
-	"github.com/gorilla/mux"
 )
 
 type DeviceController struct {
-	deviceService app.DeviceService
+	deviceService     app.DeviceService
+	deviceAuthService app.DeviceAuthService
 }
 
-func NewDeviceController(ds app.DeviceService) DeviceController {
+func NewDeviceController(ds app.DeviceService, das app.DeviceAuthService) DeviceController {
 	return DeviceController{
-		deviceService: ds,
+		deviceService:     ds,
+		deviceAuthService: das,
 	}
 }
 
@@ -36,11 +35,7 @@ func (c DeviceController) Save() http.HandlerFunc {
 		dev, err = c.deviceService.Save(dev, user.Id)
 		if err != nil {
 			log.Printf("DeviceController: %s", err)
-			if err.Error() == "access denied" {
-				Forbidden(w, err)
-			} else {
-				InternalServerError(w, err)
-			}
+			Error(w, err)
 			return
 		}
 
@@ -49,13 +44,26 @@ func (c DeviceController) Save() http.HandlerFunc {
 	}
 }
 
-func (c DeviceController) FindForRoom() http.HandlerFunc {
+func (c DeviceController) FindAll() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		dev := r.Context().Value(UserKey).(domain.User)
-		devs, err := c.deviceService.FindForRoom(dev.Id)
+		user := r.Context().Value(UserKey).(domain.User)
+		f, err := requests.DeviceFilters(r)
 		if err != nil {
 			log.Printf("DeviceController: %s", err)
-			InternalServerError(w, err)
+			BadRequest(w, err)
+			return
+		}
+		p, err := requests.Pagination(r)
+		if err != nil {
+			log.Printf("DeviceController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		devs, err := c.deviceService.FindAll(f, p, user.Id)
+		if err != nil {
+			log.Printf("DeviceController: %s", err)
+			Error(w, err)
 			return
 		}
 
@@ -67,12 +75,13 @@ func (c DeviceController) FindForRoom() http.HandlerFunc {
 
 func (c DeviceController) FindById() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		rom := r.Context().Value(RoomKey).(domain.Room)
+		user := r.Context().Value(UserKey).(domain.User)
 		dev := r.Context().Value(DeviceKey).(domain.Device)
 
-		if dev.RoomId != &rom.Id {
-			err := fmt.Errorf("access denied")
-			Forbidden(w, err)
+		err := c.deviceService.CheckAccess(dev, user.Id, domain.ViewPermission)
+		if err != nil {
+			log.Printf("DeviceController: %s", err)
+			Error(w, err)
 			return
 		}
 
@@ -81,9 +90,33 @@ func (c DeviceController) FindById() http.HandlerFunc {
 	}
 }
 
+// InventoryNumber lets clients check a number before they create a device.
+func (c DeviceController) InventoryNumber() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		number, err := requests.InventoryNumber(r)
+		if err != nil {
+			log.Printf("DeviceController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		available, err := c.deviceService.InventoryNumberAvailable(number)
+		if err != nil {
+			log.Printf("DeviceController: %s", err)
+			Error(w, err)
+			return
+		}
+
+		Success(w, resources.InventoryNumberDto{
+			InventoryNumber: number,
+			Available:       available,
+		})
+	}
+}
+
 func (c DeviceController) Update() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		rom := r.Context().Value(UserKey).(domain.Room)
+		user := r.Context().Value(UserKey).(domain.User)
 		dev, err := requests.Bind(r, requests.DeviceRequest{}, domain.Device{})
 		if err != nil {
 			log.Printf("DeviceController: %s", err)
@@ -92,9 +125,10 @@ func (c DeviceController) Update() http.HandlerFunc {
 		}
 
 		device := r.Context().Value(DeviceKey).(domain.Device)
-		if device.RoomId != &rom.Id {
-			err := fmt.Errorf("access denied")
-			Forbidden(w, err)
+		err = c.deviceService.CheckAccess(device, user.Id, domain.OperatePermission)
+		if err != nil {
+			log.Printf("DeviceController: %s", err)
+			Error(w, err)
 			return
 		}
 
@@ -108,7 +142,7 @@ func (c DeviceController) Update() http.HandlerFunc {
 		device, err = c.deviceService.Update(device)
 		if err != nil {
 			log.Printf("DeviceController: %s", err)
-			InternalServerError(w, err)
+			Error(w, err)
 			return
 		}
 
@@ -119,24 +153,26 @@ func (c DeviceController) Update() http.HandlerFunc {
 
 func (c DeviceController) SetDeviceToRoom() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		vars := mux.Vars(r)
-		deviceId, err := strconv.ParseUint(vars["deviceId"], 10, 64)
+		user := r.Context().Value(UserKey).(domain.User)
+		target, err := requests.Bind(r, requests.SetRoomRequest{}, domain.Device{})
 		if err != nil {
+			log.Printf("DeviceController: %s", err)
 			BadRequest(w, err)
 			return
 		}
 
-		var req requests.SetRoomRequest
-		dev, err := requests.Bind(r, req, domain.Device{})
+		dev := r.Context().Value(DeviceKey).(domain.Device)
+		err = c.deviceService.CheckAccess(dev, user.Id, domain.OperatePermission)
 		if err != nil {
-			BadRequest(w, err)
+			log.Printf("DeviceController: %s", err)
+			Error(w, err)
 			return
 		}
 
-		err = c.deviceService.SetDeviceToRoom(deviceId, *dev.RoomId)
+		err = c.deviceService.SetDeviceToRoom(dev, *target.RoomId)
 		if err != nil {
 			log.Printf("DeviceController: %s", err)
-			InternalServerError(w, err)
+			Error(w, err)
 			return
 		}
 
@@ -146,17 +182,73 @@ func (c DeviceController) SetDeviceToRoom() http.HandlerFunc {
 
 func (c DeviceController) RemoveDeviceFromRoom() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		vars := mux.Vars(r)
-		deviceId, err := strconv.ParseUint(vars["deviceId"], 10, 64)
+		user := r.Context().Value(UserKey).(domain.User)
+		dev := r.Context().Value(DeviceKey).(domain.Device)
+
+		err := c.deviceService.CheckAccess(dev, user.Id, domain.OperatePermission)
+		if err != nil {
+			log.Printf("DeviceController: %s", err)
+			Error(w, err)
+			return
+		}
+
+		err = c.deviceService.RemoveDeviceFromRoom(dev)
+		if err != nil {
+			log.Printf("DeviceController: %s", err)
+			Error(w, err)
+			return
+		}
+
+		Ok(w)
+	}
+}
+
+func (c DeviceController) SetPlacement() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := r.Context().Value(UserKey).(domain.User)
+		p, err := requests.Bind(r, requests.PlacementRequest{}, domain.Placement{})
 		if err != nil {
+			log.Printf("DeviceController: %s", err)
 			BadRequest(w, err)
 			return
 		}
 
-		err = c.deviceService.RemoveDeviceFromRoom(deviceId)
+		dev := r.Context().Value(DeviceKey).(domain.Device)
+		err = c.deviceService.CheckAccess(dev, user.Id, domain.OperatePermission)
 		if err != nil {
 			log.Printf("DeviceController: %s", err)
-			InternalServerError(w, err)
+			Error(w, err)
+			return
+		}
+
+		dev, err = c.deviceService.SetPlacement(dev, &p)
+		if err != nil {
+			log.Printf("DeviceController: %s", err)
+			Error(w, err)
+			return
+		}
+
+		var devDto resources.DevDto
+		Success(w, devDto.DomainToDto(dev))
+	}
+}
+
+func (c DeviceController) RemovePlacement() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := r.Context().Value(UserKey).(domain.User)
+		dev := r.Context().Value(DeviceKey).(domain.Device)
+
+		err := c.deviceService.CheckAccess(dev, user.Id, domain.OperatePermission)
+		if err != nil {
+			log.Printf("DeviceController: %s", err)
+			Error(w, err)
+			return
+		}
+
+		_, err = c.deviceService.SetPlacement(dev, nil)
+		if err != nil {
+			log.Printf("DeviceController: %s", err)
+			Error(w, err)
 			return
 		}
 
@@ -164,24 +256,97 @@ func (c DeviceController) RemoveDeviceFromRoom() http.HandlerFunc {
 	}
 }
 
+func (c DeviceController) Scene() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := r.Context().Value(UserKey).(domain.User)
+		room := r.Context().Value(RoomKey).(domain.Room)
+
+		devs, err := c.deviceService.FindPlacedForRoom(room.Id, user.Id)
+		if err != nil {
+			log.Printf("DeviceController: %s", err)
+			Error(w, err)
+			return
+		}
+
+		var sceneDto resources.RoomSceneDto
+		Success(w, sceneDto.DomainToDto(room, devs))
+	}
+}
+
 func (c DeviceController) Delete() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		rom := r.Context().Value(RoomKey).(domain.Room)
+		user := r.Context().Value(UserKey).(domain.User)
 		dev := r.Context().Value(DeviceKey).(domain.Device)
 
-		if dev.RoomId != &rom.Id {
-			err := fmt.Errorf("access denied")
-			Forbidden(w, err)
+		err := c.deviceService.CheckAccess(dev, user.Id, domain.ManagePermission)
+		if err != nil {
+			log.Printf("DeviceController: %s", err)
+			Error(w, err)
 			return
 		}
 
-		err := c.deviceService.Delete(dev.Id)
+		err = c.deviceService.Delete(dev)
 		if err != nil {
 			log.Printf("DeviceController: %s", err)
-			InternalServerError(w, err)
+			Error(w, err)
 			return
 		}
 
 		Ok(w)
 	}
 }
+
+func (c DeviceController) IssueToken() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := r.Context().Value(UserKey).(domain.User)
+		dev := r.Context().Value(DeviceKey).(domain.Device)
+
+		err := c.deviceService.CheckAccess(dev, user.Id, domain.ManagePermission)
+		if err != nil {
+			log.Printf("DeviceController: %s", err)
+			Error(w, err)
+			return
+		}
+
+		token, err := c.deviceAuthService.Issue(dev, user.Id)
+		if err != nil {
+			log.Printf("DeviceController: %s", err)
+			Error(w, err)
+			return
+		}
+
+		var tknDto resources.DeviceTokenDto
+		Created(w, tknDto.DomainToDto(token, dev))
+	}
+}
+
+func (c DeviceController) RevokeToken() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := r.Context().Value(UserKey).(domain.User)
+		dev := r.Context().Value(DeviceKey).(domain.Device)
+
+		err := c.deviceService.CheckAccess(dev, user.Id, domain.ManagePermission)
+		if err != nil {
+			log.Printf("DeviceController: %s", err)
+			Error(w, err)
+			return
+		}
+
+		err = c.deviceAuthService.Revoke(dev)
+		if err != nil {
+			log.Printf("DeviceController: %s", err)
+			Error(w, err)
+			return
+		}
+
+		noContent(w)
+	}
+}
+
+// Heartbeat has nothing to do by itself, the device auth middleware
+// already marks the device as seen.
+func (c DeviceController) Heartbeat() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		noContent(w)
+	}
+}
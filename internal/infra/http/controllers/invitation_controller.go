@@ -0,0 +1,115 @@
+package controllers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/app"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/http/requests"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/http/resources"
+)
+
+type InvitationController struct {
+	invitationService app.InvitationService
+}
+
+func NewInvitationController(is app.InvitationService) InvitationController {
+	return InvitationController{
+		invitationService: is,
+	}
+}
+
+func (c InvitationController) Save() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := r.Context().Value(UserKey).(domain.User)
+		org := r.Context().Value(OrgKey).(domain.Organization)
+		inv, err := requests.Bind(r, requests.InvitationRequest{}, domain.Invitation{})
+		if err != nil {
+			log.Printf("InvitationController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		inv.OrganizationId = org.Id
+		inv, err = c.invitationService.Save(inv, user.Id)
+		if err != nil {
+			log.Printf("InvitationController: %s", err)
+			Error(w, err)
+			return
+		}
+
+		var invDto resources.InvitationDto
+		Created(w, invDto.DomainToDto(inv))
+	}
+}
+
+func (c InvitationController) FindForOrganization() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := r.Context().Value(UserKey).(domain.User)
+		org := r.Context().Value(OrgKey).(domain.Organization)
+		p, err := requests.Pagination(r)
+		if err != nil {
+			log.Printf("InvitationController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		invs, err := c.invitationService.FindPendingForOrganization(org.Id, p, user.Id)
+		if err != nil {
+			log.Printf("InvitationController: %s", err)
+			Error(w, err)
+			return
+		}
+
+		var invsDto resources.InvitationsDto
+		Success(w, invsDto.DomainToDto(invs))
+	}
+}
+
+func (c InvitationController) Revoke() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := r.Context().Value(UserKey).(domain.User)
+		org := r.Context().Value(OrgKey).(domain.Organization)
+		inv := r.Context().Value(InvitationKey).(domain.Invitation)
+
+		if inv.OrganizationId != org.Id {
+			err := errors.New("invitation not found")
+			log.Printf("InvitationController: %s", err)
+			NotFound(w, err)
+			return
+		}
+
+		err := c.invitationService.Revoke(inv, user.Id)
+		if err != nil {
+			log.Printf("InvitationController: %s", err)
+			Error(w, err)
+			return
+		}
+
+		Ok(w)
+	}
+}
+
+func (c InvitationController) Accept() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := r.Context().Value(UserKey).(domain.User)
+		token, err := requests.Bind(r, requests.AcceptInvitationRequest{}, "")
+		if err != nil {
+			log.Printf("InvitationController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		mem, err := c.invitationService.Accept(token, user)
+		if err != nil {
+			log.Printf("InvitationController: %s", err)
+			Error(w, err)
+			return
+		}
+
+		var memDto resources.MemberDto
+		Created(w, memDto.DomainToDto(mem))
+	}
+}
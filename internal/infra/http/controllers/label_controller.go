@@ -0,0 +1,120 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/app"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/http/resources"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/labels"
+)
+
+type LabelController struct {
+	deviceService app.DeviceService
+	generator     labels.Generator
+}
+
+func NewLabelController(ds app.DeviceService, g labels.Generator) LabelController {
+	return LabelController{
+		deviceService: ds,
+		generator:     g,
+	}
+}
+
+// Device renders the device QR code, ?format=png|svg and ?size=<pixels>.
+func (c LabelController) Device() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := r.Context().Value(UserKey).(domain.User)
+		dev := r.Context().Value(DeviceKey).(domain.Device)
+
+		size := labels.DefaultSize
+		if s := r.URL.Query().Get("size"); s != "" {
+			var err error
+			size, err = strconv.Atoi(s)
+			if err != nil || size < labels.MinSize || size > labels.MaxSize {
+				err = fmt.Errorf("invalid size parameter(%d to %d pixels)", labels.MinSize, labels.MaxSize)
+				log.Printf("LabelController: %s", err)
+				BadRequest(w, err)
+				return
+			}
+		}
+
+		err := c.deviceService.CheckAccess(dev, user.Id, domain.ViewPermission)
+		if err != nil {
+			log.Printf("LabelController: %s", err)
+			Error(w, err)
+			return
+		}
+
+		var (
+			content     []byte
+			contentType string
+		)
+		switch r.URL.Query().Get("format") {
+		case "", "png":
+			content, err = c.generator.PNG(dev, size)
+			contentType = "image/png"
+		case "svg":
+			content, err = c.generator.SVG(dev, size)
+			contentType = "image/svg+xml"
+		default:
+			err = errors.New("invalid format parameter(png or svg)")
+			log.Printf("LabelController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+		if err != nil {
+			log.Printf("LabelController: %s", err)
+			Error(w, err)
+			return
+		}
+
+		file(w, contentType, "", content)
+	}
+}
+
+// Room renders a printable PDF sheet with labels of all devices in the room.
+func (c LabelController) Room() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := r.Context().Value(UserKey).(domain.User)
+		rom := r.Context().Value(RoomKey).(domain.Room)
+
+		devs, err := c.deviceService.FindForRoom(rom.Id, user.Id)
+		if err != nil {
+			log.Printf("LabelController: %s", err)
+			Error(w, err)
+			return
+		}
+
+		content, err := c.generator.RoomSheet(rom, devs)
+		if err != nil {
+			log.Printf("LabelController: %s", err)
+			Error(w, err)
+			return
+		}
+
+		file(w, "application/pdf", fmt.Sprintf("room-%d-labels.pdf", rom.Id), content)
+	}
+}
+
+// Lookup resolves a scanned device GUID to the device, its room and organization.
+func (c LabelController) Lookup() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := r.Context().Value(UserKey).(domain.User)
+		dev := r.Context().Value(DeviceKey).(domain.Device)
+
+		loc, err := c.deviceService.Locate(dev, user.Id)
+		if err != nil {
+			log.Printf("LabelController: %s", err)
+			Error(w, err)
+			return
+		}
+
+		var locDto resources.DeviceLocationDto
+		Success(w, locDto.DomainToDto(loc))
+	}
+}
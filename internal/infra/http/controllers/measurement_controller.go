@@ -0,0 +1,95 @@
+package controllers
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/app"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/http/requests"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/http/resources"
+)
+
+type MeasurementController struct {
+	measurementService app.MeasurementService
+}
+
+func NewMeasurementController(ms app.MeasurementService) MeasurementController {
+	return MeasurementController{
+		measurementService: ms,
+	}
+}
+
+func (c MeasurementController) Save() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := r.Context().Value(UserKey).(domain.User)
+		dev := r.Context().Value(DeviceKey).(domain.Device)
+		ms, err := requests.Bind(r, requests.MeasurementsRequest{}, []domain.Measurement{})
+		if err != nil {
+			log.Printf("MeasurementController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		ms, err = c.measurementService.Save(dev, ms, user.Id)
+		if err != nil {
+			log.Printf("MeasurementController: %s", err)
+			Error(w, err)
+			return
+		}
+
+		var msDto resources.MeasurementsDto
+		Created(w, msDto.DomainToDto(dev, ms))
+	}
+}
+
+func (c MeasurementController) Record() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		dev := r.Context().Value(DeviceKey).(domain.Device)
+		ms, err := requests.Bind(r, requests.MeasurementsRequest{}, []domain.Measurement{})
+		if err != nil {
+			log.Printf("MeasurementController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		ms, err = c.measurementService.Record(dev, ms)
+		if err != nil {
+			log.Printf("MeasurementController: %s", err)
+			Error(w, err)
+			return
+		}
+
+		var msDto resources.MeasurementsDto
+		Created(w, msDto.DomainToDto(dev, ms))
+	}
+}
+
+func (c MeasurementController) FindForDevice() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := r.Context().Value(UserKey).(domain.User)
+		dev := r.Context().Value(DeviceKey).(domain.Device)
+		from, to, err := requests.TimeRange(r)
+		if err != nil {
+			log.Printf("MeasurementController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+		p, err := requests.Pagination(r)
+		if err != nil {
+			log.Printf("MeasurementController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		ms, err := c.measurementService.FindForDevice(dev, from, to, p, user.Id)
+		if err != nil {
+			log.Printf("MeasurementController: %s", err)
+			Error(w, err)
+			return
+		}
+
+		var msDto resources.MeasurementPageDto
+		Success(w, msDto.DomainToDto(dev, ms))
+	}
+}
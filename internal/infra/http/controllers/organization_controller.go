@@ -1,7 +1,6 @@
 package controllers
 
 import (
-	"fmt"
 	"log"
 	"net/http"
 
@@ -13,11 +12,13 @@ import (
 
 type OrganizationController struct {
 	organizationService app.OrganizationService
+	memberService       app.OrganizationMemberService
 }
 
-func NewOrganizationController(os app.OrganizationService) OrganizationController {
+func NewOrganizationController(os app.OrganizationService, ms app.OrganizationMemberService) OrganizationController {
 	return OrganizationController{
 		organizationService: os,
+		memberService:       ms,
 	}
 }
 
@@ -31,11 +32,10 @@ func (c OrganizationController) Save() http.HandlerFunc {
 			return
 		}
 
-		org.UserId = user.Id
-		org, err = c.organizationService.Save(org)
+		org, err = c.organizationService.Save(org, user)
 		if err != nil {
 			log.Printf("OrganizationController: %s", err)
-			InternalServerError(w, err)
+			Error(w, err)
 			return
 		}
 
@@ -47,10 +47,17 @@ func (c OrganizationController) Save() http.HandlerFunc {
 func (c OrganizationController) FindForUser() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		user := r.Context().Value(UserKey).(domain.User)
-		orgs, err := c.organizationService.FindForUser(user.Id)
+		p, err := requests.Pagination(r)
 		if err != nil {
 			log.Printf("OrganizationController: %s", err)
-			InternalServerError(w, err)
+			BadRequest(w, err)
+			return
+		}
+
+		orgs, err := c.organizationService.FindForUser(user.Id, p)
+		if err != nil {
+			log.Printf("OrganizationController: %s", err)
+			Error(w, err)
 			return
 		}
 
@@ -65,9 +72,10 @@ func (c OrganizationController) Find() http.HandlerFunc {
 		user := r.Context().Value(UserKey).(domain.User)
 		org := r.Context().Value(OrgKey).(domain.Organization)
 
-		if org.UserId != user.Id {
-			err := fmt.Errorf("access denied")
-			Forbidden(w, err)
+		err := c.memberService.Authorize(org.Id, user.Id, domain.ViewPermission)
+		if err != nil {
+			log.Printf("OrganizationController: %s", err)
+			Error(w, err)
 			return
 		}
 
@@ -87,9 +95,10 @@ func (c OrganizationController) Update() http.HandlerFunc {
 		}
 
 		organization := r.Context().Value(OrgKey).(domain.Organization)
-		if organization.UserId != user.Id {
-			err := fmt.Errorf("access denied")
-			Forbidden(w, err)
+		err = c.memberService.Authorize(organization.Id, user.Id, domain.OwnPermission)
+		if err != nil {
+			log.Printf("OrganizationController: %s", err)
+			Error(w, err)
 			return
 		}
 
@@ -101,7 +110,7 @@ func (c OrganizationController) Update() http.HandlerFunc {
 		organization, err = c.organizationService.Update(organization)
 		if err != nil {
 			log.Printf("OrganizationController: %s", err)
-			InternalServerError(w, err)
+			Error(w, err)
 			return
 		}
 
@@ -115,16 +124,17 @@ func (c OrganizationController) Delete() http.HandlerFunc {
 		user := r.Context().Value(UserKey).(domain.User)
 		org := r.Context().Value(OrgKey).(domain.Organization)
 
-		if org.UserId != user.Id {
-			err := fmt.Errorf("access denied")
-			Forbidden(w, err)
+		err := c.memberService.Authorize(org.Id, user.Id, domain.OwnPermission)
+		if err != nil {
+			log.Printf("OrganizationController: %s", err)
+			Error(w, err)
 			return
 		}
 
-		err := c.organizationService.Delete(org.Id)
+		err = c.organizationService.Delete(org.Id)
 		if err != nil {
 			log.Printf("OrganizationController: %s", err)
-			InternalServerError(w, err)
+			Error(w, err)
 			return
 		}
 
@@ -0,0 +1,138 @@
+package controllers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/app"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/http/requests"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/http/resources"
+)
+
+type OrganizationMemberController struct {
+	memberService app.OrganizationMemberService
+}
+
+func NewOrganizationMemberController(ms app.OrganizationMemberService) OrganizationMemberController {
+	return OrganizationMemberController{
+		memberService: ms,
+	}
+}
+
+func (c OrganizationMemberController) Save() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := r.Context().Value(UserKey).(domain.User)
+		org := r.Context().Value(OrgKey).(domain.Organization)
+		mem, err := requests.Bind(r, requests.OrganizationMemberRequest{}, domain.OrganizationMember{})
+		if err != nil {
+			log.Printf("OrganizationMemberController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		mem.OrganizationId = org.Id
+		mem, err = c.memberService.Save(mem, user.Id)
+		if err != nil {
+			log.Printf("OrganizationMemberController: %s", err)
+			Error(w, err)
+			return
+		}
+
+		var memDto resources.MemberDto
+		Created(w, memDto.DomainToDto(mem))
+	}
+}
+
+func (c OrganizationMemberController) FindForOrganization() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := r.Context().Value(UserKey).(domain.User)
+		org := r.Context().Value(OrgKey).(domain.Organization)
+		f, err := requests.MemberFilters(r)
+		if err != nil {
+			log.Printf("OrganizationMemberController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+		p, err := requests.Pagination(r)
+		if err != nil {
+			log.Printf("OrganizationMemberController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		f.OrganizationId = org.Id
+		mems, err := c.memberService.FindForOrganization(f, p, user.Id)
+		if err != nil {
+			log.Printf("OrganizationMemberController: %s", err)
+			Error(w, err)
+			return
+		}
+
+		var memsDto resources.MembersDto
+		Success(w, memsDto.DomainToDto(mems))
+	}
+}
+
+func (c OrganizationMemberController) ChangeRole() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := r.Context().Value(UserKey).(domain.User)
+		target, err := requests.Bind(r, requests.MemberRoleRequest{}, domain.OrganizationMember{})
+		if err != nil {
+			log.Printf("OrganizationMemberController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		mem, ok := c.member(w, r)
+		if !ok {
+			return
+		}
+
+		mem, err = c.memberService.ChangeRole(mem, target.Role, user.Id)
+		if err != nil {
+			log.Printf("OrganizationMemberController: %s", err)
+			Error(w, err)
+			return
+		}
+
+		var memDto resources.MemberDto
+		Success(w, memDto.DomainToDto(mem))
+	}
+}
+
+func (c OrganizationMemberController) Delete() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := r.Context().Value(UserKey).(domain.User)
+		mem, ok := c.member(w, r)
+		if !ok {
+			return
+		}
+
+		err := c.memberService.Delete(mem, user.Id)
+		if err != nil {
+			log.Printf("OrganizationMemberController: %s", err)
+			Error(w, err)
+			return
+		}
+
+		Ok(w)
+	}
+}
+
+// member returns the member from the path, it has to belong
+// to the organization from the same path.
+func (c OrganizationMemberController) member(w http.ResponseWriter, r *http.Request) (domain.OrganizationMember, bool) {
+	org := r.Context().Value(OrgKey).(domain.Organization)
+	mem := r.Context().Value(MemberKey).(domain.OrganizationMember)
+
+	if mem.OrganizationId != org.Id {
+		err := errors.New("member not found")
+		log.Printf("OrganizationMemberController: %s", err)
+		NotFound(w, err)
+		return domain.OrganizationMember{}, false
+	}
+
+	return mem, true
+}
@@ -0,0 +1,54 @@
+package controllers
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/app"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/http/resources"
+)
+
+type PowerReportController struct {
+	powerReportService app.PowerReportService
+}
+
+func NewPowerReportController(ps app.PowerReportService) PowerReportController {
+	return PowerReportController{
+		powerReportService: ps,
+	}
+}
+
+func (c PowerReportController) RoomReport() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := r.Context().Value(UserKey).(domain.User)
+		rom := r.Context().Value(RoomKey).(domain.Room)
+
+		report, err := c.powerReportService.RoomReport(rom, user.Id)
+		if err != nil {
+			log.Printf("PowerReportController: %s", err)
+			Error(w, err)
+			return
+		}
+
+		var reportDto resources.RoomPowerReportDto
+		Success(w, reportDto.DomainToDto(report))
+	}
+}
+
+func (c PowerReportController) OrganizationReport() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := r.Context().Value(UserKey).(domain.User)
+		org := r.Context().Value(OrgKey).(domain.Organization)
+
+		report, err := c.powerReportService.OrganizationReport(org, user.Id)
+		if err != nil {
+			log.Printf("PowerReportController: %s", err)
+			Error(w, err)
+			return
+		}
+
+		var reportDto resources.OrgPowerReportDto
+		Success(w, reportDto.DomainToDto(report))
+	}
+}
@@ -1,7 +1,6 @@
 package controllers
 
 import (
-	"fmt"
 	"log"
 	"net/http"
 
@@ -34,11 +33,7 @@ func (c RoomController) Save() http.HandlerFunc {
 		rom, err = c.roomService.Save(rom, user.Id)
 		if err != nil {
 			log.Printf("RoomController: %s", err)
-			if err.Error() == "access denied" {
-				Forbidden(w, err)
-			} else {
-				InternalServerError(w, err)
-			}
+			Error(w, err)
 			return
 		}
 
@@ -49,11 +44,31 @@ func (c RoomController) Save() http.HandlerFunc {
 
 func (c RoomController) FindForOrganization() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		org := r.Context().Value(OrgKey).(domain.Organization)
-		roms, err := c.roomService.FindForOrganization(org.Id)
+		user := r.Context().Value(UserKey).(domain.User)
+		f, err := requests.RoomFilters(r)
+		if err != nil {
+			log.Printf("RoomController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+		p, err := requests.Pagination(r)
+		if err != nil {
+			log.Printf("RoomController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		err = c.roomService.CheckAccess(domain.Room{OrganizationId: f.OrganizationId}, user.Id, domain.ViewPermission)
 		if err != nil {
 			log.Printf("RoomController: %s", err)
-			InternalServerError(w, err)
+			Error(w, err)
+			return
+		}
+
+		roms, err := c.roomService.FindAll(f, p)
+		if err != nil {
+			log.Printf("RoomController: %s", err)
+			Error(w, err)
 			return
 		}
 
@@ -65,12 +80,13 @@ func (c RoomController) FindForOrganization() http.HandlerFunc {
 
 func (c RoomController) Find() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		org := r.Context().Value(OrgKey).(domain.Organization)
+		user := r.Context().Value(UserKey).(domain.User)
 		rom := r.Context().Value(RoomKey).(domain.Room)
 
-		if rom.OrganizationId != org.Id {
-			err := fmt.Errorf("access denied")
-			Forbidden(w, err)
+		err := c.roomService.CheckAccess(rom, user.Id, domain.ViewPermission)
+		if err != nil {
+			log.Printf("RoomController: %s", err)
+			Error(w, err)
 			return
 		}
 
@@ -81,7 +97,7 @@ func (c RoomController) Find() http.HandlerFunc {
 
 func (c RoomController) Update() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		org := r.Context().Value(OrgKey).(domain.Organization)
+		user := r.Context().Value(UserKey).(domain.User)
 		rom, err := requests.Bind(r, requests.RoomRequest{}, domain.Room{})
 		if err != nil {
 			log.Printf("RoomController: %s", err)
@@ -89,20 +105,26 @@ func (c RoomController) Update() http.HandlerFunc {
 			return
 		}
 
+		// moving a room to another organization needs rights in both of them
 		room := r.Context().Value(RoomKey).(domain.Room)
-		if room.OrganizationId != org.Id {
-			err := fmt.Errorf("access denied")
-			Forbidden(w, err)
+		err = c.roomService.CheckAccess(room, user.Id, domain.ManagePermission)
+		if err == nil && rom.OrganizationId != room.OrganizationId {
+			err = c.roomService.CheckAccess(rom, user.Id, domain.ManagePermission)
+		}
+		if err != nil {
+			log.Printf("RoomController: %s", err)
+			Error(w, err)
 			return
 		}
 
 		room.OrganizationId = rom.OrganizationId
 		room.Name = rom.Name
 		room.Description = rom.Description
+		room.PowerCapacity = rom.PowerCapacity
 		room, err = c.roomService.Update(room)
 		if err != nil {
 			log.Printf("RoomController: %s", err)
-			InternalServerError(w, err)
+			Error(w, err)
 			return
 		}
 
@@ -113,22 +135,72 @@ func (c RoomController) Update() http.HandlerFunc {
 
 func (c RoomController) Delete() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		org := r.Context().Value(OrgKey).(domain.Organization)
+		user := r.Context().Value(UserKey).(domain.User)
 		rom := r.Context().Value(RoomKey).(domain.Room)
 
-		if rom.OrganizationId != org.Id {
-			err := fmt.Errorf("access denied")
-			Forbidden(w, err)
+		err := c.roomService.CheckAccess(rom, user.Id, domain.ManagePermission)
+		if err != nil {
+			log.Printf("RoomController: %s", err)
+			Error(w, err)
 			return
 		}
 
-		err := c.roomService.Delete(rom.Id)
+		err = c.roomService.Delete(rom.Id)
 		if err != nil {
 			log.Printf("RoomController: %s", err)
-			InternalServerError(w, err)
+			Error(w, err)
 			return
 		}
 
 		Ok(w)
 	}
 }
+
+func (c RoomController) SaveAsset(asset domain.RoomAsset) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := r.Context().Value(UserKey).(domain.User)
+		rom := r.Context().Value(RoomKey).(domain.Room)
+
+		var (
+			file requests.UploadedFile
+			err  error
+		)
+		if asset == domain.RoomModel {
+			file, err = requests.Model(r)
+		} else {
+			file, err = requests.FloorPlan(r)
+		}
+		if err != nil {
+			log.Printf("RoomController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		rom, err = c.roomService.SaveAsset(rom, asset, file.Ext, file.Content, user.Id)
+		if err != nil {
+			log.Printf("RoomController: %s", err)
+			Error(w, err)
+			return
+		}
+
+		var romDto resources.RomDto
+		Success(w, romDto.DomainToDto(rom))
+	}
+}
+
+func (c RoomController) RemoveAsset(asset domain.RoomAsset) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := r.Context().Value(UserKey).(domain.User)
+		rom := r.Context().Value(RoomKey).(domain.Room)
+
+		rom, err := c.roomService.RemoveAsset(rom, asset, user.Id)
+		if err != nil {
+			log.Printf("RoomController: %s", err)
+			Error(w, err)
+			return
+		}
+
+		var romDto resources.RomDto
+		Success(w, romDto.DomainToDto(rom))
+	}
+}
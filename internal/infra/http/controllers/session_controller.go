@@ -0,0 +1,73 @@
+package controllers
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/app"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/http/requests"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/http/resources"
+)
+
+type SessionController struct {
+	sessionService app.SessionService
+}
+
+func NewSessionController(ss app.SessionService) SessionController {
+	return SessionController{
+		sessionService: ss,
+	}
+}
+
+func (c SessionController) FindForUser() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		sess := r.Context().Value(SessKey).(domain.Session)
+		p, err := requests.Pagination(r)
+		if err != nil {
+			log.Printf("SessionController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		ss, err := c.sessionService.FindForUser(sess.UserId, p)
+		if err != nil {
+			log.Printf("SessionController: %s", err)
+			Error(w, err)
+			return
+		}
+
+		var sessDto resources.SessionsDto
+		Success(w, sessDto.DomainToDto(ss, sess))
+	}
+}
+
+func (c SessionController) Delete() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		sess := r.Context().Value(SessKey).(domain.Session)
+		other := r.Context().Value(PathSessKey).(domain.Session)
+		err := c.sessionService.Revoke(other, sess)
+		if err != nil {
+			log.Printf("SessionController: %s", err)
+			Error(w, err)
+			return
+		}
+
+		noContent(w)
+	}
+}
+
+// DeleteOthers signs the user out everywhere but here.
+func (c SessionController) DeleteOthers() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		sess := r.Context().Value(SessKey).(domain.Session)
+		err := c.sessionService.RevokeOthers(sess)
+		if err != nil {
+			log.Printf("SessionController: %s", err)
+			Error(w, err)
+			return
+		}
+
+		noContent(w)
+	}
+}
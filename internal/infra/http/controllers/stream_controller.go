@@ -0,0 +1,173 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/app"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/events"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/http/resources"
+	"github.com/go-chi/jwtauth/v5"
+)
+
+const streamKeepAlive = 25 * time.Second
+
+// StreamController pushes bus events to clients as server-sent events,
+// one "event: <type>" message with an EventDto body per event.
+// Access is checked again on every keepalive and the stream ends once it
+// is lost or the access token used to open it expires.
+type StreamController struct {
+	eventBus      events.Bus
+	authService   app.AuthService
+	userService   app.UserService
+	memberService app.OrganizationMemberService
+}
+
+func NewStreamController(eb events.Bus, as app.AuthService, us app.UserService, ms app.OrganizationMemberService) StreamController {
+	return StreamController{
+		eventBus:      eb,
+		authService:   as,
+		userService:   us,
+		memberService: ms,
+	}
+}
+
+func (c StreamController) Organization() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := r.Context().Value(UserKey).(domain.User)
+		org := r.Context().Value(OrgKey).(domain.Organization)
+
+		err := c.memberService.Authorize(org.Id, user.Id, domain.ViewPermission)
+		if err != nil {
+			log.Printf("StreamController: %s", err)
+			Error(w, err)
+			return
+		}
+
+		c.stream(w, r, org.Id, func(e domain.Event) bool {
+			return e.OrganizationId == org.Id
+		})
+	}
+}
+
+func (c StreamController) Room() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := r.Context().Value(UserKey).(domain.User)
+		room := r.Context().Value(RoomKey).(domain.Room)
+
+		err := c.memberService.Authorize(room.OrganizationId, user.Id, domain.ViewPermission)
+		if err != nil {
+			log.Printf("StreamController: %s", err)
+			Error(w, err)
+			return
+		}
+
+		// besides everything that happens in the room itself, including devices
+		// moving in or out, subscribers get organization level events, e.g. the
+		// organization being deleted
+		c.stream(w, r, room.OrganizationId, func(e domain.Event) bool {
+			if e.OrganizationId != room.OrganizationId {
+				return false
+			}
+			if e.RoomId != nil || e.PrevRoomId != nil {
+				return (e.RoomId != nil && *e.RoomId == room.Id) ||
+					(e.PrevRoomId != nil && *e.PrevRoomId == room.Id)
+			}
+			return e.DeviceGUID == nil
+		})
+	}
+}
+
+func (c StreamController) stream(w http.ResponseWriter, r *http.Request, oId uint64, match func(domain.Event) bool) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		err := errors.New("streaming is not supported")
+		log.Printf("StreamController: %s", err)
+		Error(w, err)
+		return
+	}
+
+	evts, unsubscribe := c.eventBus.Subscribe()
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(streamKeepAlive)
+	defer keepAlive.Stop()
+
+	var expired <-chan time.Time
+	token, _, err := jwtauth.FromContext(r.Context())
+	if err == nil && token != nil && !token.Expiration().IsZero() {
+		expiry := time.NewTimer(time.Until(token.Expiration()))
+		defer expiry.Stop()
+		expired = expiry.C
+	}
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-expired:
+			return
+		case <-keepAlive.C:
+			err := c.checkAccess(r.Context(), oId)
+			if err != nil {
+				log.Printf("StreamController: %s", err)
+				return
+			}
+			_, err = fmt.Fprint(w, ": ping\n\n")
+			if err != nil {
+				return
+			}
+		case e, ok := <-evts:
+			if !ok {
+				return
+			}
+			if !match(e) {
+				continue
+			}
+			data, err := json.Marshal(resources.EventDto{}.DomainToDto(e))
+			if err != nil {
+				log.Printf("StreamController: %s", err)
+				continue
+			}
+			_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", e.Type, data)
+			if err != nil {
+				return
+			}
+		}
+		flusher.Flush()
+	}
+}
+
+// checkAccess repeats what the auth middleware and the handler checked
+// when the stream was opened: the session is alive, the user is not
+// blocked and still may view the organization.
+func (c StreamController) checkAccess(ctx context.Context, oId uint64) error {
+	sess := ctx.Value(SessKey).(domain.Session)
+	err := c.authService.Check(sess)
+	if err != nil {
+		return err
+	}
+
+	user, err := c.userService.FindById(sess.UserId)
+	if err != nil {
+		return err
+	}
+	if user.Blocked() {
+		return app.ErrUserBlocked
+	}
+
+	return c.memberService.Authorize(oId, user.Id, domain.ViewPermission)
+}
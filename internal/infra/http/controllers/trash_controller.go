@@ -0,0 +1,118 @@
+package controllers
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/app"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/http/requests"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/http/resources"
+)
+
+type TrashController struct {
+	trashService app.TrashService
+}
+
+func NewTrashController(ts app.TrashService) TrashController {
+	return TrashController{
+		trashService: ts,
+	}
+}
+
+func (c TrashController) FindRooms() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := r.Context().Value(UserKey).(domain.User)
+		org := r.Context().Value(OrgKey).(domain.Organization)
+		p, err := requests.Pagination(r)
+		if err != nil {
+			log.Printf("TrashController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		rooms, err := c.trashService.FindRooms(org.Id, p, user.Id)
+		if err != nil {
+			log.Printf("TrashController: %s", err)
+			Error(w, err)
+			return
+		}
+
+		var romsDto resources.RomsDto
+		Success(w, romsDto.DomainToDto(rooms))
+	}
+}
+
+func (c TrashController) FindDevices() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := r.Context().Value(UserKey).(domain.User)
+		org := r.Context().Value(OrgKey).(domain.Organization)
+		p, err := requests.Pagination(r)
+		if err != nil {
+			log.Printf("TrashController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		devs, err := c.trashService.FindDevices(org.Id, p, user.Id)
+		if err != nil {
+			log.Printf("TrashController: %s", err)
+			Error(w, err)
+			return
+		}
+
+		var devsDto resources.DevsDto
+		Success(w, devsDto.DomainToDto(devs))
+	}
+}
+
+func (c TrashController) RestoreOrganization() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := r.Context().Value(UserKey).(domain.User)
+		org := r.Context().Value(OrgKey).(domain.Organization)
+
+		org, err := c.trashService.RestoreOrganization(org, user.Id)
+		if err != nil {
+			log.Printf("TrashController: %s", err)
+			Error(w, err)
+			return
+		}
+
+		var orgDto resources.OrgDto
+		Success(w, orgDto.DomainToDto(org))
+	}
+}
+
+func (c TrashController) RestoreRoom() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := r.Context().Value(UserKey).(domain.User)
+		rom := r.Context().Value(RoomKey).(domain.Room)
+
+		rom, err := c.trashService.RestoreRoom(rom, user.Id)
+		if err != nil {
+			log.Printf("TrashController: %s", err)
+			Error(w, err)
+			return
+		}
+
+		var romDto resources.RomDto
+		Success(w, romDto.DomainToDto(rom))
+	}
+}
+
+func (c TrashController) RestoreDevice() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := r.Context().Value(UserKey).(domain.User)
+		dev := r.Context().Value(DeviceKey).(domain.Device)
+
+		dev, err := c.trashService.RestoreDevice(dev, user.Id)
+		if err != nil {
+			log.Printf("TrashController: %s", err)
+			Error(w, err)
+			return
+		}
+
+		var devDto resources.DevDto
+		Success(w, devDto.DomainToDto(dev))
+	}
+}
@@ -7,17 +7,20 @@ import (
 	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/http/resources"
 	"log"
 	"net/http"
+	"strings"
 )
 
 type UserController struct {
-	userService app.UserService
-	authService app.AuthService
+	userService         app.UserService
+	authService         app.AuthService
+	verificationService app.EmailVerificationService
 }
 
-func NewUserController(us app.UserService, as app.AuthService) UserController {
+func NewUserController(us app.UserService, as app.AuthService, evs app.EmailVerificationService) UserController {
 	return UserController{
-		userService: us,
-		authService: as,
+		userService:         us,
+		authService:         as,
+		verificationService: evs,
 	}
 }
 
@@ -37,22 +40,54 @@ func (c UserController) Update() http.HandlerFunc {
 			return
 		}
 
+		// a new address has to be confirmed all over again, the update
+		// leaves it unverified
 		u := r.Context().Value(UserKey).(domain.User)
+		emailChanged := !strings.EqualFold(u.Email, user.Email)
 		u.FirstName = user.FirstName
 		u.SecondName = user.SecondName
 		u.Email = user.Email
 		user, err = c.userService.Update(u)
 		if err != nil {
 			log.Printf("UserController: %s", err)
-			InternalServerError(w, err)
+			Error(w, err)
 			return
 		}
 
+		if emailChanged {
+			err = c.verificationService.Send(user)
+			if err != nil {
+				log.Printf("UserController: %s", err)
+			}
+		}
+
 		var userDto resources.UserDto
 		Success(w, userDto.DomainToDto(user))
 	}
 }
 
+func (c UserController) ChangePassword() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		cp, err := requests.Bind(r, requests.ChangePasswordRequest{}, domain.ChangePassword{})
+		if err != nil {
+			log.Printf("UserController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		u := r.Context().Value(UserKey).(domain.User)
+		sess := r.Context().Value(SessKey).(domain.Session)
+		err = c.authService.ChangePassword(u, sess, cp)
+		if err != nil {
+			log.Printf("UserController: %s", err)
+			Error(w, err)
+			return
+		}
+
+		noContent(w)
+	}
+}
+
 func (c UserController) Delete() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		u := r.Context().Value(UserKey).(domain.User)
@@ -60,7 +95,7 @@ func (c UserController) Delete() http.HandlerFunc {
 		err := c.userService.Delete(u.Id)
 		if err != nil {
 			log.Printf("UserController: %s", err)
-			InternalServerError(w, err)
+			Error(w, err)
 			return
 		}
 
@@ -0,0 +1,154 @@
+package controllers
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/app"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/http/requests"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/http/resources"
+)
+
+type WebhookController struct {
+	webhookService app.WebhookService
+}
+
+func NewWebhookController(ws app.WebhookService) WebhookController {
+	return WebhookController{
+		webhookService: ws,
+	}
+}
+
+func (c WebhookController) Save() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := r.Context().Value(UserKey).(domain.User)
+		hook, err := requests.Bind(r, requests.WebhookRequest{}, domain.Webhook{})
+		if err != nil {
+			log.Printf("WebhookController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		hook, err = c.webhookService.Save(hook, user.Id)
+		if err != nil {
+			log.Printf("WebhookController: %s", err)
+			Error(w, err)
+			return
+		}
+
+		var hookDto resources.WebhookDto
+		Created(w, hookDto.DomainToDto(hook))
+	}
+}
+
+func (c WebhookController) FindForOrganization() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := r.Context().Value(UserKey).(domain.User)
+		orgId, err := organizationParam(r)
+		if err != nil {
+			log.Printf("WebhookController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+		p, err := requests.Pagination(r)
+		if err != nil {
+			log.Printf("WebhookController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		hooks, err := c.webhookService.FindForOrganization(orgId, p, user.Id)
+		if err != nil {
+			log.Printf("WebhookController: %s", err)
+			Error(w, err)
+			return
+		}
+
+		var hooksDto resources.WebhooksDto
+		Success(w, hooksDto.DomainToDto(hooks))
+	}
+}
+
+func (c WebhookController) Find() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := r.Context().Value(UserKey).(domain.User)
+		hook := r.Context().Value(WebhookKey).(domain.Webhook)
+
+		err := c.webhookService.CheckAccess(hook.OrganizationId, user.Id, domain.ManagePermission)
+		if err != nil {
+			log.Printf("WebhookController: %s", err)
+			Error(w, err)
+			return
+		}
+
+		var hookDto resources.WebhookDto
+		Success(w, hookDto.DomainToDto(hook))
+	}
+}
+
+func (c WebhookController) Update() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := r.Context().Value(UserKey).(domain.User)
+		upd, err := requests.Bind(r, requests.WebhookRequest{}, domain.Webhook{})
+		if err != nil {
+			log.Printf("WebhookController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		hook := r.Context().Value(WebhookKey).(domain.Webhook)
+		hook.Url = upd.Url
+		hook.Secret = upd.Secret
+		hook.Events = upd.Events
+		hook.Enabled = upd.Enabled
+		hook, err = c.webhookService.Update(hook, user.Id)
+		if err != nil {
+			log.Printf("WebhookController: %s", err)
+			Error(w, err)
+			return
+		}
+
+		var hookDto resources.WebhookDto
+		Success(w, hookDto.DomainToDto(hook))
+	}
+}
+
+func (c WebhookController) Delete() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := r.Context().Value(UserKey).(domain.User)
+		hook := r.Context().Value(WebhookKey).(domain.Webhook)
+
+		err := c.webhookService.Delete(hook, user.Id)
+		if err != nil {
+			log.Printf("WebhookController: %s", err)
+			Error(w, err)
+			return
+		}
+
+		Ok(w)
+	}
+}
+
+func (c WebhookController) FindDeliveries() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := r.Context().Value(UserKey).(domain.User)
+		hook := r.Context().Value(WebhookKey).(domain.Webhook)
+		p, err := requests.Pagination(r)
+		if err != nil {
+			log.Printf("WebhookController: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		dlvs, err := c.webhookService.FindDeliveries(hook, p, user.Id)
+		if err != nil {
+			log.Printf("WebhookController: %s", err)
+			Error(w, err)
+			return
+		}
+
+		var dlvsDto resources.WebhookDeliveriesDto
+		Success(w, dlvsDto.DomainToDto(dlvs))
+	}
+}
@@ -9,11 +9,10 @@ import (
 	"github.com/go-chi/jwtauth/v5"
 	"github.com/google/uuid"
 	"github.com/lestrrat-go/jwx/v2/jwt"
-	"github.com/upper/db/v4"
 	"net/http"
 )
 
-func AuthMiddleware(ja *jwtauth.JWTAuth, as app.AuthService, us app.UserService) func(http.Handler) http.Handler {
+func AuthMiddleware(ja *jwtauth.JWTAuth, as app.AuthService, us app.UserService, ss app.SessionService) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		hfn := func(w http.ResponseWriter, r *http.Request) {
 			ctx := r.Context()
@@ -49,13 +48,20 @@ func AuthMiddleware(ja *jwtauth.JWTAuth, as app.AuthService, us app.UserService)
 
 			user, err := us.FindById(uId)
 			if err != nil {
-				if errors.Is(err, db.ErrNoMoreRows) {
+				if errors.Is(err, domain.ErrNotFound) {
 					err = errors.New("unauthorized")
 				}
 				controllers.Unauthorized(w, err)
 				return
 			}
+			if user.Blocked() {
+				controllers.Forbidden(w, app.ErrUserBlocked)
+				return
+			}
+
+			ss.Touch(auth)
 
+			ctx = jwtauth.NewContext(ctx, token, nil)
 			ctx = context.WithValue(ctx, controllers.UserKey, user)
 			ctx = context.WithValue(ctx, controllers.SessKey, auth)
 
@@ -0,0 +1,41 @@
+package middlewares
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/app"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/http/controllers"
+)
+
+const deviceAuthScheme = "Device "
+
+// DeviceAuthMiddleware authenticates hardware by the "Authorization: Device <token>"
+// header and puts the device into the request context instead of a user.
+// Every authenticated request also counts as a sign of life from the device.
+func DeviceAuthMiddleware(das app.DeviceAuthService, ds app.DeviceService) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		hfn := func(w http.ResponseWriter, r *http.Request) {
+			header := r.Header.Get("Authorization")
+			if len(header) <= len(deviceAuthScheme) || !strings.EqualFold(header[:len(deviceAuthScheme)], deviceAuthScheme) {
+				controllers.Unauthorized(w, errors.New("device token is missing"))
+				return
+			}
+
+			dev, err := das.Check(header[len(deviceAuthScheme):])
+			if err != nil {
+				controllers.Unauthorized(w, errors.New("unauthorized"))
+				return
+			}
+
+			_ = ds.Touch(dev)
+
+			ctx := context.WithValue(r.Context(), controllers.DeviceKey, dev)
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		}
+		return http.HandlerFunc(hfn)
+	}
+}
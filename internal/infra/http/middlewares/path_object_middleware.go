@@ -5,7 +5,7 @@ import (
 	"fmt"
 	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/http/controllers"
 	"github.com/go-chi/chi/v5"
-	"github.com/upper/db/v4"
+	"github.com/google/uuid"
 	"log"
 	"net/http"
 	"strconv"
@@ -15,6 +15,17 @@ type Findable interface {
 	Find(uint64) (interface{}, error)
 }
 
+// FindFunc lets a plain function be used as a Findable.
+type FindFunc func(uint64) (interface{}, error)
+
+func (f FindFunc) Find(id uint64) (interface{}, error) {
+	return f(id)
+}
+
+type GUIDFindable interface {
+	FindByGUID(uuid.UUID) (interface{}, error)
+}
+
 func PathObject(pathKey string, ctxKey controllers.CtxKey, service Findable) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		hfn := func(w http.ResponseWriter, r *http.Request) {
@@ -29,13 +40,33 @@ func PathObject(pathKey string, ctxKey controllers.CtxKey, service Findable) fun
 			obj, err := service.Find(id)
 			if err != nil {
 				log.Print(err)
-				errInt4 := fmt.Errorf("%d is greater than maximum value for Int4", id)
-				if err == db.ErrNoMoreRows || err.Error() == errInt4.Error() {
-					err = fmt.Errorf("record not found")
-					controllers.NotFound(w, err)
-					return
-				}
-				controllers.InternalServerError(w, err)
+				controllers.Error(w, err)
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), ctxKey, obj)
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		}
+		return http.HandlerFunc(hfn)
+	}
+}
+
+func PathGUIDObject(pathKey string, ctxKey controllers.CtxKey, service GUIDFindable) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		hfn := func(w http.ResponseWriter, r *http.Request) {
+			guid, err := uuid.Parse(chi.URLParam(r, pathKey))
+			if err != nil {
+				err = fmt.Errorf("invalid %s parameter(only UUID)", pathKey)
+				log.Print(err)
+				controllers.BadRequest(w, err)
+				return
+			}
+
+			obj, err := service.FindByGUID(guid)
+			if err != nil {
+				log.Print(err)
+				controllers.Error(w, err)
 				return
 			}
 
@@ -0,0 +1,43 @@
+package middlewares
+
+import (
+	"net"
+	"net/http"
+
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// RealIPMiddleware takes the client address from the X-Forwarded-For and
+// X-Real-IP headers only when the request comes from one of the trusted
+// proxies. Anyone else could put whatever they like there, so their own
+// address is kept.
+func RealIPMiddleware(trusted []*net.IPNet) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fromHeaders := middleware.RealIP(next)
+		hfn := func(w http.ResponseWriter, r *http.Request) {
+			if fromProxy(r.RemoteAddr, trusted) {
+				fromHeaders.ServeHTTP(w, r)
+				return
+			}
+			next.ServeHTTP(w, r)
+		}
+		return http.HandlerFunc(hfn)
+	}
+}
+
+func fromProxy(remoteAddr string, trusted []*net.IPNet) bool {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+	for _, n := range trusted {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,33 @@
+package middlewares
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/http/controllers"
+)
+
+// RoleMiddleware lets only users with one of the roles through,
+// it has to run after AuthMiddleware.
+func RoleMiddleware(roles ...domain.Role) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		hfn := func(w http.ResponseWriter, r *http.Request) {
+			user, ok := r.Context().Value(controllers.UserKey).(domain.User)
+			if !ok {
+				controllers.Unauthorized(w, errors.New("unauthorized"))
+				return
+			}
+
+			for _, role := range roles {
+				if user.Role == role {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+
+			controllers.Forbidden(w, domain.ErrAccessDenied)
+		}
+		return http.HandlerFunc(hfn)
+	}
+}
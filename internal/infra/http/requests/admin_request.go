@@ -0,0 +1,41 @@
+package requests
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+)
+
+type UserRoleRequest struct {
+	Role domain.Role `json:"role" validate:"required,oneof=ADMIN CUSTOMER"`
+}
+
+func (r UserRoleRequest) ToDomainModel() (interface{}, error) {
+	return r.Role, nil
+}
+
+// UserFilters reads the "search", "role" and "deleted" query parameters.
+func UserFilters(r *http.Request) (domain.UserFilters, error) {
+	q := r.URL.Query()
+	f := domain.UserFilters{
+		Search: strings.TrimSpace(q.Get("search")),
+		Role:   domain.Role(q.Get("role")),
+	}
+
+	if f.Role != "" && f.Role != domain.AdminRole && f.Role != domain.CustomerRole {
+		return domain.UserFilters{}, errors.New("invalid 'role' parameter (ADMIN or CUSTOMER expected)")
+	}
+
+	if v := q.Get("deleted"); v != "" {
+		deleted, err := strconv.ParseBool(v)
+		if err != nil {
+			return domain.UserFilters{}, errors.New("invalid 'deleted' parameter (true or false expected)")
+		}
+		f.Deleted = deleted
+	}
+
+	return f, nil
+}
@@ -0,0 +1,53 @@
+package requests
+
+import (
+	"time"
+
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+)
+
+type AlertRuleRequest struct {
+	DeviceId  *uint64  `json:"deviceId,omitempty" validate:"required_without=RoomId,excluded_with=RoomId"`
+	RoomId    *uint64  `json:"roomId,omitempty" validate:"required_without=DeviceId"`
+	Name      string   `json:"name" validate:"required,max=100"`
+	Units     *string  `json:"units,omitempty" validate:"omitempty,max=50"`
+	Operator  string   `json:"operator" validate:"required,oneof=> >= < <="`
+	Threshold *float64 `json:"threshold" validate:"required"`
+	Duration  uint64   `json:"duration" validate:"max=86400"`
+	Enabled   *bool    `json:"enabled,omitempty"`
+}
+
+// UpdateAlertRuleRequest carries everything but the target,
+// a rule is never moved to another device or room.
+type UpdateAlertRuleRequest struct {
+	Name      string   `json:"name" validate:"required,max=100"`
+	Units     *string  `json:"units,omitempty" validate:"omitempty,max=50"`
+	Operator  string   `json:"operator" validate:"required,oneof=> >= < <="`
+	Threshold *float64 `json:"threshold" validate:"required"`
+	Duration  uint64   `json:"duration" validate:"max=86400"`
+	Enabled   *bool    `json:"enabled,omitempty"`
+}
+
+func (r AlertRuleRequest) ToDomainModel() (interface{}, error) {
+	return domain.AlertRule{
+		DeviceId:  r.DeviceId,
+		RoomId:    r.RoomId,
+		Name:      r.Name,
+		Units:     r.Units,
+		Operator:  domain.AlertOperator(r.Operator),
+		Threshold: *r.Threshold,
+		Duration:  time.Duration(r.Duration) * time.Second,
+		Enabled:   r.Enabled == nil || *r.Enabled,
+	}, nil
+}
+
+func (r UpdateAlertRuleRequest) ToDomainModel() (interface{}, error) {
+	return domain.AlertRule{
+		Name:      r.Name,
+		Units:     r.Units,
+		Operator:  domain.AlertOperator(r.Operator),
+		Threshold: *r.Threshold,
+		Duration:  time.Duration(r.Duration) * time.Second,
+		Enabled:   r.Enabled == nil || *r.Enabled,
+	}, nil
+}
@@ -0,0 +1,41 @@
+package requests
+
+import (
+	"time"
+
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+)
+
+type CommandRequest struct {
+	Action string   `json:"action" validate:"required,oneof=ON OFF TOGGLE SETPOINT"`
+	Value  *float64 `json:"value,omitempty" validate:"required_if=Action SETPOINT"`
+	TTL    uint64   `json:"ttl,omitempty" validate:"omitempty,max=86400"`
+}
+
+type AckCommandRequest struct {
+	Success bool    `json:"success"`
+	Message *string `json:"message,omitempty" validate:"omitempty,max=1000"`
+}
+
+func (r CommandRequest) ToDomainModel() (interface{}, error) {
+	var expiresAt time.Time
+	if r.TTL > 0 {
+		expiresAt = time.Now().Add(time.Duration(r.TTL) * time.Second)
+	}
+	return domain.Command{
+		Action:    r.Action,
+		Value:     r.Value,
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+func (r AckCommandRequest) ToDomainModel() (interface{}, error) {
+	status := domain.CommandAcked
+	if !r.Success {
+		status = domain.CommandFailed
+	}
+	return domain.Command{
+		Status: status,
+		Result: r.Message,
+	}, nil
+}
@@ -2,12 +2,17 @@ package requests
 
 import (
 	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
 	"github.com/google/uuid"
 )
 
 type DeviceRequest struct {
+	OrganizationId   uint64    `json:"organizationId" validate:"required"`
 	RoomId           *uint64   `json:"roomId,omitempty"`
 	GUID             uuid.UUID `json:"guid"`
 	InventoryNumber  string    `json:"inventoryNumber"`
@@ -31,6 +36,7 @@ func (r DeviceRequest) ToDomainModel() (interface{}, error) {
 	}
 
 	return domain.Device{
+		OrganizationId:   r.OrganizationId,
 		RoomId:           r.RoomId,
 		GUID:             r.GUID,
 		InventoryNumber:  r.InventoryNumber,
@@ -43,7 +49,7 @@ func (r DeviceRequest) ToDomainModel() (interface{}, error) {
 }
 
 type SetRoomRequest struct {
-	RoomId uint64 `json:"room_id"`
+	RoomId uint64 `json:"roomId" validate:"required"`
 }
 
 func (r SetRoomRequest) ToDomainModel() (interface{}, error) {
@@ -51,3 +57,96 @@ func (r SetRoomRequest) ToDomainModel() (interface{}, error) {
 		RoomId: &r.RoomId,
 	}, nil
 }
+
+type PlacementRequest struct {
+	Position    VectorRequest      `json:"position" validate:"required"`
+	Orientation *QuaternionRequest `json:"orientation,omitempty"`
+	AnchorId    *string            `json:"anchorId,omitempty" validate:"omitempty,min=1,max=255"`
+}
+
+type VectorRequest struct {
+	X *float64 `json:"x" validate:"required"`
+	Y *float64 `json:"y" validate:"required"`
+	Z *float64 `json:"z" validate:"required"`
+}
+
+type QuaternionRequest struct {
+	X *float64 `json:"x" validate:"required"`
+	Y *float64 `json:"y" validate:"required"`
+	Z *float64 `json:"z" validate:"required"`
+	W *float64 `json:"w" validate:"required"`
+}
+
+// ToDomainModel normalizes the orientation, a missing one means
+// the device is aligned with the room axes.
+func (r PlacementRequest) ToDomainModel() (interface{}, error) {
+	q := domain.Quaternion{W: 1}
+	if o := r.Orientation; o != nil {
+		// Hypot keeps the norm of very large components from overflowing
+		n := math.Hypot(math.Hypot(*o.X, *o.Y), math.Hypot(*o.Z, *o.W))
+		if n < 1e-9 {
+			return domain.Placement{}, errors.New("orientation must be a non-zero quaternion")
+		}
+		q = domain.Quaternion{X: *o.X / n, Y: *o.Y / n, Z: *o.Z / n, W: *o.W / n}
+	}
+
+	return domain.Placement{
+		X:           *r.Position.X,
+		Y:           *r.Position.Y,
+		Z:           *r.Position.Z,
+		Orientation: q,
+		AnchorId:    r.AnchorId,
+	}, nil
+}
+
+// DeviceFilters reads the "organizationId", "roomId", "category",
+// "serialNumber" and "status" query parameters, either the organization
+// or the room is required.
+func DeviceFilters(r *http.Request) (domain.DeviceFilters, error) {
+	q := r.URL.Query()
+	f := domain.DeviceFilters{
+		Category:     q.Get("category"),
+		SerialNumber: strings.TrimSpace(q.Get("serialNumber")),
+		Status:       domain.DeviceStatus(q.Get("status")),
+	}
+
+	if v := q.Get("organizationId"); v != "" {
+		orgId, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			return domain.DeviceFilters{}, errors.New("invalid 'organizationId' parameter (positive number expected)")
+		}
+		f.OrganizationId = orgId
+	}
+
+	if v := q.Get("roomId"); v != "" {
+		roomId, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			return domain.DeviceFilters{}, errors.New("invalid 'roomId' parameter (positive number expected)")
+		}
+		f.RoomId = &roomId
+	}
+
+	if f.OrganizationId == 0 && f.RoomId == nil {
+		return domain.DeviceFilters{}, errors.New("either 'organizationId' or 'roomId' parameter is required")
+	}
+	if f.Category != "" && f.Category != "SENSOR" && f.Category != "ACTUATOR" {
+		return domain.DeviceFilters{}, errors.New("invalid 'category' parameter (SENSOR or ACTUATOR expected)")
+	}
+	switch f.Status {
+	case "", domain.DeviceOnline, domain.DeviceStale, domain.DeviceOffline:
+	default:
+		return domain.DeviceFilters{}, errors.New("invalid 'status' parameter (ONLINE, STALE or OFFLINE expected)")
+	}
+
+	return f, nil
+}
+
+// InventoryNumber reads the required "inventoryNumber" query parameter.
+func InventoryNumber(r *http.Request) (string, error) {
+	number := strings.TrimSpace(r.URL.Query().Get("inventoryNumber"))
+	if number == "" {
+		return "", errors.New("'inventoryNumber' parameter is required")
+	}
+
+	return number, nil
+}
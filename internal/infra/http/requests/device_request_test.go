@@ -0,0 +1,65 @@
+package requests
+
+import (
+	"math"
+	"testing"
+
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+)
+
+func TestPlacementRequestToDomainModel(t *testing.T) {
+	f := func(v float64) *float64 { return &v }
+	quaternion := func(x, y, z, w float64) *QuaternionRequest {
+		return &QuaternionRequest{X: f(x), Y: f(y), Z: f(z), W: f(w)}
+	}
+	half := math.Sqrt(0.5)
+
+	tests := []struct {
+		name        string
+		orientation *QuaternionRequest
+		want        domain.Quaternion
+		wantErr     bool
+	}{
+		{"missing means identity", nil, domain.Quaternion{W: 1}, false},
+		{"unit stays as is", quaternion(0, 0, 0, 1), domain.Quaternion{W: 1}, false},
+		{"scaled identity", quaternion(0, 0, 0, 4), domain.Quaternion{W: 1}, false},
+		{"negative scale keeps the sign", quaternion(0, 0, 0, -2), domain.Quaternion{W: -1}, false},
+		{"90 degrees around y", quaternion(0, 3, 0, 3), domain.Quaternion{Y: half, W: half}, false},
+		{"every component", quaternion(1, 1, 1, 1), domain.Quaternion{X: 0.5, Y: 0.5, Z: 0.5, W: 0.5}, false},
+		{"huge components", quaternion(0, 1e200, 0, 1e200), domain.Quaternion{Y: half, W: half}, false},
+		{"zero", quaternion(0, 0, 0, 0), domain.Quaternion{}, true},
+		{"next to zero", quaternion(1e-12, 0, 0, 1e-12), domain.Quaternion{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := PlacementRequest{
+				Position:    VectorRequest{X: f(1.5), Y: f(0), Z: f(-2)},
+				Orientation: tt.orientation,
+			}
+			res, err := r.ToDomainModel()
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("no error for a zero quaternion")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			p := res.(domain.Placement)
+			if p.X != 1.5 || p.Y != 0 || p.Z != -2 {
+				t.Errorf("position %v %v %v, want 1.5 0 -2", p.X, p.Y, p.Z)
+			}
+			q := p.Orientation
+			for _, c := range [][2]float64{{q.X, tt.want.X}, {q.Y, tt.want.Y}, {q.Z, tt.want.Z}, {q.W, tt.want.W}} {
+				if math.Abs(c[0]-c[1]) > 1e-12 {
+					t.Fatalf("orientation %+v, want %+v", q, tt.want)
+				}
+			}
+			if n := q.X*q.X + q.Y*q.Y + q.Z*q.Z + q.W*q.W; math.Abs(n-1) > 1e-12 {
+				t.Errorf("orientation %+v is not a unit quaternion", q)
+			}
+		})
+	}
+}
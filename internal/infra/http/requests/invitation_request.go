@@ -0,0 +1,23 @@
+package requests
+
+import "github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+
+type InvitationRequest struct {
+	Email string `json:"email" validate:"required,email,max=255"`
+	Role  string `json:"role" validate:"required,oneof=owner manager technician viewer"`
+}
+
+type AcceptInvitationRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+func (r InvitationRequest) ToDomainModel() (interface{}, error) {
+	return domain.Invitation{
+		Email: r.Email,
+		Role:  domain.OrganizationRole(r.Role),
+	}, nil
+}
+
+func (r AcceptInvitationRequest) ToDomainModel() (interface{}, error) {
+	return r.Token, nil
+}
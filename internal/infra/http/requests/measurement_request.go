@@ -0,0 +1,67 @@
+package requests
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+)
+
+const (
+	defaultHistoryRange = 24 * time.Hour
+	maxHistoryRange     = 31 * 24 * time.Hour
+)
+
+type MeasurementRequest struct {
+	Value      *float64   `json:"value" validate:"required"`
+	MeasuredAt *time.Time `json:"measuredAt,omitempty"`
+}
+
+type MeasurementsRequest struct {
+	Readings []MeasurementRequest `json:"readings" validate:"required,min=1,dive"`
+}
+
+func (r MeasurementsRequest) ToDomainModel() (interface{}, error) {
+	ms := make([]domain.Measurement, 0, len(r.Readings))
+	for _, rd := range r.Readings {
+		m := domain.Measurement{Value: *rd.Value}
+		if rd.MeasuredAt != nil {
+			m.MeasuredAt = *rd.MeasuredAt
+		}
+		ms = append(ms, m)
+	}
+	return ms, nil
+}
+
+// TimeRange reads the "from" and "to" RFC 3339 query parameters.
+// Missing bounds default to the last 24 hours, at most 31 days
+// may lie between them.
+func TimeRange(r *http.Request) (time.Time, time.Time, error) {
+	to := time.Now()
+	if v := r.URL.Query().Get("to"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return time.Time{}, time.Time{}, errors.New("invalid 'to' parameter (RFC 3339 expected)")
+		}
+		to = t
+	}
+
+	from := to.Add(-defaultHistoryRange)
+	if v := r.URL.Query().Get("from"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return time.Time{}, time.Time{}, errors.New("invalid 'from' parameter (RFC 3339 expected)")
+		}
+		from = t
+	}
+
+	if from.After(to) {
+		return time.Time{}, time.Time{}, errors.New("'from' must not be after 'to'")
+	}
+	if to.Sub(from) > maxHistoryRange {
+		return time.Time{}, time.Time{}, errors.New("'from' and 'to' must not be more than 31 days apart")
+	}
+
+	return from, to, nil
+}
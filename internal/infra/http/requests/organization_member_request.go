@@ -0,0 +1,49 @@
+package requests
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+)
+
+type OrganizationMemberRequest struct {
+	Email string `json:"email" validate:"required,email"`
+	Role  string `json:"role" validate:"required,oneof=owner manager technician viewer"`
+}
+
+type MemberRoleRequest struct {
+	Role string `json:"role" validate:"required,oneof=owner manager technician viewer"`
+}
+
+func (r OrganizationMemberRequest) ToDomainModel() (interface{}, error) {
+	return domain.OrganizationMember{
+		Role: domain.OrganizationRole(r.Role),
+		User: &domain.User{Email: r.Email},
+	}, nil
+}
+
+func (r MemberRoleRequest) ToDomainModel() (interface{}, error) {
+	return domain.OrganizationMember{
+		Role: domain.OrganizationRole(r.Role),
+	}, nil
+}
+
+// MemberFilters reads the "role" and "search" query parameters,
+// the organization comes from the path.
+func MemberFilters(r *http.Request) (domain.MemberFilters, error) {
+	q := r.URL.Query()
+	f := domain.MemberFilters{
+		Role:   domain.OrganizationRole(q.Get("role")),
+		Search: strings.TrimSpace(q.Get("search")),
+	}
+
+	switch f.Role {
+	case "", domain.OrganizationOwner, domain.OrganizationManager, domain.OrganizationTechnician, domain.OrganizationViewer:
+	default:
+		return domain.MemberFilters{}, errors.New("invalid 'role' parameter (owner, manager, technician or viewer expected)")
+	}
+
+	return f, nil
+}
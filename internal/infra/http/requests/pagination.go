@@ -0,0 +1,49 @@
+package requests
+
+import (
+	"errors"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+)
+
+const (
+	defaultPerPage = 20
+	maxPerPage     = 100
+)
+
+var sortPattern = regexp.MustCompile(`^-?[a-zA-Z]+$`)
+
+// Pagination reads the "page", "per_page" and "sort" query parameters,
+// pages are counted from 1 and "sort=-createdDate" sorts descending.
+func Pagination(r *http.Request) (domain.Pagination, error) {
+	p := domain.Pagination{Page: 1, CountPerPage: defaultPerPage}
+	if v := r.URL.Query().Get("page"); v != "" {
+		page, err := strconv.ParseUint(v, 10, 64)
+		if err != nil || page == 0 {
+			return domain.Pagination{}, errors.New("invalid 'page' parameter (positive number expected)")
+		}
+		p.Page = page
+	}
+
+	if v := r.URL.Query().Get("per_page"); v != "" {
+		perPage, err := strconv.ParseUint(v, 10, 64)
+		if err != nil || perPage == 0 || perPage > maxPerPage {
+			return domain.Pagination{}, errors.New("invalid 'per_page' parameter (1 to 100 expected)")
+		}
+		p.CountPerPage = perPage
+	}
+
+	if v := r.URL.Query().Get("sort"); v != "" {
+		if !sortPattern.MatchString(v) {
+			return domain.Pagination{}, errors.New("invalid 'sort' parameter (field name expected, prefixed with '-' to sort descending)")
+		}
+		p.Desc = strings.HasPrefix(v, "-")
+		p.Sort = strings.TrimPrefix(v, "-")
+	}
+
+	return p, nil
+}
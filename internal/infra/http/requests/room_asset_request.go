@@ -0,0 +1,105 @@
+package requests
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+)
+
+const (
+	uploadField      = "file"
+	maxFloorPlanSize = 10 << 20
+	maxModelSize     = 50 << 20
+)
+
+var floorPlanTypes = map[string]string{
+	"image/png":  ".png",
+	"image/jpeg": ".jpg",
+	"image/webp": ".webp",
+}
+
+// UploadedFile is an uploaded asset whose type was taken from
+// the content itself, not from what the client claimed.
+type UploadedFile struct {
+	Ext     string
+	Content []byte
+}
+
+func FloorPlan(r *http.Request) (UploadedFile, error) {
+	content, declared, err := readUpload(r, maxFloorPlanSize)
+	if err != nil {
+		return UploadedFile{}, err
+	}
+
+	detected, _, _ := mime.ParseMediaType(http.DetectContentType(content))
+	ext, ok := floorPlanTypes[detected]
+	if !ok || (declared != "" && declared != detected && declared != "application/octet-stream") {
+		return UploadedFile{}, errors.New("floor plan must be a PNG, JPEG or WebP image")
+	}
+
+	return UploadedFile{Ext: ext, Content: content}, nil
+}
+
+// Model accepts binary glTF (GLB) or a self-contained glTF JSON document.
+func Model(r *http.Request) (UploadedFile, error) {
+	content, declared, err := readUpload(r, maxModelSize)
+	if err != nil {
+		return UploadedFile{}, err
+	}
+
+	var ext, detected string
+	switch {
+	case bytes.HasPrefix(content, []byte("glTF")):
+		ext, detected = ".glb", "model/gltf-binary"
+	case isGLTF(content):
+		ext, detected = ".gltf", "model/gltf+json"
+	default:
+		return UploadedFile{}, errors.New("model must be a glTF or GLB file")
+	}
+	if declared != "" && declared != detected && declared != "application/octet-stream" && declared != "application/json" {
+		return UploadedFile{}, fmt.Errorf("content type %s does not match a %s model", declared, ext)
+	}
+
+	return UploadedFile{Ext: ext, Content: content}, nil
+}
+
+func readUpload(r *http.Request, maxSize int64) ([]byte, string, error) {
+	// a little headroom for the multipart envelope itself
+	r.Body = http.MaxBytesReader(nil, r.Body, maxSize+1<<20)
+	file, header, err := r.FormFile(uploadField)
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return nil, "", fmt.Errorf("file exceeds %d MB", maxSize>>20)
+		}
+		return nil, "", fmt.Errorf("multipart form with a %q file is expected", uploadField)
+	}
+	defer file.Close()
+
+	if header.Size > maxSize {
+		return nil, "", fmt.Errorf("file exceeds %d MB", maxSize>>20)
+	}
+	content, err := io.ReadAll(io.LimitReader(file, maxSize))
+	if err != nil {
+		return nil, "", err
+	}
+	if len(content) == 0 {
+		return nil, "", errors.New("file is empty")
+	}
+
+	declared, _, _ := mime.ParseMediaType(header.Header.Get("Content-Type"))
+	return content, declared, nil
+}
+
+func isGLTF(content []byte) bool {
+	var doc struct {
+		Asset *struct {
+			Version string `json:"version"`
+		} `json:"asset"`
+	}
+	return json.Unmarshal(content, &doc) == nil && doc.Asset != nil && doc.Asset.Version != ""
+}
@@ -1,11 +1,19 @@
 package requests
 
-import "github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+)
 
 type RoomRequest struct {
-	OrganizationId uint64 `json:"organizationId"`
-	Name           string `json:"name" validate:"required"`
-	Description    string `json:"description"`
+	OrganizationId uint64   `json:"organizationId"`
+	Name           string   `json:"name" validate:"required"`
+	Description    string   `json:"description"`
+	PowerCapacity  *float64 `json:"powerCapacity,omitempty" validate:"omitempty,gt=0"`
 }
 
 func (r RoomRequest) ToDomainModel() (interface{}, error) {
@@ -13,5 +21,21 @@ func (r RoomRequest) ToDomainModel() (interface{}, error) {
 		OrganizationId: r.OrganizationId,
 		Name:           r.Name,
 		Description:    r.Description,
+		PowerCapacity:  r.PowerCapacity,
+	}, nil
+}
+
+// RoomFilters reads the "organizationId" and "search" query parameters,
+// the organization is required.
+func RoomFilters(r *http.Request) (domain.RoomFilters, error) {
+	q := r.URL.Query()
+	orgId, err := strconv.ParseUint(q.Get("organizationId"), 10, 64)
+	if err != nil {
+		return domain.RoomFilters{}, errors.New("invalid 'organizationId' parameter (positive number expected)")
+	}
+
+	return domain.RoomFilters{
+		OrganizationId: orgId,
+		Search:         strings.TrimSpace(q.Get("search")),
 	}, nil
 }
@@ -9,6 +9,8 @@ type RegisterRequest struct {
 	SecondName string `json:"secondName" validate:"required,gte=1,max=40"`
 	Email      string `json:"email" validate:"required,email"`
 	Password   string `json:"password" validate:"required,gte=4,max=20"`
+	// InvitationToken accepts an invitation along with the registration
+	InvitationToken string `json:"invitationToken,omitempty"`
 }
 
 type LoginRequest struct {
@@ -22,12 +24,37 @@ type UpdateUserRequest struct {
 	Email      string `json:"email" validate:"required,email"`
 }
 
+type ChangePasswordRequest struct {
+	OldPassword string `json:"oldPassword" validate:"required"`
+	NewPassword string `json:"newPassword" validate:"required,gte=4,max=20,nefield=OldPassword"`
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refreshToken" validate:"required"`
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+type ResetPasswordRequest struct {
+	Token       string `json:"token" validate:"required"`
+	NewPassword string `json:"newPassword" validate:"required,gte=4,max=20"`
+}
+
 func (r RegisterRequest) ToDomainModel() (interface{}, error) {
-	return domain.User{
-		FirstName:  r.FirstName,
-		SecondName: r.SecondName,
-		Email:      r.Email,
-		Password:   r.Password,
+	return domain.Registration{
+		User: domain.User{
+			FirstName:  r.FirstName,
+			SecondName: r.SecondName,
+			Email:      r.Email,
+			Password:   r.Password,
+		},
+		InvitationToken: r.InvitationToken,
 	}, nil
 }
 
@@ -45,3 +72,29 @@ func (r LoginRequest) ToDomainModel() (interface{}, error) {
 		Email:    r.Email,
 	}, nil
 }
+
+func (r ChangePasswordRequest) ToDomainModel() (interface{}, error) {
+	return domain.ChangePassword{
+		OldPassword: r.OldPassword,
+		NewPassword: r.NewPassword,
+	}, nil
+}
+
+func (r ForgotPasswordRequest) ToDomainModel() (interface{}, error) {
+	return r.Email, nil
+}
+
+func (r ResetPasswordRequest) ToDomainModel() (interface{}, error) {
+	return domain.PasswordChange{
+		Token:       r.Token,
+		NewPassword: r.NewPassword,
+	}, nil
+}
+
+func (r RefreshRequest) ToDomainModel() (interface{}, error) {
+	return r.RefreshToken, nil
+}
+
+func (r VerifyEmailRequest) ToDomainModel() (interface{}, error) {
+	return r.Token, nil
+}
@@ -0,0 +1,33 @@
+package requests
+
+import (
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/webhooks"
+)
+
+type WebhookRequest struct {
+	OrganizationId uint64   `json:"organizationId" validate:"required"`
+	Url            string   `json:"url" validate:"required,url,max=2048"`
+	Secret         string   `json:"secret" validate:"required,min=16,max=255"`
+	Events         []string `json:"events,omitempty" validate:"omitempty,dive,oneof=organization.created organization.updated organization.deleted organization.restored room.created room.updated room.deleted room.restored device.created device.updated device.deleted device.restored command.created alert.opened alert.closed"`
+	Enabled        *bool    `json:"enabled,omitempty"`
+}
+
+func (r WebhookRequest) ToDomainModel() (interface{}, error) {
+	err := webhooks.CheckTarget(r.Url)
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]domain.EventType, 0, len(r.Events))
+	for _, e := range r.Events {
+		events = append(events, domain.EventType(e))
+	}
+	return domain.Webhook{
+		OrganizationId: r.OrganizationId,
+		Url:            r.Url,
+		Secret:         r.Secret,
+		Events:         events,
+		Enabled:        r.Enabled == nil || *r.Enabled,
+	}, nil
+}
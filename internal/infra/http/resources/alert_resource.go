@@ -0,0 +1,111 @@
+package resources
+
+import (
+	"time"
+
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+)
+
+type AlertRulesDto struct {
+	Rules []AlertRuleDto `json:"rules"`
+	Total uint64         `json:"total"`
+	Pages uint           `json:"pages"`
+}
+
+type AlertRuleDto struct {
+	Id             uint64               `json:"id"`
+	OrganizationId uint64               `json:"organizationId"`
+	DeviceId       *uint64              `json:"deviceId,omitempty"`
+	RoomId         *uint64              `json:"roomId,omitempty"`
+	UserId         uint64               `json:"userId"`
+	Name           string               `json:"name"`
+	Units          *string              `json:"units,omitempty"`
+	Operator       domain.AlertOperator `json:"operator"`
+	Threshold      float64              `json:"threshold"`
+	Duration       uint64               `json:"duration"`
+	Enabled        bool                 `json:"enabled"`
+	CreatedDate    time.Time            `json:"createdDate"`
+	UpdatedDate    time.Time            `json:"updatedDate"`
+}
+
+type AlertsDto struct {
+	Alerts []AlertDto `json:"alerts"`
+	Total  uint64     `json:"total"`
+	Pages  uint       `json:"pages"`
+}
+
+type AlertDto struct {
+	Id               uint64             `json:"id"`
+	RuleId           uint64             `json:"ruleId"`
+	OrganizationId   uint64             `json:"organizationId"`
+	DeviceId         uint64             `json:"deviceId"`
+	RoomId           *uint64            `json:"roomId,omitempty"`
+	Status           domain.AlertStatus `json:"status"`
+	Value            float64            `json:"value"`
+	StartedDate      time.Time          `json:"startedDate"`
+	OpenedDate       *time.Time         `json:"openedDate,omitempty"`
+	ResolvedDate     *time.Time         `json:"resolvedDate,omitempty"`
+	AcknowledgedDate *time.Time         `json:"acknowledgedDate,omitempty"`
+	AcknowledgedBy   *uint64            `json:"acknowledgedBy,omitempty"`
+}
+
+func (d AlertRuleDto) DomainToDto(ar domain.AlertRule) AlertRuleDto {
+	return AlertRuleDto{
+		Id:             ar.Id,
+		OrganizationId: ar.OrganizationId,
+		DeviceId:       ar.DeviceId,
+		RoomId:         ar.RoomId,
+		UserId:         ar.UserId,
+		Name:           ar.Name,
+		Units:          ar.Units,
+		Operator:       ar.Operator,
+		Threshold:      ar.Threshold,
+		Duration:       uint64(ar.Duration / time.Second),
+		Enabled:        ar.Enabled,
+		CreatedDate:    ar.CreatedDate,
+		UpdatedDate:    ar.UpdatedDate,
+	}
+}
+
+func (d AlertRulesDto) DomainToDto(rules domain.Page[domain.AlertRule]) AlertRulesDto {
+	result := make([]AlertRuleDto, 0, len(rules.Items))
+	for _, ar := range rules.Items {
+		var arDto AlertRuleDto
+		result = append(result, arDto.DomainToDto(ar))
+	}
+	return AlertRulesDto{
+		Rules: result,
+		Total: rules.Total,
+		Pages: rules.Pages,
+	}
+}
+
+func (d AlertDto) DomainToDto(a domain.Alert) AlertDto {
+	return AlertDto{
+		Id:               a.Id,
+		RuleId:           a.RuleId,
+		OrganizationId:   a.OrganizationId,
+		DeviceId:         a.DeviceId,
+		RoomId:           a.RoomId,
+		Status:           a.Status,
+		Value:            a.Value,
+		StartedDate:      a.StartedDate,
+		OpenedDate:       a.OpenedDate,
+		ResolvedDate:     a.ResolvedDate,
+		AcknowledgedDate: a.AcknowledgedDate,
+		AcknowledgedBy:   a.AcknowledgedBy,
+	}
+}
+
+func (d AlertsDto) DomainToDto(alerts domain.Page[domain.Alert]) AlertsDto {
+	result := make([]AlertDto, 0, len(alerts.Items))
+	for _, a := range alerts.Items {
+		var aDto AlertDto
+		result = append(result, aDto.DomainToDto(a))
+	}
+	return AlertsDto{
+		Alerts: result,
+		Total:  alerts.Total,
+		Pages:  alerts.Pages,
+	}
+}
@@ -0,0 +1,58 @@
+package resources
+
+import (
+	"time"
+
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+)
+
+type CommandsDto struct {
+	Commands []CommandDto `json:"commands"`
+	Total    uint64       `json:"total"`
+	Pages    uint         `json:"pages"`
+}
+
+type CommandDto struct {
+	Id            uint64               `json:"id"`
+	DeviceId      uint64               `json:"deviceId"`
+	UserId        uint64               `json:"userId"`
+	Action        string               `json:"action"`
+	Value         *float64             `json:"value,omitempty"`
+	Status        domain.CommandStatus `json:"status"`
+	Result        *string              `json:"result,omitempty"`
+	ExpiresAt     time.Time            `json:"expiresAt"`
+	DeliveredDate *time.Time           `json:"deliveredDate,omitempty"`
+	AckedDate     *time.Time           `json:"ackedDate,omitempty"`
+	CreatedDate   time.Time            `json:"createdDate"`
+	UpdatedDate   time.Time            `json:"updatedDate"`
+}
+
+func (d CommandDto) DomainToDto(c domain.Command) CommandDto {
+	return CommandDto{
+		Id:            c.Id,
+		DeviceId:      c.DeviceId,
+		UserId:        c.UserId,
+		Action:        c.Action,
+		Value:         c.Value,
+		Status:        c.Status,
+		Result:        c.Result,
+		ExpiresAt:     c.ExpiresAt,
+		DeliveredDate: c.DeliveredDate,
+		AckedDate:     c.AckedDate,
+		CreatedDate:   c.CreatedDate,
+		UpdatedDate:   c.UpdatedDate,
+	}
+}
+
+func (d CommandsDto) DomainToDto(cmds domain.Page[domain.Command]) CommandsDto {
+	commands := make([]CommandDto, 0, len(cmds.Items))
+	for _, c := range cmds.Items {
+		var cDto CommandDto
+		commands = append(commands, cDto.DomainToDto(c))
+	}
+	return CommandsDto{
+		Commands: commands,
+		Total:    cmds.Total,
+		Pages:    cmds.Pages,
+	}
+}
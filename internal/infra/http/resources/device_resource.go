@@ -1,25 +1,76 @@
 package resources
 
 import (
+	"time"
+
 	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
 	"github.com/google/uuid"
 )
 
 type DevsDto struct {
 	Devices []DevDto `json:"devices"`
+	Total   uint64   `json:"total"`
+	Pages   uint     `json:"pages"`
 }
 
 type DevDto struct {
-	Id               uint64    `json:"id"`
-	OrganizationId   uint64    `json:"organizationId"`
-	RoomId           *uint64   `json:"roomId"`
-	GUID             uuid.UUID `json:"guid"`
-	InventoryNumber  string    `json:"inventoryNumber"`
-	SerialNumber     string    `json:"serialNumber"`
-	Characteristics  string    `json:"characteristics"`
-	Category         string    `json:"category"`
-	Units            *string   `json:"units"`
-	PowerConsumption *float64  `json:"powerconsumption"`
+	Id               uint64              `json:"id"`
+	OrganizationId   uint64              `json:"organizationId"`
+	RoomId           *uint64             `json:"roomId"`
+	GUID             uuid.UUID           `json:"guid"`
+	InventoryNumber  string              `json:"inventoryNumber"`
+	SerialNumber     string              `json:"serialNumber"`
+	Characteristics  string              `json:"characteristics"`
+	Category         string              `json:"category"`
+	Units            *string             `json:"units"`
+	PowerConsumption *float64            `json:"powerconsumption"`
+	Placement        *PlacementDto       `json:"placement"`
+	Status           domain.DeviceStatus `json:"status"`
+	LastSeen         *time.Time          `json:"lastSeen"`
+	DeletedDate      *time.Time          `json:"deletedDate,omitempty"`
+}
+
+type PlacementDto struct {
+	Position    VectorDto     `json:"position"`
+	Orientation QuaternionDto `json:"orientation"`
+	AnchorId    *string       `json:"anchorId"`
+}
+
+type VectorDto struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+	Z float64 `json:"z"`
+}
+
+type QuaternionDto struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+	Z float64 `json:"z"`
+	W float64 `json:"w"`
+}
+
+// RoomSceneDto is everything the AR client needs to lay out a room.
+type RoomSceneDto struct {
+	Room    RomDto   `json:"room"`
+	Devices []DevDto `json:"devices"`
+}
+
+type DeviceLocationDto struct {
+	Device       DevDto  `json:"device"`
+	Room         *RomDto `json:"room"`
+	Organization OrgDto  `json:"organization"`
+}
+
+type DeviceTokenDto struct {
+	GUID  uuid.UUID `json:"guid"`
+	Token string    `json:"token"`
+}
+
+func (d DeviceTokenDto) DomainToDto(token string, dv domain.Device) DeviceTokenDto {
+	return DeviceTokenDto{
+		GUID:  dv.GUID,
+		Token: token,
+	}
 }
 
 func (d DevDto) DomainToDto(dv domain.Device) DevDto {
@@ -34,18 +85,70 @@ func (d DevDto) DomainToDto(dv domain.Device) DevDto {
 		Category:         dv.Category,
 		Units:            dv.Units,
 		PowerConsumption: dv.PowerConsumption,
+		Placement:        PlacementDto{}.DomainToDto(dv.Placement),
+		Status:           dv.Status,
+		LastSeen:         dv.LastSeenDate,
+		DeletedDate:      dv.DeletedDate,
 	}
 }
 
-func (d DevsDto) DomainToDto(devs []domain.Device) DevsDto {
-	var devices []DevDto
+func (d PlacementDto) DomainToDto(p *domain.Placement) *PlacementDto {
+	if p == nil {
+		return nil
+	}
+	return &PlacementDto{
+		Position: VectorDto{X: p.X, Y: p.Y, Z: p.Z},
+		Orientation: QuaternionDto{
+			X: p.Orientation.X,
+			Y: p.Orientation.Y,
+			Z: p.Orientation.Z,
+			W: p.Orientation.W,
+		},
+		AnchorId: p.AnchorId,
+	}
+}
+
+func (d RoomSceneDto) DomainToDto(m domain.Room, devs []domain.Device) RoomSceneDto {
+	devices := make([]DevDto, 0, len(devs))
 	for _, dv := range devs {
+		var dvDto DevDto
+		devices = append(devices, dvDto.DomainToDto(dv))
+	}
+	return RoomSceneDto{
+		Room:    RomDto{}.DomainToDto(m),
+		Devices: devices,
+	}
+}
+
+func (d DeviceLocationDto) DomainToDto(loc domain.DeviceLocation) DeviceLocationDto {
+	var room *RomDto
+	if loc.Room != nil {
+		rom := RomDto{}.DomainToDto(*loc.Room)
+		room = &rom
+	}
+	return DeviceLocationDto{
+		Device:       DevDto{}.DomainToDto(loc.Device),
+		Room:         room,
+		Organization: OrgDto{}.DomainToDto(loc.Organization),
+	}
+}
+
+func (d DevsDto) DomainToDto(devs domain.Page[domain.Device]) DevsDto {
+	devices := make([]DevDto, 0, len(devs.Items))
+	for _, dv := range devs.Items {
 		var dvDto DevDto
 		dev := dvDto.DomainToDto(dv)
 		devices = append(devices, dev)
 	}
 	response := DevsDto{
 		Devices: devices,
+		Total:   devs.Total,
+		Pages:   devs.Pages,
 	}
 	return response
 }
+
+type InventoryNumberDto struct {
+	InventoryNumber string `json:"inventoryNumber"`
+	Available       bool   `json:"available"`
+}
@@ -0,0 +1,43 @@
+package resources
+
+import (
+	"time"
+
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+	"github.com/google/uuid"
+)
+
+type EventDto struct {
+	Type           domain.EventType `json:"type"`
+	OrganizationId uint64           `json:"organizationId"`
+	RoomId         *uint64          `json:"roomId,omitempty"`
+	PrevRoomId     *uint64          `json:"prevRoomId,omitempty"`
+	DeviceGUID     *uuid.UUID       `json:"deviceGuid,omitempty"`
+	Date           time.Time        `json:"date"`
+	Data           interface{}      `json:"data"`
+}
+
+func (d EventDto) DomainToDto(e domain.Event) EventDto {
+	var data interface{}
+	switch v := e.Data.(type) {
+	case domain.Organization:
+		data = OrgDto{}.DomainToDto(v)
+	case domain.Room:
+		data = RomDto{}.DomainToDto(v)
+	case domain.Device:
+		data = DevDto{}.DomainToDto(v)
+	case domain.Command:
+		data = CommandDto{}.DomainToDto(v)
+	case domain.Alert:
+		data = AlertDto{}.DomainToDto(v)
+	}
+	return EventDto{
+		Type:           e.Type,
+		OrganizationId: e.OrganizationId,
+		RoomId:         e.RoomId,
+		PrevRoomId:     e.PrevRoomId,
+		DeviceGUID:     e.DeviceGUID,
+		Date:           e.Date,
+		Data:           data,
+	}
+}
@@ -0,0 +1,49 @@
+package resources
+
+import (
+	"time"
+
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+)
+
+type InvitationsDto struct {
+	Invitations []InvitationDto `json:"invitations"`
+	Total       uint64          `json:"total"`
+	Pages       uint            `json:"pages"`
+}
+
+// InvitationDto never carries the token, it is only sent by mail.
+type InvitationDto struct {
+	Id             uint64                  `json:"id"`
+	OrganizationId uint64                  `json:"organizationId"`
+	Email          string                  `json:"email"`
+	Role           domain.OrganizationRole `json:"role"`
+	InvitedBy      uint64                  `json:"invitedBy"`
+	ExpiresDate    time.Time               `json:"expiresDate"`
+	CreatedDate    time.Time               `json:"createdDate"`
+}
+
+func (d InvitationDto) DomainToDto(i domain.Invitation) InvitationDto {
+	return InvitationDto{
+		Id:             i.Id,
+		OrganizationId: i.OrganizationId,
+		Email:          i.Email,
+		Role:           i.Role,
+		InvitedBy:      i.InvitedBy,
+		ExpiresDate:    i.ExpiresDate,
+		CreatedDate:    i.CreatedDate,
+	}
+}
+
+func (d InvitationsDto) DomainToDto(invs domain.Page[domain.Invitation]) InvitationsDto {
+	result := make([]InvitationDto, 0, len(invs.Items))
+	for _, i := range invs.Items {
+		var iDto InvitationDto
+		result = append(result, iDto.DomainToDto(i))
+	}
+	return InvitationsDto{
+		Invitations: result,
+		Total:       invs.Total,
+		Pages:       invs.Pages,
+	}
+}
@@ -0,0 +1,59 @@
+package resources
+
+import (
+	"time"
+
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+	"github.com/google/uuid"
+)
+
+type MeasurementsDto struct {
+	DeviceGUID   uuid.UUID        `json:"deviceGuid"`
+	Units        *string          `json:"units"`
+	Measurements []MeasurementDto `json:"measurements"`
+}
+
+// MeasurementPageDto is one page of the measurement history.
+type MeasurementPageDto struct {
+	MeasurementsDto
+	Total uint64 `json:"total"`
+	Pages uint   `json:"pages"`
+}
+
+type MeasurementDto struct {
+	Id         uint64    `json:"id"`
+	DeviceId   uint64    `json:"deviceId"`
+	Value      float64   `json:"value"`
+	MeasuredAt time.Time `json:"measuredAt"`
+}
+
+func (d MeasurementDto) DomainToDto(m domain.Measurement) MeasurementDto {
+	return MeasurementDto{
+		Id:         m.Id,
+		DeviceId:   m.DeviceId,
+		Value:      m.Value,
+		MeasuredAt: m.MeasuredAt,
+	}
+}
+
+func (d MeasurementsDto) DomainToDto(dv domain.Device, ms []domain.Measurement) MeasurementsDto {
+	measurements := make([]MeasurementDto, 0, len(ms))
+	for _, m := range ms {
+		var mDto MeasurementDto
+		measurements = append(measurements, mDto.DomainToDto(m))
+	}
+	return MeasurementsDto{
+		DeviceGUID:   dv.GUID,
+		Units:        dv.Units,
+		Measurements: measurements,
+	}
+}
+
+func (d MeasurementPageDto) DomainToDto(dv domain.Device, ms domain.Page[domain.Measurement]) MeasurementPageDto {
+	var msDto MeasurementsDto
+	return MeasurementPageDto{
+		MeasurementsDto: msDto.DomainToDto(dv, ms.Items),
+		Total:           ms.Total,
+		Pages:           ms.Pages,
+	}
+}
@@ -0,0 +1,56 @@
+package resources
+
+import (
+	"time"
+
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+)
+
+type MembersDto struct {
+	Members []MemberDto `json:"members"`
+	Total   uint64      `json:"total"`
+	Pages   uint        `json:"pages"`
+}
+
+type MemberDto struct {
+	Id             uint64                  `json:"id"`
+	OrganizationId uint64                  `json:"organizationId"`
+	Role           domain.OrganizationRole `json:"role"`
+	User           *UserDto                `json:"user,omitempty"`
+	CreatedDate    time.Time               `json:"createdDate"`
+	UpdatedDate    time.Time               `json:"updatedDate"`
+}
+
+func (d MemberDto) DomainToDto(m domain.OrganizationMember) MemberDto {
+	var user *UserDto
+	if m.User != nil {
+		u := UserDto{
+			Id:         m.User.Id,
+			FirstName:  m.User.FirstName,
+			SecondName: m.User.SecondName,
+			Email:      m.User.Email,
+		}
+		user = &u
+	}
+	return MemberDto{
+		Id:             m.Id,
+		OrganizationId: m.OrganizationId,
+		Role:           m.Role,
+		User:           user,
+		CreatedDate:    m.CreatedDate,
+		UpdatedDate:    m.UpdatedDate,
+	}
+}
+
+func (d MembersDto) DomainToDto(mems domain.Page[domain.OrganizationMember]) MembersDto {
+	result := make([]MemberDto, 0, len(mems.Items))
+	for _, m := range mems.Items {
+		var mDto MemberDto
+		result = append(result, mDto.DomainToDto(m))
+	}
+	return MembersDto{
+		Members: result,
+		Total:   mems.Total,
+		Pages:   mems.Pages,
+	}
+}
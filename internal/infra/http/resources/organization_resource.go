@@ -9,6 +9,8 @@ import (
 type OrgsDto struct {
 	Organizations []OrgDto `json:"organizations"`
 	Rooms         []RomDto `json:"rooms"`
+	Total         uint64   `json:"total"`
+	Pages         uint     `json:"pages"`
 }
 
 type OrgDto struct {
@@ -46,15 +48,17 @@ func (d OrgDto) DomainToDto(o domain.Organization) OrgDto {
 	}
 }
 
-func (d OrgsDto) DomainToDto(orgs []domain.Organization) OrgsDto {
-	var organizations []OrgDto
-	for _, o := range orgs {
+func (d OrgsDto) DomainToDto(orgs domain.Page[domain.Organization]) OrgsDto {
+	organizations := make([]OrgDto, 0, len(orgs.Items))
+	for _, o := range orgs.Items {
 		var oDto OrgDto
 		org := oDto.DomainToDto(o)
 		organizations = append(organizations, org)
 	}
 	response := OrgsDto{
 		Organizations: organizations,
+		Total:         orgs.Total,
+		Pages:         orgs.Pages,
 	}
 	return response
 }
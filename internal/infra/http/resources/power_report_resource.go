@@ -0,0 +1,52 @@
+package resources
+
+import "github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+
+type RoomPowerReportDto struct {
+	RoomId           uint64             `json:"roomId"`
+	Name             string             `json:"name"`
+	PowerCapacity    *float64           `json:"powerCapacity"`
+	TotalConsumption float64            `json:"totalConsumption"`
+	ByCategory       map[string]float64 `json:"byCategory"`
+	DeviceCount      uint64             `json:"deviceCount"`
+	Overloaded       bool               `json:"overloaded"`
+}
+
+type OrgPowerReportDto struct {
+	OrganizationId   uint64               `json:"organizationId"`
+	Name             string               `json:"name"`
+	TotalConsumption float64              `json:"totalConsumption"`
+	ByCategory       map[string]float64   `json:"byCategory"`
+	Unassigned       float64              `json:"unassigned"`
+	Rooms            []RoomPowerReportDto `json:"rooms"`
+	OverloadedRooms  []uint64             `json:"overloadedRooms"`
+}
+
+func (d RoomPowerReportDto) DomainToDto(r domain.RoomPowerReport) RoomPowerReportDto {
+	return RoomPowerReportDto{
+		RoomId:           r.Room.Id,
+		Name:             r.Room.Name,
+		PowerCapacity:    r.Room.PowerCapacity,
+		TotalConsumption: r.TotalConsumption,
+		ByCategory:       r.ByCategory,
+		DeviceCount:      r.DeviceCount,
+		Overloaded:       r.Overloaded,
+	}
+}
+
+func (d OrgPowerReportDto) DomainToDto(r domain.OrganizationPowerReport) OrgPowerReportDto {
+	var rooms []RoomPowerReportDto
+	for _, rr := range r.Rooms {
+		var rDto RoomPowerReportDto
+		rooms = append(rooms, rDto.DomainToDto(rr))
+	}
+	return OrgPowerReportDto{
+		OrganizationId:   r.Organization.Id,
+		Name:             r.Organization.Name,
+		TotalConsumption: r.TotalConsumption,
+		ByCategory:       r.ByCategory,
+		Unassigned:       r.Unassigned,
+		Rooms:            rooms,
+		OverloadedRooms:  r.OverloadedRooms,
+	}
+}
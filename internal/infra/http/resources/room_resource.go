@@ -8,15 +8,21 @@ import (
 
 type RomsDto struct {
 	Rooms []RomDto `json:"rooms"`
+	Total uint64   `json:"total"`
+	Pages uint     `json:"pages"`
 }
 
 type RomDto struct {
-	Id             uint64    `json:"id"`
-	OrganizationId uint64    `json:"organizationId"`
-	Name           string    `json:"name"`
-	Description    string    `json:"description,somitempty"`
-	CreatedDate    time.Time `json:"createdDate"`
-	UpdatedDate    time.Time `json:"updatedDate"`
+	Id             uint64     `json:"id"`
+	OrganizationId uint64     `json:"organizationId"`
+	Name           string     `json:"name"`
+	Description    string     `json:"description,somitempty"`
+	PowerCapacity  *float64   `json:"powerCapacity"`
+	FloorPlanUrl   *string    `json:"floorPlanUrl"`
+	ModelUrl       *string    `json:"modelUrl"`
+	CreatedDate    time.Time  `json:"createdDate"`
+	UpdatedDate    time.Time  `json:"updatedDate"`
+	DeletedDate    *time.Time `json:"deletedDate,omitempty"`
 }
 
 func (d RomDto) DomainToDto(m domain.Room) RomDto {
@@ -25,20 +31,35 @@ func (d RomDto) DomainToDto(m domain.Room) RomDto {
 		OrganizationId: m.OrganizationId,
 		Name:           m.Name,
 		Description:    m.Description,
+		PowerCapacity:  m.PowerCapacity,
+		FloorPlanUrl:   staticUrl(m.FloorPlan),
+		ModelUrl:       staticUrl(m.Model),
 		CreatedDate:    m.CreatedDate,
 		UpdatedDate:    m.UpdatedDate,
+		DeletedDate:    m.DeletedDate,
 	}
 }
 
-func (d RomsDto) DomainToDto(roms []domain.Room) RomsDto {
-	var rooms []RomDto
-	for _, m := range roms {
+func (d RomsDto) DomainToDto(roms domain.Page[domain.Room]) RomsDto {
+	rooms := make([]RomDto, 0, len(roms.Items))
+	for _, m := range roms.Items {
 		var mDto RomDto
 		rom := mDto.DomainToDto(m)
 		rooms = append(rooms, rom)
 	}
 	response := RomsDto{
 		Rooms: rooms,
+		Total: roms.Total,
+		Pages: roms.Pages,
 	}
 	return response
 }
+
+// staticUrl turns a path inside the file storage into the URL it is served at.
+func staticUrl(filename *string) *string {
+	if filename == nil {
+		return nil
+	}
+	url := "/static/" + *filename
+	return &url
+}
@@ -0,0 +1,47 @@
+package resources
+
+import (
+	"time"
+
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+	"github.com/google/uuid"
+)
+
+type SessionsDto struct {
+	Sessions []SessionDto `json:"sessions"`
+	Total    uint64       `json:"total"`
+	Pages    uint         `json:"pages"`
+}
+
+type SessionDto struct {
+	Id           uuid.UUID `json:"id"`
+	UserAgent    string    `json:"userAgent"`
+	Ip           string    `json:"ip"`
+	Current      bool      `json:"current"`
+	CreatedDate  time.Time `json:"createdDate"`
+	LastUsedDate time.Time `json:"lastUsedDate"`
+}
+
+func (d SessionDto) DomainToDto(s domain.Session, current domain.Session) SessionDto {
+	return SessionDto{
+		Id:           s.UUID,
+		UserAgent:    s.UserAgent,
+		Ip:           s.Ip,
+		Current:      s.UUID == current.UUID,
+		CreatedDate:  s.CreatedDate,
+		LastUsedDate: s.LastUsedDate,
+	}
+}
+
+func (d SessionsDto) DomainToDto(ss domain.Page[domain.Session], current domain.Session) SessionsDto {
+	result := make([]SessionDto, 0, len(ss.Items))
+	for _, s := range ss.Items {
+		var sDto SessionDto
+		result = append(result, sDto.DomainToDto(s, current))
+	}
+	return SessionsDto{
+		Sessions: result,
+		Total:    ss.Total,
+		Pages:    ss.Pages,
+	}
+}
@@ -1,6 +1,10 @@
 package resources
 
-import "github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+import (
+	"time"
+
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+)
 
 type UserDto struct {
 	Id         uint64      `json:"id"`
@@ -8,11 +12,18 @@ type UserDto struct {
 	SecondName string      `json:"secondName"`
 	Email      string      `json:"email"`
 	Role       domain.Role `json:"role,omitempty"`
+	// VerifiedDate stays null until the email address is confirmed
+	VerifiedDate *time.Time `json:"verifiedDate"`
+	BlockedDate  *time.Time `json:"blockedDate,omitempty"`
+	DeletedDate  *time.Time `json:"deletedDate,omitempty"`
 }
 
 type AuthDto struct {
-	Token string  `json:"token"`
-	User  UserDto `json:"user"`
+	Token              string    `json:"token"`
+	ExpiresDate        time.Time `json:"expiresDate"`
+	RefreshToken       string    `json:"refreshToken"`
+	RefreshExpiresDate time.Time `json:"refreshExpiresDate"`
+	User               UserDto   `json:"user"`
 }
 
 type UsersDto struct {
@@ -23,11 +34,23 @@ type UsersDto struct {
 
 func (d UserDto) DomainToDto(user domain.User) UserDto {
 	return UserDto{
-		Id:         user.Id,
-		FirstName:  user.FirstName,
-		SecondName: user.SecondName,
-		Email:      user.Email,
-		Role:       user.Role,
+		Id:           user.Id,
+		FirstName:    user.FirstName,
+		SecondName:   user.SecondName,
+		Email:        user.Email,
+		Role:         user.Role,
+		VerifiedDate: user.VerifiedDate,
+		BlockedDate:  user.BlockedDate,
+		DeletedDate:  user.DeletedDate,
+	}
+}
+
+func (d UsersDto) DomainToDto(users domain.Page[domain.User]) UsersDto {
+	var uDto UserDto
+	return UsersDto{
+		Items: uDto.DomainToDtoCollection(users.Items),
+		Total: users.Total,
+		Pages: users.Pages,
 	}
 }
 
@@ -39,10 +62,13 @@ func (d UserDto) DomainToDtoCollection(users []domain.User) []UserDto {
 	return result
 }
 
-func (d AuthDto) DomainToDto(token string, user domain.User) AuthDto {
+func (d AuthDto) DomainToDto(tokens domain.AuthTokens, user domain.User) AuthDto {
 	var userDto UserDto
 	return AuthDto{
-		Token: token,
-		User:  userDto.DomainToDto(user),
+		Token:              tokens.AccessToken,
+		ExpiresDate:        tokens.AccessExpiresDate,
+		RefreshToken:       tokens.RefreshToken,
+		RefreshExpiresDate: tokens.RefreshExpiresDate,
+		User:               userDto.DomainToDto(user),
 	}
 }
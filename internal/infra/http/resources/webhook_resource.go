@@ -0,0 +1,98 @@
+package resources
+
+import (
+	"time"
+
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+)
+
+type WebhooksDto struct {
+	Webhooks []WebhookDto `json:"webhooks"`
+	Total    uint64       `json:"total"`
+	Pages    uint         `json:"pages"`
+}
+
+// WebhookDto never carries the secret back.
+type WebhookDto struct {
+	Id             uint64             `json:"id"`
+	OrganizationId uint64             `json:"organizationId"`
+	Url            string             `json:"url"`
+	Events         []domain.EventType `json:"events"`
+	Enabled        bool               `json:"enabled"`
+	CreatedDate    time.Time          `json:"createdDate"`
+	UpdatedDate    time.Time          `json:"updatedDate"`
+}
+
+type WebhookDeliveriesDto struct {
+	Deliveries []WebhookDeliveryDto `json:"deliveries"`
+	Total      uint64               `json:"total"`
+	Pages      uint                 `json:"pages"`
+}
+
+type WebhookDeliveryDto struct {
+	Id              uint64                `json:"id"`
+	EventType       domain.EventType      `json:"eventType"`
+	Status          domain.DeliveryStatus `json:"status"`
+	Attempts        int                   `json:"attempts"`
+	ResponseCode    *int                  `json:"responseCode,omitempty"`
+	Error           *string               `json:"error,omitempty"`
+	NextAttemptDate *time.Time            `json:"nextAttemptDate,omitempty"`
+	DeliveredDate   *time.Time            `json:"deliveredDate,omitempty"`
+	CreatedDate     time.Time             `json:"createdDate"`
+}
+
+func (d WebhookDto) DomainToDto(w domain.Webhook) WebhookDto {
+	events := w.Events
+	if events == nil {
+		events = []domain.EventType{}
+	}
+	return WebhookDto{
+		Id:             w.Id,
+		OrganizationId: w.OrganizationId,
+		Url:            w.Url,
+		Events:         events,
+		Enabled:        w.Enabled,
+		CreatedDate:    w.CreatedDate,
+		UpdatedDate:    w.UpdatedDate,
+	}
+}
+
+func (d WebhooksDto) DomainToDto(hooks domain.Page[domain.Webhook]) WebhooksDto {
+	result := make([]WebhookDto, 0, len(hooks.Items))
+	for _, w := range hooks.Items {
+		var wDto WebhookDto
+		result = append(result, wDto.DomainToDto(w))
+	}
+	return WebhooksDto{
+		Webhooks: result,
+		Total:    hooks.Total,
+		Pages:    hooks.Pages,
+	}
+}
+
+func (d WebhookDeliveryDto) DomainToDto(dl domain.WebhookDelivery) WebhookDeliveryDto {
+	return WebhookDeliveryDto{
+		Id:              dl.Id,
+		EventType:       dl.EventType,
+		Status:          dl.Status,
+		Attempts:        dl.Attempts,
+		ResponseCode:    dl.ResponseCode,
+		Error:           dl.Error,
+		NextAttemptDate: dl.NextAttemptDate,
+		DeliveredDate:   dl.DeliveredDate,
+		CreatedDate:     dl.CreatedDate,
+	}
+}
+
+func (d WebhookDeliveriesDto) DomainToDto(dlvs domain.Page[domain.WebhookDelivery]) WebhookDeliveriesDto {
+	result := make([]WebhookDeliveryDto, 0, len(dlvs.Items))
+	for _, dl := range dlvs.Items {
+		var dDto WebhookDeliveryDto
+		result = append(result, dDto.DomainToDto(dl))
+	}
+	return WebhookDeliveriesDto{
+		Deliveries: result,
+		Total:      dlvs.Total,
+		Pages:      dlvs.Pages,
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/BohdanBoriak/boilerplate-go-back/config"
 	"github.com/BohdanBoriak/boilerplate-go-back/config/container"
 	"github.com/BohdanBoriak/boilerplate-go-back/internal/app"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
 	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/http/controllers"
 	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/http/middlewares"
 	"github.com/go-chi/chi/v5"
@@ -23,7 +24,7 @@ func Router(cont container.Container) http.Handler {
 
 	router := chi.NewRouter()
 
-	router.Use(middleware.RedirectSlashes, middleware.Logger, cors.Handler(cors.Options{
+	router.Use(middleware.RedirectSlashes, cont.RealIPMw, middleware.Logger, cors.Handler(cors.Options{
 		AllowedOrigins:   []string{"https://*", "http://*", "capacitor://localhost"},
 		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
 		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
@@ -47,13 +48,32 @@ func Router(cont container.Container) http.Handler {
 				})
 			})
 
+			// Device routes
+			apiRouter.Group(func(apiRouter chi.Router) {
+				apiRouter.Use(cont.DeviceAuthMw)
+
+				DeviceApiRouter(apiRouter, cont.DeviceController, cont.MeasurementController, cont.CommandController, cont.CommandService)
+			})
+
 			// Protected routes
 			apiRouter.Group(func(apiRouter chi.Router) {
 				apiRouter.Use(cont.AuthMw)
 
 				UserRouter(apiRouter, cont.UserController)
-				OrganizationRouter(apiRouter, cont.OrganizationController, cont.OrganizationService)
-				RoomRouter(apiRouter, cont.RoomController, cont.RoomService, cont.OrganizationService)
+				SessionRouter(apiRouter, cont.SessionController, cont.SessionService)
+				OrganizationRouter(apiRouter, cont.OrganizationController, cont.OrganizationMemberController, cont.InvitationController, cont.TrashController, cont.OrganizationService, cont.OrganizationMemberService, cont.InvitationService, cont.TrashService)
+				InvitationRouter(apiRouter, cont.InvitationController)
+				RoomRouter(apiRouter, cont.RoomController, cont.DeviceController, cont.TrashController, cont.RoomService, cont.TrashService)
+				DeviceRouter(apiRouter, cont.DeviceController, cont.TrashController, cont.DeviceService, cont.TrashService)
+				MeasurementRouter(apiRouter, cont.MeasurementController, cont.DeviceService)
+				CommandRouter(apiRouter, cont.CommandController, cont.DeviceService)
+				PowerReportRouter(apiRouter, cont.PowerReportController, cont.RoomService, cont.OrganizationService)
+				AlertRuleRouter(apiRouter, cont.AlertRuleController, cont.AlertRuleService)
+				AlertRouter(apiRouter, cont.AlertController, cont.AlertService)
+				WebhookRouter(apiRouter, cont.WebhookController, cont.WebhookService)
+				LabelRouter(apiRouter, cont.LabelController, cont.DeviceService, cont.RoomService)
+				StreamRouter(apiRouter, cont.StreamController, cont.OrganizationService, cont.RoomService)
+				AdminRouter(apiRouter, cont.AdminController, cont.AdminService, cont.UserService, cont.OrganizationService)
 				apiRouter.Handle("/*", NotFoundJSON())
 			})
 		})
@@ -81,10 +101,30 @@ func AuthRouter(r chi.Router, ac controllers.AuthController, amw func(http.Handl
 			"/login",
 			ac.Login(),
 		)
+		apiRouter.Post(
+			"/refresh",
+			ac.Refresh(),
+		)
 		apiRouter.With(amw).Post(
 			"/logout",
 			ac.Logout(),
 		)
+		apiRouter.Post(
+			"/password/forgot",
+			ac.ForgotPassword(),
+		)
+		apiRouter.Post(
+			"/password/reset",
+			ac.ResetPassword(),
+		)
+		apiRouter.Post(
+			"/email/verify",
+			ac.VerifyEmail(),
+		)
+		apiRouter.With(amw).Post(
+			"/email/resend",
+			ac.ResendVerification(),
+		)
 	})
 }
 
@@ -98,6 +138,10 @@ func UserRouter(r chi.Router, uc controllers.UserController) {
 			"/",
 			uc.Update(),
 		)
+		apiRouter.Put(
+			"/password",
+			uc.ChangePassword(),
+		)
 		apiRouter.Delete(
 			"/",
 			uc.Delete(),
@@ -105,8 +149,20 @@ func UserRouter(r chi.Router, uc controllers.UserController) {
 	})
 }
 
-func OrganizationRouter(r chi.Router, oc controllers.OrganizationController, os app.OrganizationService) {
+func OrganizationRouter(
+	r chi.Router,
+	oc controllers.OrganizationController,
+	mc controllers.OrganizationMemberController,
+	ic controllers.InvitationController,
+	tc controllers.TrashController,
+	os app.OrganizationService,
+	ms app.OrganizationMemberService,
+	is app.InvitationService,
+	ts app.TrashService) {
 	opom := middlewares.PathObject("orgId", controllers.OrgKey, os)
+	dopom := middlewares.PathObject("orgId", controllers.OrgKey, middlewares.FindFunc(ts.FindDeletedOrganization))
+	mpom := middlewares.PathObject("memberId", controllers.MemberKey, ms)
+	ipom := middlewares.PathObject("invitationId", controllers.InvitationKey, is)
 	r.Route("/organizations", func(apiRouter chi.Router) {
 		apiRouter.Post(
 			"/",
@@ -128,19 +184,143 @@ func OrganizationRouter(r chi.Router, oc controllers.OrganizationController, os
 			"/{orgId}",
 			oc.Delete(),
 		)
+		apiRouter.With(dopom).Post(
+			"/{orgId}/restore",
+			tc.RestoreOrganization(),
+		)
+		apiRouter.With(opom).Get(
+			"/{orgId}/trash/rooms",
+			tc.FindRooms(),
+		)
+		apiRouter.With(opom).Get(
+			"/{orgId}/trash/devices",
+			tc.FindDevices(),
+		)
+		apiRouter.With(opom).Get(
+			"/{orgId}/members",
+			mc.FindForOrganization(),
+		)
+		apiRouter.With(opom).Post(
+			"/{orgId}/members",
+			mc.Save(),
+		)
+		apiRouter.With(opom, mpom).Put(
+			"/{orgId}/members/{memberId}",
+			mc.ChangeRole(),
+		)
+		apiRouter.With(opom, mpom).Delete(
+			"/{orgId}/members/{memberId}",
+			mc.Delete(),
+		)
+		apiRouter.With(opom).Get(
+			"/{orgId}/invitations",
+			ic.FindForOrganization(),
+		)
+		apiRouter.With(opom).Post(
+			"/{orgId}/invitations",
+			ic.Save(),
+		)
+		apiRouter.With(opom, ipom).Delete(
+			"/{orgId}/invitations/{invitationId}",
+			ic.Revoke(),
+		)
 	})
 }
 
-func RoomRouter(r chi.Router, oc controllers.RoomController, rs app.RoomService, os app.OrganizationService) {
-	ropom := middlewares.PathObject("romId", controllers.RoomKey, rs)
+func AdminRouter(r chi.Router, ac controllers.AdminController, as app.AdminService, us app.UserService, os app.OrganizationService) {
+	upom := middlewares.PathObject("userId", controllers.PathUserKey, us)
 	opom := middlewares.PathObject("orgId", controllers.OrgKey, os)
+	// restore works on records the regular services no longer find
+	dupom := middlewares.PathObject("userId", controllers.PathUserKey, middlewares.FindFunc(as.FindDeletedUser))
+	dopom := middlewares.PathObject("orgId", controllers.OrgKey, middlewares.FindFunc(as.FindDeletedOrganization))
+	drpom := middlewares.PathObject("romId", controllers.RoomKey, middlewares.FindFunc(as.FindDeletedRoom))
+	ddpom := middlewares.PathObject("deviceId", controllers.DeviceKey, middlewares.FindFunc(as.FindDeletedDevice))
+	r.Route("/admin", func(apiRouter chi.Router) {
+		apiRouter.Use(middlewares.RoleMiddleware(domain.AdminRole))
+		apiRouter.Get(
+			"/users",
+			ac.FindUsers(),
+		)
+		apiRouter.With(upom).Get(
+			"/users/{userId}",
+			ac.FindUser(),
+		)
+		apiRouter.With(upom).Put(
+			"/users/{userId}/block",
+			ac.BlockUser(),
+		)
+		apiRouter.With(upom).Delete(
+			"/users/{userId}/block",
+			ac.UnblockUser(),
+		)
+		apiRouter.With(upom).Put(
+			"/users/{userId}/role",
+			ac.ChangeRole(),
+		)
+		apiRouter.With(dupom).Post(
+			"/users/{userId}/restore",
+			ac.RestoreUser(),
+		)
+		apiRouter.With(opom).Get(
+			"/organizations/{orgId}",
+			ac.FindOrganization(),
+		)
+		apiRouter.With(opom).Get(
+			"/organizations/{orgId}/members",
+			ac.FindMembers(),
+		)
+		apiRouter.With(dopom).Post(
+			"/organizations/{orgId}/restore",
+			ac.RestoreOrganization(),
+		)
+		apiRouter.With(drpom).Post(
+			"/rooms/{romId}/restore",
+			ac.RestoreRoom(),
+		)
+		apiRouter.With(ddpom).Post(
+			"/devices/{deviceId}/restore",
+			ac.RestoreDevice(),
+		)
+	})
+}
+
+func SessionRouter(r chi.Router, sc controllers.SessionController, ss app.SessionService) {
+	spom := middlewares.PathGUIDObject("sessionId", controllers.PathSessKey, ss)
+	r.Route("/sessions", func(apiRouter chi.Router) {
+		apiRouter.Get(
+			"/",
+			sc.FindForUser(),
+		)
+		apiRouter.Delete(
+			"/",
+			sc.DeleteOthers(),
+		)
+		apiRouter.With(spom).Delete(
+			"/{sessionId}",
+			sc.Delete(),
+		)
+	})
+}
+
+func InvitationRouter(r chi.Router, ic controllers.InvitationController) {
+	r.Route("/invitations", func(apiRouter chi.Router) {
+		apiRouter.Post(
+			"/accept",
+			ic.Accept(),
+		)
+	})
+}
+
+func RoomRouter(r chi.Router, oc controllers.RoomController, dc controllers.DeviceController, tc controllers.TrashController, rs app.RoomService, ts app.TrashService) {
+	ropom := middlewares.PathObject("romId", controllers.RoomKey, rs)
+	dropom := middlewares.PathObject("romId", controllers.RoomKey, middlewares.FindFunc(ts.FindDeletedRoom))
 	r.Route("/rooms", func(apiRouter chi.Router) {
 		apiRouter.Post(
 			"/",
 			oc.Save(),
 		)
-		apiRouter.With(opom).Get(
-			"/{orgId}",
+		apiRouter.Get(
+			"/",
 			oc.FindForOrganization(),
 		)
 		apiRouter.With(ropom).Get(
@@ -155,11 +335,36 @@ func RoomRouter(r chi.Router, oc controllers.RoomController, rs app.RoomService,
 			"/{romId}",
 			oc.Delete(),
 		)
+		apiRouter.With(dropom).Post(
+			"/{romId}/restore",
+			tc.RestoreRoom(),
+		)
+		apiRouter.With(ropom).Get(
+			"/{romId}/scene",
+			dc.Scene(),
+		)
+		apiRouter.With(ropom).Put(
+			"/{romId}/floor-plan",
+			oc.SaveAsset(domain.RoomFloorPlan),
+		)
+		apiRouter.With(ropom).Delete(
+			"/{romId}/floor-plan",
+			oc.RemoveAsset(domain.RoomFloorPlan),
+		)
+		apiRouter.With(ropom).Put(
+			"/{romId}/model",
+			oc.SaveAsset(domain.RoomModel),
+		)
+		apiRouter.With(ropom).Delete(
+			"/{romId}/model",
+			oc.RemoveAsset(domain.RoomModel),
+		)
 	})
 }
 
-func DeviceRouter(r chi.Router, oc controllers.DeviceController, os app.DeviceService) {
+func DeviceRouter(r chi.Router, oc controllers.DeviceController, tc controllers.TrashController, os app.DeviceService, ts app.TrashService) {
 	dopom := middlewares.PathObject("devId", controllers.DeviceKey, os)
+	ddopom := middlewares.PathObject("devId", controllers.DeviceKey, middlewares.FindFunc(ts.FindDeletedDevice))
 	r.Route("/devices", func(apiRouter chi.Router) {
 		apiRouter.Post(
 			"/",
@@ -167,7 +372,11 @@ func DeviceRouter(r chi.Router, oc controllers.DeviceController, os app.DeviceSe
 		)
 		apiRouter.Get(
 			"/",
-			oc.FindForRoom(),
+			oc.FindAll(),
+		)
+		apiRouter.Get(
+			"/inventory-number",
+			oc.InventoryNumber(),
 		)
 		apiRouter.With(dopom).Get(
 			"/{devId}",
@@ -178,21 +387,213 @@ func DeviceRouter(r chi.Router, oc controllers.DeviceController, os app.DeviceSe
 			oc.Update(),
 		)
 		apiRouter.With(dopom).Put(
-			"/{devId}",
+			"/{devId}/room",
 			oc.SetDeviceToRoom(),
 		)
-		apiRouter.With(dopom).Patch(
-			"/{devId}",
+		apiRouter.With(dopom).Delete(
+			"/{devId}/room",
 			oc.RemoveDeviceFromRoom(),
 		)
 		apiRouter.With(dopom).Put(
-			"/{devId}",
-			oc.Update(),
+			"/{devId}/placement",
+			oc.SetPlacement(),
+		)
+		apiRouter.With(dopom).Delete(
+			"/{devId}/placement",
+			oc.RemovePlacement(),
 		)
 		apiRouter.With(dopom).Delete(
 			"/{devId}",
 			oc.Delete(),
 		)
+		apiRouter.With(ddopom).Post(
+			"/{devId}/restore",
+			tc.RestoreDevice(),
+		)
+		apiRouter.With(dopom).Post(
+			"/{devId}/token",
+			oc.IssueToken(),
+		)
+		apiRouter.With(dopom).Delete(
+			"/{devId}/token",
+			oc.RevokeToken(),
+		)
+	})
+}
+
+// DeviceApiRouter serves requests made by the hardware itself,
+// the device is taken from the request context.
+func DeviceApiRouter(r chi.Router, dc controllers.DeviceController, mc controllers.MeasurementController, cc controllers.CommandController, cs app.CommandService) {
+	cpom := middlewares.PathObject("cmdId", controllers.CommandKey, cs)
+	r.Route("/device", func(apiRouter chi.Router) {
+		apiRouter.Post(
+			"/heartbeat",
+			dc.Heartbeat(),
+		)
+		apiRouter.Post(
+			"/measurements",
+			mc.Record(),
+		)
+		apiRouter.Post(
+			"/commands",
+			cc.Poll(),
+		)
+		apiRouter.With(cpom).Post(
+			"/commands/{cmdId}/ack",
+			cc.Ack(),
+		)
+	})
+}
+
+func MeasurementRouter(r chi.Router, mc controllers.MeasurementController, ds app.DeviceService) {
+	dgpom := middlewares.PathGUIDObject("guid", controllers.DeviceKey, ds)
+	r.Route("/measurements", func(apiRouter chi.Router) {
+		apiRouter.With(dgpom).Post(
+			"/{guid}",
+			mc.Save(),
+		)
+		apiRouter.With(dgpom).Get(
+			"/{guid}",
+			mc.FindForDevice(),
+		)
+	})
+}
+
+func CommandRouter(r chi.Router, cc controllers.CommandController, ds app.DeviceService) {
+	dgpom := middlewares.PathGUIDObject("guid", controllers.DeviceKey, ds)
+	r.Route("/commands", func(apiRouter chi.Router) {
+		apiRouter.With(dgpom).Post(
+			"/{guid}",
+			cc.Save(),
+		)
+		apiRouter.With(dgpom).Get(
+			"/{guid}",
+			cc.FindForDevice(),
+		)
+	})
+}
+
+func PowerReportRouter(r chi.Router, pc controllers.PowerReportController, rs app.RoomService, os app.OrganizationService) {
+	ropom := middlewares.PathObject("romId", controllers.RoomKey, rs)
+	opom := middlewares.PathObject("orgId", controllers.OrgKey, os)
+	r.Route("/reports/power", func(apiRouter chi.Router) {
+		apiRouter.With(opom).Get(
+			"/organizations/{orgId}",
+			pc.OrganizationReport(),
+		)
+		apiRouter.With(ropom).Get(
+			"/rooms/{romId}",
+			pc.RoomReport(),
+		)
+	})
+}
+
+func AlertRuleRouter(r chi.Router, arc controllers.AlertRuleController, ars app.AlertRuleService) {
+	arpom := middlewares.PathObject("ruleId", controllers.RuleKey, ars)
+	r.Route("/alert-rules", func(apiRouter chi.Router) {
+		apiRouter.Post(
+			"/",
+			arc.Save(),
+		)
+		apiRouter.Get(
+			"/",
+			arc.FindForOrganization(),
+		)
+		apiRouter.With(arpom).Get(
+			"/{ruleId}",
+			arc.Find(),
+		)
+		apiRouter.With(arpom).Put(
+			"/{ruleId}",
+			arc.Update(),
+		)
+		apiRouter.With(arpom).Delete(
+			"/{ruleId}",
+			arc.Delete(),
+		)
+	})
+}
+
+func AlertRouter(r chi.Router, ac controllers.AlertController, as app.AlertService) {
+	apom := middlewares.PathObject("alertId", controllers.AlertKey, as)
+	r.Route("/alerts", func(apiRouter chi.Router) {
+		apiRouter.Get(
+			"/",
+			ac.FindForOrganization(),
+		)
+		apiRouter.With(apom).Get(
+			"/{alertId}",
+			ac.Find(),
+		)
+		apiRouter.With(apom).Post(
+			"/{alertId}/ack",
+			ac.Acknowledge(),
+		)
+	})
+}
+
+func WebhookRouter(r chi.Router, wc controllers.WebhookController, ws app.WebhookService) {
+	wpom := middlewares.PathObject("hookId", controllers.WebhookKey, ws)
+	r.Route("/webhooks", func(apiRouter chi.Router) {
+		apiRouter.Post(
+			"/",
+			wc.Save(),
+		)
+		apiRouter.Get(
+			"/",
+			wc.FindForOrganization(),
+		)
+		apiRouter.With(wpom).Get(
+			"/{hookId}",
+			wc.Find(),
+		)
+		apiRouter.With(wpom).Put(
+			"/{hookId}",
+			wc.Update(),
+		)
+		apiRouter.With(wpom).Delete(
+			"/{hookId}",
+			wc.Delete(),
+		)
+		apiRouter.With(wpom).Get(
+			"/{hookId}/deliveries",
+			wc.FindDeliveries(),
+		)
+	})
+}
+
+func LabelRouter(r chi.Router, lc controllers.LabelController, ds app.DeviceService, rs app.RoomService) {
+	dopom := middlewares.PathObject("devId", controllers.DeviceKey, ds)
+	ropom := middlewares.PathObject("romId", controllers.RoomKey, rs)
+	dgpom := middlewares.PathGUIDObject("guid", controllers.DeviceKey, ds)
+	r.Route("/labels", func(apiRouter chi.Router) {
+		apiRouter.With(dopom).Get(
+			"/devices/{devId}",
+			lc.Device(),
+		)
+		apiRouter.With(ropom).Get(
+			"/rooms/{romId}",
+			lc.Room(),
+		)
+	})
+	r.With(dgpom).Get(
+		"/lookup/{guid}",
+		lc.Lookup(),
+	)
+}
+
+func StreamRouter(r chi.Router, sc controllers.StreamController, os app.OrganizationService, rs app.RoomService) {
+	opom := middlewares.PathObject("orgId", controllers.OrgKey, os)
+	ropom := middlewares.PathObject("romId", controllers.RoomKey, rs)
+	r.Route("/stream", func(apiRouter chi.Router) {
+		apiRouter.With(opom).Get(
+			"/organizations/{orgId}",
+			sc.Organization(),
+		)
+		apiRouter.With(ropom).Get(
+			"/rooms/{romId}",
+			sc.Room(),
+		)
 	})
 }
 
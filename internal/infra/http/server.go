@@ -3,6 +3,7 @@ package http
 import (
 	"context"
 	"fmt"
+	"net"
 	"net/http"
 	"time"
 )
@@ -11,6 +12,9 @@ func Server(ctx context.Context, router http.Handler) error {
 	srv := &http.Server{
 		Addr:    fmt.Sprintf(":%d", 8080),
 		Handler: router,
+		// long-lived requests such as event streams end together with the app,
+		// otherwise Shutdown would wait for them until its timeout
+		BaseContext: func(net.Listener) context.Context { return ctx },
 	}
 
 	errServeCh := make(chan error)
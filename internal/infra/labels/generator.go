@@ -0,0 +1,125 @@
+package labels
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+	"github.com/go-pdf/fpdf"
+	"github.com/skip2/go-qrcode"
+)
+
+const (
+	DefaultSize = 256
+	MinSize     = 64
+	MaxSize     = 2048
+)
+
+// Generator renders QR labels for devices. The code holds a deep link
+// when a base is configured and the bare device GUID otherwise.
+type Generator struct {
+	deepLinkBase string
+}
+
+func NewGenerator(deepLinkBase string) Generator {
+	return Generator{
+		deepLinkBase: deepLinkBase,
+	}
+}
+
+func (g Generator) Content(dv domain.Device) string {
+	if g.deepLinkBase == "" {
+		return dv.GUID.String()
+	}
+	return g.deepLinkBase + dv.GUID.String()
+}
+
+func (g Generator) PNG(dv domain.Device, size int) ([]byte, error) {
+	return qrcode.Encode(g.Content(dv), qrcode.Medium, size)
+}
+
+func (g Generator) SVG(dv domain.Device, size int) ([]byte, error) {
+	q, err := qrcode.New(g.Content(dv), qrcode.Medium)
+	if err != nil {
+		return nil, err
+	}
+
+	bitmap := q.Bitmap()
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" shape-rendering="crispEdges">`,
+		size, size, len(bitmap), len(bitmap))
+	fmt.Fprintf(&buf, `<rect width="%d" height="%d" fill="#fff"/><path fill="#000" d="`, len(bitmap), len(bitmap))
+	for y, row := range bitmap {
+		for x, dark := range row {
+			if dark {
+				fmt.Fprintf(&buf, "M%d %dh1v1h-1z", x, y)
+			}
+		}
+	}
+	buf.WriteString(`"/></svg>`)
+	return buf.Bytes(), nil
+}
+
+// Label sheet layout, A4 in millimeters.
+const (
+	sheetMargin  = 10.0
+	sheetHeader  = 12.0
+	labelColumns = 3
+	labelRows    = 4
+	labelWidth   = 63.0
+	labelHeight  = 65.0
+	labelQR      = 42.0
+)
+
+// RoomSheet lays out printable labels for every device of the room.
+// Core PDF fonts cover Latin-1 only, other characters are replaced.
+func (g Generator) RoomSheet(m domain.Room, devs []domain.Device) ([]byte, error) {
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(sheetMargin, sheetMargin, sheetMargin)
+	pdf.SetAutoPageBreak(false, 0)
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+
+	perPage := labelColumns * labelRows
+	for i, dv := range devs {
+		if i%perPage == 0 {
+			pdf.AddPage()
+			pdf.SetFont("Helvetica", "B", 14)
+			pdf.CellFormat(0, sheetHeader-4, tr(m.Name), "", 1, "L", false, 0, "")
+		}
+
+		png, err := g.PNG(dv, 512)
+		if err != nil {
+			return nil, err
+		}
+		name := fmt.Sprintf("qr-%d", dv.Id)
+		pdf.RegisterImageOptionsReader(name, fpdf.ImageOptions{ImageType: "PNG"}, bytes.NewReader(png))
+
+		pos := i % perPage
+		x := sheetMargin + float64(pos%labelColumns)*labelWidth
+		y := sheetMargin + sheetHeader + float64(pos/labelColumns)*labelHeight
+
+		pdf.SetDrawColor(200, 200, 200)
+		pdf.Rect(x, y, labelWidth, labelHeight, "D")
+		pdf.ImageOptions(name, x+(labelWidth-labelQR)/2, y+2, labelQR, labelQR, false, fpdf.ImageOptions{ImageType: "PNG"}, 0, "")
+
+		pdf.SetXY(x, y+labelQR+3)
+		pdf.SetFont("Helvetica", "B", 10)
+		pdf.CellFormat(labelWidth, 5, tr(dv.InventoryNumber), "", 2, "C", false, 0, "")
+		pdf.SetFont("Helvetica", "", 8)
+		pdf.CellFormat(labelWidth, 4, tr(dv.SerialNumber), "", 2, "C", false, 0, "")
+		pdf.SetFont("Helvetica", "", 6)
+		pdf.CellFormat(labelWidth, 4, dv.GUID.String(), "", 2, "C", false, 0, "")
+	}
+	if len(devs) == 0 {
+		pdf.AddPage()
+		pdf.SetFont("Helvetica", "B", 14)
+		pdf.CellFormat(0, sheetHeader-4, tr(m.Name), "", 1, "L", false, 0, "")
+	}
+
+	var buf bytes.Buffer
+	err := pdf.Output(&buf)
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
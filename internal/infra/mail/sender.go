@@ -0,0 +1,95 @@
+package mail
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"mime"
+	"net"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Sender delivers plain text messages, NewFileSender is meant for local
+// use and NewSMTPSender for everything else.
+type Sender interface {
+	Send(m Message) error
+}
+
+type fileSender struct {
+	dir  string
+	from string
+}
+
+// NewFileSender writes every message as an .eml file into dir
+// instead of sending it anywhere.
+func NewFileSender(dir, from string) Sender {
+	return fileSender{
+		dir:  dir,
+		from: from,
+	}
+}
+
+func (s fileSender) Send(m Message) error {
+	err := os.MkdirAll(s.dir, os.ModePerm)
+	if err != nil {
+		return err
+	}
+
+	filename := filepath.Join(s.dir, fmt.Sprintf("%s-%s.eml", time.Now().Format("20060102150405"), uuid.New()))
+	err = os.WriteFile(filename, compose(s.from, m), 0644)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Mail: %q to %s saved to %s", m.Subject, m.To, filename)
+	return nil
+}
+
+type smtpSender struct {
+	addr string
+	from string
+	auth smtp.Auth
+}
+
+// NewSMTPSender sends through the server at addr (host:port),
+// it authenticates only when a username is given.
+func NewSMTPSender(addr, username, password, from string) Sender {
+	var auth smtp.Auth
+	if username != "" {
+		host, _, _ := net.SplitHostPort(addr)
+		auth = smtp.PlainAuth("", username, password, host)
+	}
+	return smtpSender{
+		addr: addr,
+		from: from,
+		auth: auth,
+	}
+}
+
+func (s smtpSender) Send(m Message) error {
+	return smtp.SendMail(s.addr, s.auth, s.from, []string{m.To}, compose(s.from, m))
+}
+
+func compose(from string, m Message) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", from)
+	fmt.Fprintf(&buf, "To: %s\r\n", m.To)
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", m.Subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	buf.WriteString("\r\n")
+	buf.WriteString(m.Body)
+	return buf.Bytes()
+}
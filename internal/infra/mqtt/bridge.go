@@ -0,0 +1,281 @@
+package mqtt
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/app"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/events"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/http/resources"
+	paho "github.com/eclipse/paho.mqtt.golang"
+	"github.com/google/uuid"
+)
+
+// Topics are laid out per device: org/{orgId}/device/{guid}/{kind}.
+const (
+	telemetryTopic = "org/+/device/+/telemetry"
+	heartbeatTopic = "org/+/device/+/heartbeat"
+	ackTopic       = "org/+/device/+/commands/ack"
+
+	qos            = 1
+	connectTimeout = 10 * time.Second
+	publishTimeout = 5 * time.Second
+)
+
+type reading struct {
+	Value      *float64   `json:"value"`
+	MeasuredAt *time.Time `json:"measuredAt,omitempty"`
+}
+
+type telemetryMessage struct {
+	reading
+	Readings []reading `json:"readings,omitempty"`
+}
+
+type ackMessage struct {
+	Id      uint64  `json:"id"`
+	Success bool    `json:"success"`
+	Message *string `json:"message,omitempty"`
+}
+
+type eventMessage struct {
+	Type domain.EventType `json:"type"`
+	Date time.Time        `json:"date"`
+	Data interface{}      `json:"data"`
+}
+
+// Bridge takes device messages from the broker to the services and
+// forwards device events back. It does not authenticate devices itself:
+// the organization and GUID of a topic are taken as they are, so the
+// broker has to keep every device to its own topics. The embedded one
+// does, an external broker (MQTT_BROKER) must be set up with per-device
+// credentials and ACLs limiting each to reading org/{orgId}/device/{guid}/
+// and writing only its telemetry, heartbeat and commands/ack topics.
+type Bridge struct {
+	client             paho.Client
+	deviceService      app.DeviceService
+	measurementService app.MeasurementService
+	commandService     app.CommandService
+	eventBus           events.Bus
+}
+
+func NewBridge(
+	opts *paho.ClientOptions,
+	ds app.DeviceService,
+	ms app.MeasurementService,
+	cs app.CommandService,
+	eb events.Bus) *Bridge {
+	b := &Bridge{
+		deviceService:      ds,
+		measurementService: ms,
+		commandService:     cs,
+		eventBus:           eb,
+	}
+
+	// subscriptions are not kept by the broker between clean sessions,
+	// so they are made again on every (re)connect
+	opts.SetAutoReconnect(true)
+	opts.SetConnectRetry(true)
+	opts.SetOnConnectHandler(b.subscribe)
+	b.client = paho.NewClient(opts)
+	return b
+}
+
+// Start connects in the background, the client keeps retrying until the
+// broker is reachable, and forwards bus events until ctx is done.
+func (b *Bridge) Start(ctx context.Context) {
+	token := b.client.Connect()
+	go func() {
+		if token.Wait() && token.Error() != nil {
+			log.Printf("MqttBridge: %s", token.Error())
+		}
+	}()
+
+	evts, unsubscribe := b.eventBus.Subscribe()
+	go func() {
+		defer unsubscribe()
+		for {
+			select {
+			case <-ctx.Done():
+				b.client.Disconnect(250)
+				return
+			case e := <-evts:
+				b.forward(e)
+			}
+		}
+	}()
+}
+
+func (b *Bridge) subscribe(c paho.Client) {
+	handlers := map[string]paho.MessageHandler{
+		telemetryTopic: b.handleTelemetry,
+		heartbeatTopic: b.handleHeartbeat,
+		ackTopic:       b.handleAck,
+	}
+	for topic, handler := range handlers {
+		token := c.Subscribe(topic, qos, handler)
+		if token.WaitTimeout(connectTimeout) && token.Error() != nil {
+			log.Printf("MqttBridge: subscribe to %s: %s", topic, token.Error())
+		}
+	}
+}
+
+func (b *Bridge) handleTelemetry(_ paho.Client, msg paho.Message) {
+	dev, err := b.deviceFromTopic(msg.Topic())
+	if err != nil {
+		log.Printf("MqttBridge: %s", err)
+		return
+	}
+
+	var tm telemetryMessage
+	err = json.Unmarshal(msg.Payload(), &tm)
+	if err != nil {
+		log.Printf("MqttBridge: %s", err)
+		return
+	}
+
+	rds := tm.Readings
+	if tm.Value != nil {
+		rds = append(rds, tm.reading)
+	}
+	var ms []domain.Measurement
+	for _, rd := range rds {
+		if rd.Value == nil {
+			continue
+		}
+		m := domain.Measurement{Value: *rd.Value}
+		if rd.MeasuredAt != nil {
+			m.MeasuredAt = *rd.MeasuredAt
+		}
+		ms = append(ms, m)
+	}
+	if len(ms) == 0 {
+		log.Printf("MqttBridge: no readings in message on %s", msg.Topic())
+		return
+	}
+
+	_, err = b.measurementService.Record(dev, ms)
+	if err != nil {
+		log.Printf("MqttBridge: %s", err)
+		return
+	}
+
+	_ = b.deviceService.Touch(dev)
+}
+
+func (b *Bridge) handleHeartbeat(_ paho.Client, msg paho.Message) {
+	dev, err := b.deviceFromTopic(msg.Topic())
+	if err != nil {
+		log.Printf("MqttBridge: %s", err)
+		return
+	}
+
+	_ = b.deviceService.Touch(dev)
+}
+
+func (b *Bridge) handleAck(_ paho.Client, msg paho.Message) {
+	dev, err := b.deviceFromTopic(msg.Topic())
+	if err != nil {
+		log.Printf("MqttBridge: %s", err)
+		return
+	}
+
+	var am ackMessage
+	err = json.Unmarshal(msg.Payload(), &am)
+	if err != nil {
+		log.Printf("MqttBridge: %s", err)
+		return
+	}
+
+	c, err := b.commandService.Find(am.Id)
+	if err != nil {
+		log.Printf("MqttBridge: %s", err)
+		return
+	}
+
+	status := domain.CommandAcked
+	if !am.Success {
+		status = domain.CommandFailed
+	}
+	_, err = b.commandService.Ack(dev, c.(domain.Command), domain.Command{Status: status, Result: am.Message})
+	if err != nil {
+		log.Printf("MqttBridge: %s", err)
+		return
+	}
+
+	_ = b.deviceService.Touch(dev)
+}
+
+func (b *Bridge) forward(e domain.Event) {
+	if e.DeviceGUID == nil {
+		return
+	}
+
+	var (
+		kind = "events"
+		data interface{}
+	)
+	switch d := e.Data.(type) {
+	case domain.Device:
+		data = resources.DevDto{}.DomainToDto(d)
+	case domain.Command:
+		kind = "commands"
+		data = resources.CommandDto{}.DomainToDto(d)
+	default:
+		return
+	}
+
+	payload, err := json.Marshal(eventMessage{Type: e.Type, Date: e.Date, Data: data})
+	if err != nil {
+		log.Printf("MqttBridge: %s", err)
+		return
+	}
+
+	topic := fmt.Sprintf("org/%d/device/%s/%s", e.OrganizationId, e.DeviceGUID, kind)
+	token := b.client.Publish(topic, qos, false, payload)
+	if !token.WaitTimeout(publishTimeout) {
+		log.Printf("MqttBridge: publish to %s timed out", topic)
+		return
+	}
+	if token.Error() != nil {
+		log.Printf("MqttBridge: %s", token.Error())
+	}
+}
+
+// deviceFromTopic resolves the device addressed by a topic and makes sure
+// it really belongs to the organization named in the same topic. Whether
+// the sender is that device is up to the broker ACLs, see Bridge.
+func (b *Bridge) deviceFromTopic(topic string) (domain.Device, error) {
+	parts := strings.Split(topic, "/")
+	if len(parts) < 5 || parts[0] != "org" || parts[2] != "device" {
+		return domain.Device{}, fmt.Errorf("unexpected topic %s", topic)
+	}
+
+	orgId, err := strconv.ParseUint(parts[1], 10, 64)
+	if err != nil {
+		return domain.Device{}, fmt.Errorf("invalid organization id in topic %s", topic)
+	}
+
+	guid, err := uuid.Parse(parts[3])
+	if err != nil {
+		return domain.Device{}, fmt.Errorf("invalid device guid in topic %s", topic)
+	}
+
+	dev, err := b.deviceService.FindByGUID(guid)
+	if err != nil {
+		return domain.Device{}, err
+	}
+
+	dv := dev.(domain.Device)
+	if dv.OrganizationId != orgId {
+		return domain.Device{}, fmt.Errorf("device %s does not belong to organization %d", guid, orgId)
+	}
+
+	return dv, nil
+}
@@ -0,0 +1,276 @@
+package mqtt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/app"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/events"
+	paho "github.com/eclipse/paho.mqtt.golang"
+	"github.com/eclipse/paho.mqtt.golang/packets"
+	"github.com/google/uuid"
+)
+
+const deviceToken = "device-token"
+
+type fakeDeviceAuth struct {
+	app.DeviceAuthService
+	dev domain.Device
+}
+
+func (f fakeDeviceAuth) Check(token string) (domain.Device, error) {
+	if token != deviceToken {
+		return domain.Device{}, domain.ErrNotFound
+	}
+	return f.dev, nil
+}
+
+type fakeDevices struct {
+	app.DeviceService
+	dev domain.Device
+}
+
+func (f fakeDevices) FindByGUID(guid uuid.UUID) (interface{}, error) {
+	if guid != f.dev.GUID {
+		return nil, domain.ErrNotFound
+	}
+	return f.dev, nil
+}
+
+func (f fakeDevices) Touch(dv domain.Device) error {
+	return nil
+}
+
+type fakeMeasurements struct {
+	app.MeasurementService
+	mu     sync.Mutex
+	stored []domain.Measurement
+}
+
+func (f *fakeMeasurements) Record(dv domain.Device, ms []domain.Measurement) ([]domain.Measurement, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, m := range ms {
+		m.DeviceId = dv.Id
+		f.stored = append(f.stored, m)
+	}
+	return ms, nil
+}
+
+func (f *fakeMeasurements) Stored() []domain.Measurement {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]domain.Measurement(nil), f.stored...)
+}
+
+// TestTelemetryThroughEmbeddedBroker runs the embedded broker and the
+// bridge on a free port and sends telemetry the way a device does.
+func TestTelemetryThroughEmbeddedBroker(t *testing.T) {
+	dev := domain.Device{Id: 7, OrganizationId: 3, GUID: uuid.New()}
+	measurements := &fakeMeasurements{}
+	addr := freeAddr(t)
+
+	broker, err := NewBroker(addr, "bridge", "bridge-secret", fakeDeviceAuth{dev: dev}, events.NewBus())
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() { _ = broker.Start() }()
+	defer broker.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	opts := paho.NewClientOptions().
+		AddBroker("tcp://" + addr).
+		SetClientID("bridge").
+		SetUsername("bridge").
+		SetPassword("bridge-secret")
+	NewBridge(opts, fakeDevices{dev: dev}, measurements, nil, events.NewBus()).Start(ctx)
+
+	device := connect(t, addr, dev.GUID.String(), deviceToken)
+	defer device.Disconnect(250)
+
+	// the bridge subscribes once connected, so publish until it listens
+	topic := fmt.Sprintf("org/%d/device/%s/telemetry", dev.OrganizationId, dev.GUID)
+	deadline := time.Now().Add(10 * time.Second)
+	for len(measurements.Stored()) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("no measurement was stored")
+		}
+		token := device.Publish(topic, qos, false, `{"value": 21.5}`)
+		if !token.WaitTimeout(publishTimeout) || token.Error() != nil {
+			t.Fatalf("publish: %v", token.Error())
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	m := measurements.Stored()[0]
+	if m.DeviceId != dev.Id || m.Value != 21.5 {
+		t.Errorf("stored %+v, want value 21.5 for device %d", m, dev.Id)
+	}
+}
+
+func TestEmbeddedBrokerRefusesUnknownToken(t *testing.T) {
+	dev := domain.Device{Id: 7, OrganizationId: 3, GUID: uuid.New()}
+	addr := freeAddr(t)
+
+	broker, err := NewBroker(addr, "bridge", "bridge-secret", fakeDeviceAuth{dev: dev}, events.NewBus())
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() { _ = broker.Start() }()
+	defer broker.Close()
+
+	tests := []struct {
+		name     string
+		username string
+		password string
+	}{
+		{"wrong token", dev.GUID.String(), "other-token"},
+		{"other device", uuid.New().String(), deviceToken},
+		{"bridge username with device token", "bridge", deviceToken},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := paho.NewClient(paho.NewClientOptions().
+				AddBroker("tcp://" + addr).
+				SetUsername(tt.username).
+				SetPassword(tt.password))
+			token := c.Connect()
+			if !token.WaitTimeout(connectTimeout) {
+				t.Fatal("connect timed out")
+			}
+			if !errors.Is(token.Error(), packets.ErrorRefusedNotAuthorised) {
+				c.Disconnect(250)
+				t.Fatalf("got %v, want %v", token.Error(), packets.ErrorRefusedNotAuthorised)
+			}
+		})
+	}
+}
+
+func TestEmbeddedBrokerLimitsDeviceWrites(t *testing.T) {
+	dev := domain.Device{Id: 7, OrganizationId: 3, GUID: uuid.New()}
+	addr := freeAddr(t)
+
+	broker, err := NewBroker(addr, "bridge", "bridge-secret", fakeDeviceAuth{dev: dev}, events.NewBus())
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() { _ = broker.Start() }()
+	defer broker.Close()
+
+	observer := connect(t, addr, "bridge", "bridge-secret")
+	defer observer.Disconnect(250)
+	received := make(chan string, 16)
+	token := observer.Subscribe("org/#", qos, func(_ paho.Client, msg paho.Message) {
+		received <- msg.Topic()
+	})
+	if !token.WaitTimeout(connectTimeout) || token.Error() != nil {
+		t.Fatalf("subscribe: %v", token.Error())
+	}
+
+	own := fmt.Sprintf("org/%d/device/%s/", dev.OrganizationId, dev.GUID)
+	tests := []struct {
+		name  string
+		topic string
+	}{
+		{"own commands", own + "commands"},
+		{"own events", own + "events"},
+		{"other device", fmt.Sprintf("org/%d/device/%s/telemetry", dev.OrganizationId, uuid.New())},
+		{"other organization", fmt.Sprintf("org/%d/device/%s/telemetry", dev.OrganizationId+1, dev.GUID)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			device := connect(t, addr, dev.GUID.String(), deviceToken)
+			defer device.Disconnect(250)
+
+			// QoS 0 is dropped quietly, so the allowed message sent after it
+			// shows that the refused one is not coming
+			device.Publish(tt.topic, 0, false, `{"value": 1}`).WaitTimeout(publishTimeout)
+			device.Publish(own+"heartbeat", 0, false, `{}`).WaitTimeout(publishTimeout)
+			for {
+				select {
+				case topic := <-received:
+					if topic == tt.topic {
+						t.Fatalf("device wrote to %s", topic)
+					}
+					if topic == own+"heartbeat" {
+						return
+					}
+				case <-time.After(connectTimeout):
+					t.Fatal("allowed message was not delivered")
+				}
+			}
+		})
+	}
+}
+
+func TestEmbeddedBrokerDisconnectsRevokedDevice(t *testing.T) {
+	dev := domain.Device{Id: 7, OrganizationId: 3, GUID: uuid.New()}
+	addr := freeAddr(t)
+	bus := events.NewBus()
+
+	broker, err := NewBroker(addr, "bridge", "bridge-secret", fakeDeviceAuth{dev: dev}, bus)
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() { _ = broker.Start() }()
+	defer broker.Close()
+
+	lost := make(chan struct{})
+	device := paho.NewClient(paho.NewClientOptions().
+		AddBroker("tcp://" + addr).
+		SetUsername(dev.GUID.String()).
+		SetPassword(deviceToken).
+		SetAutoReconnect(false).
+		SetConnectRetry(true).
+		SetConnectRetryInterval(100 * time.Millisecond).
+		SetConnectionLostHandler(func(paho.Client, error) { close(lost) }))
+	token := device.Connect()
+	if !token.WaitTimeout(connectTimeout) || token.Error() != nil {
+		t.Fatalf("connect: %v", token.Error())
+	}
+	defer device.Disconnect(250)
+
+	bus.Publish(domain.Event{Type: domain.DeviceTokenRevoked, OrganizationId: dev.OrganizationId, DeviceGUID: &dev.GUID})
+	select {
+	case <-lost:
+	case <-time.After(connectTimeout):
+		t.Fatal("device stayed connected after its token was revoked")
+	}
+}
+
+func connect(t *testing.T, addr, username, password string) paho.Client {
+	t.Helper()
+	c := paho.NewClient(paho.NewClientOptions().
+		AddBroker("tcp://" + addr).
+		SetClientID(username).
+		SetUsername(username).
+		SetPassword(password).
+		SetConnectRetry(true).
+		SetConnectRetryInterval(100 * time.Millisecond))
+	token := c.Connect()
+	if !token.WaitTimeout(connectTimeout) {
+		t.Fatal("connect timed out")
+	}
+	if token.Error() != nil {
+		t.Fatal(token.Error())
+	}
+	return c
+}
+
+// freeAddr picks a port nothing listens on, the broker binds it right after.
+func freeAddr(t *testing.T) string {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	return l.Addr().String()
+}
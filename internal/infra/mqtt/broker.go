@@ -0,0 +1,164 @@
+package mqtt
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/app"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/events"
+	"github.com/google/uuid"
+	mochi "github.com/mochi-mqtt/server/v2"
+	"github.com/mochi-mqtt/server/v2/listeners"
+	"github.com/mochi-mqtt/server/v2/packets"
+)
+
+// deviceWritable are the topics under its own prefix a device may publish
+// to, the rest of them are written by the bridge only.
+var deviceWritable = []string{"telemetry", "heartbeat", "commands/ack"}
+
+// Broker is an in-process MQTT broker, so the bridge and the devices
+// can talk to each other without any outside services. The connections
+// of a device are dropped as soon as its token is revoked or the device
+// is deleted.
+type Broker struct {
+	server      *mochi.Server
+	auth        *authHook
+	unsubscribe func()
+}
+
+func NewBroker(addr, bridgeUsername, bridgePassword string, das app.DeviceAuthService, eb events.Bus) (*Broker, error) {
+	server := mochi.New(&mochi.Options{})
+
+	auth := &authHook{
+		deviceAuthService: das,
+		bridgeUsername:    []byte(bridgeUsername),
+		bridgePassword:    []byte(bridgePassword),
+	}
+	err := server.AddHook(auth, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	err = server.AddListener(listeners.NewTCP(listeners.Config{ID: "tcp", Address: addr}))
+	if err != nil {
+		return nil, err
+	}
+
+	evts, unsubscribe := eb.Subscribe()
+	b := &Broker{server: server, auth: auth, unsubscribe: unsubscribe}
+	go func() {
+		for e := range evts {
+			if e.DeviceGUID == nil || (e.Type != domain.DeviceTokenRevoked && e.Type != domain.DeviceDeleted) {
+				continue
+			}
+			b.disconnect(*e.DeviceGUID)
+		}
+	}()
+
+	return b, nil
+}
+
+func (b *Broker) Start() error {
+	return b.server.Serve()
+}
+
+func (b *Broker) Close() error {
+	b.unsubscribe()
+	return b.server.Close()
+}
+
+func (b *Broker) disconnect(guid uuid.UUID) {
+	b.auth.clients.Range(func(k, v interface{}) bool {
+		if v.(clientAccess).guid == guid {
+			_ = b.server.DisconnectClient(k.(*mochi.Client), packets.ErrNotAuthorized)
+		}
+		return true
+	})
+}
+
+// clientAccess is what a connected client may use: the bridge anything,
+// a device only its own topics.
+type clientAccess struct {
+	bridge bool
+	guid   uuid.UUID
+	prefix string
+}
+
+// authHook lets the bridge in with its own credentials and devices in
+// with their GUID as username and their device token as password.
+// A device may read topics under its own org/{orgId}/device/{guid}/ and
+// write only the deviceWritable ones among them.
+type authHook struct {
+	mochi.HookBase
+	deviceAuthService app.DeviceAuthService
+	bridgeUsername    []byte
+	bridgePassword    []byte
+	clients           sync.Map
+}
+
+func (h *authHook) ID() string {
+	return "device-auth"
+}
+
+func (h *authHook) Provides(b byte) bool {
+	return bytes.Contains([]byte{
+		mochi.OnConnectAuthenticate,
+		mochi.OnACLCheck,
+		mochi.OnDisconnect,
+	}, []byte{b})
+}
+
+func (h *authHook) OnConnectAuthenticate(cl *mochi.Client, pk packets.Packet) bool {
+	username, password := pk.Connect.Username, pk.Connect.Password
+
+	if len(h.bridgePassword) > 0 &&
+		subtle.ConstantTimeCompare(username, h.bridgeUsername) == 1 &&
+		subtle.ConstantTimeCompare(password, h.bridgePassword) == 1 {
+		h.clients.Store(cl, clientAccess{bridge: true})
+		return true
+	}
+
+	dev, err := h.deviceAuthService.Check(string(password))
+	if err != nil || dev.GUID.String() != string(username) {
+		return false
+	}
+
+	h.clients.Store(cl, clientAccess{
+		guid:   dev.GUID,
+		prefix: fmt.Sprintf("org/%d/device/%s/", dev.OrganizationId, dev.GUID),
+	})
+	return true
+}
+
+func (h *authHook) OnACLCheck(cl *mochi.Client, topic string, write bool) bool {
+	v, ok := h.clients.Load(cl)
+	if !ok {
+		return false
+	}
+	access := v.(clientAccess)
+	if access.bridge {
+		return true
+	}
+
+	kind, ok := strings.CutPrefix(topic, access.prefix)
+	if !ok {
+		return false
+	}
+	if !write {
+		return true
+	}
+	for _, w := range deviceWritable {
+		if kind == w {
+			return true
+		}
+	}
+	return false
+}
+
+func (h *authHook) OnDisconnect(cl *mochi.Client, err error, expire bool) {
+	h.clients.Delete(cl)
+}
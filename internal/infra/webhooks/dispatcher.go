@@ -0,0 +1,38 @@
+package webhooks
+
+import (
+	"context"
+	"time"
+
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/app"
+)
+
+const deliveryInterval = 5 * time.Second
+
+// Dispatcher periodically sends the pending deliveries that are due,
+// the services queue them through the Outbox along with their changes.
+type Dispatcher struct {
+	webhookService app.WebhookService
+}
+
+func NewDispatcher(ws app.WebhookService) Dispatcher {
+	return Dispatcher{
+		webhookService: ws,
+	}
+}
+
+func (d Dispatcher) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(deliveryInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				// errors are already logged by the service, the next tick retries
+				_ = d.webhookService.DeliverDue()
+			}
+		}
+	}()
+}
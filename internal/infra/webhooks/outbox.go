@@ -0,0 +1,69 @@
+package webhooks
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/database"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/http/resources"
+)
+
+// Outbox stores the deliveries as pending rows, the Dispatcher
+// picks them up from there, so none is lost on a restart.
+type Outbox struct {
+	webhookRepo  database.WebhookRepository
+	deliveryRepo database.WebhookDeliveryRepository
+}
+
+func NewOutbox(wr database.WebhookRepository, dr database.WebhookDeliveryRepository) Outbox {
+	return Outbox{
+		webhookRepo:  wr,
+		deliveryRepo: dr,
+	}
+}
+
+func (o Outbox) Add(e domain.Event) error {
+	return add(o.webhookRepo, o.deliveryRepo, e)
+}
+
+func (o Outbox) AddWithin(tx database.Tx, e domain.Event) error {
+	return add(tx.Webhooks(), tx.WebhookDeliveries(), e)
+}
+
+func add(wr database.WebhookRepository, dr database.WebhookDeliveryRepository, e domain.Event) error {
+	hooks, err := wr.FindEnabledForOrganization(e.OrganizationId)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	if e.Date.IsZero() {
+		e.Date = now
+	}
+	var payload []byte
+	for _, w := range hooks {
+		if !w.Accepts(e.Type) {
+			continue
+		}
+		if payload == nil {
+			payload, err = json.Marshal(resources.EventDto{}.DomainToDto(e))
+			if err != nil {
+				return err
+			}
+		}
+
+		_, err = dr.Save(domain.WebhookDelivery{
+			WebhookId:       w.Id,
+			EventType:       e.Type,
+			Payload:         string(payload),
+			Status:          domain.DeliveryPending,
+			NextAttemptDate: &now,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
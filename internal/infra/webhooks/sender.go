@@ -0,0 +1,77 @@
+package webhooks
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+)
+
+const (
+	SignatureHeader = "X-Webhook-Signature"
+	TimestampHeader = "X-Webhook-Timestamp"
+	EventHeader     = "X-Webhook-Event"
+	DeliveryHeader  = "X-Webhook-Delivery"
+
+	sendTimeout = 10 * time.Second
+)
+
+// Sender signs every payload with HMAC-SHA256 over "{timestamp}.{body}"
+// using the webhook secret, so receivers can check both origin and freshness.
+// It connects to public addresses only and never goes through a proxy.
+type Sender struct {
+	client *http.Client
+}
+
+func NewSender() Sender {
+	dialer := &net.Dialer{Timeout: sendTimeout, Control: dialPublic}
+	return Sender{
+		client: &http.Client{
+			Timeout:   sendTimeout,
+			Transport: &http.Transport{DialContext: dialer.DialContext},
+		},
+	}
+}
+
+func (s Sender) Send(w domain.Webhook, d domain.WebhookDelivery) (int, error) {
+	body := []byte(d.Payload)
+	ts := strconv.FormatInt(time.Now().Unix(), 10)
+
+	req, err := http.NewRequest(http.MethodPost, w.Url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, string(d.EventType))
+	req.Header.Set(DeliveryHeader, strconv.FormatUint(d.Id, 10))
+	req.Header.Set(TimestampHeader, ts)
+	req.Header.Set(SignatureHeader, "sha256="+Sign(w.Secret, ts, body))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("unexpected response status %s", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
+
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
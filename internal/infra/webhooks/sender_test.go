@@ -0,0 +1,29 @@
+package webhooks
+
+import "testing"
+
+func TestSign(t *testing.T) {
+	body := []byte(`{"type":"device.created"}`)
+	tests := []struct {
+		name      string
+		secret    string
+		timestamp string
+		body      []byte
+		want      string
+	}{
+		{"payload", "whsec-0123456789abcdef", "1700000000", body, "0f5ae6734c8937f538bd10b2b118728730f15e53db009dc902ea066991ecd2f6"},
+		{"other secret", "other-secret-0123", "1700000000", body, "1ff576a80e8238afbe77c7b1558cb843f345b6ed87add64b6544a60569a2e135"},
+		{"empty body", "whsec-0123456789abcdef", "1700000000", nil, "b225047a70491323cb35ad04c6121e8c0ca18b344d6d975fc714cd1da1aa3b34"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Sign(tt.secret, tt.timestamp, tt.body); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	if Sign("whsec-0123456789abcdef", "1700000001", body) == tests[0].want {
+		t.Error("signature does not depend on the timestamp")
+	}
+}
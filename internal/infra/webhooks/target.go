@@ -0,0 +1,58 @@
+package webhooks
+
+import (
+	"errors"
+	"net"
+	"net/url"
+	"syscall"
+)
+
+var (
+	ErrInvalidTarget   = errors.New("webhook url must be an http(s) address")
+	ErrForbiddenTarget = errors.New("webhook url must point to a public address")
+)
+
+// CheckTarget refuses urls whose host resolves to a loopback, link-local,
+// private or otherwise internal address, so a webhook cannot be used to
+// reach the server itself or the network behind it.
+func CheckTarget(rawUrl string) error {
+	u, err := url.Parse(rawUrl)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return ErrInvalidTarget
+	}
+
+	ips, err := net.LookupIP(u.Hostname())
+	if err != nil {
+		return ErrInvalidTarget
+	}
+	for _, ip := range ips {
+		if !public(ip) {
+			return ErrForbiddenTarget
+		}
+	}
+	return nil
+}
+
+// dialPublic repeats the check for the address actually dialed, the name
+// may resolve differently by the time a delivery is sent.
+func dialPublic(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || !public(ip) {
+		return ErrForbiddenTarget
+	}
+	return nil
+}
+
+func public(ip net.IP) bool {
+	return !(ip.IsLoopback() ||
+		ip.IsPrivate() ||
+		ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() ||
+		ip.IsMulticast())
+}
@@ -0,0 +1,36 @@
+package webhooks
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCheckTarget(t *testing.T) {
+	tests := []struct {
+		url  string
+		want error
+	}{
+		{"https://93.184.216.34/hook", nil},
+		{"http://[2606:4700:4700::1111]/hook", nil},
+		{"ftp://93.184.216.34/hook", ErrInvalidTarget},
+		{"https:///hook", ErrInvalidTarget},
+		{"http://127.0.0.1:8080/hook", ErrForbiddenTarget},
+		{"http://localhost/hook", ErrForbiddenTarget},
+		{"http://[::1]/hook", ErrForbiddenTarget},
+		{"http://10.1.2.3/hook", ErrForbiddenTarget},
+		{"http://172.16.0.10/hook", ErrForbiddenTarget},
+		{"http://192.168.1.1/hook", ErrForbiddenTarget},
+		{"http://169.254.169.254/latest/meta-data", ErrForbiddenTarget},
+		{"http://[fe80::1]/hook", ErrForbiddenTarget},
+		{"http://[fd00::1]/hook", ErrForbiddenTarget},
+		{"http://0.0.0.0/hook", ErrForbiddenTarget},
+		{"http://[::ffff:127.0.0.1]/hook", ErrForbiddenTarget},
+	}
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			if err := CheckTarget(tt.url); !errors.Is(err, tt.want) {
+				t.Errorf("got %v, want %v", err, tt.want)
+			}
+		})
+	}
+}